@@ -11,6 +11,7 @@ import (
 type Config struct {
 	Logger     LoggerCfg
 	HTTPServer HTTPServerCfg
+	Analyzer   AnalyzerCfg
 }
 
 // LoggerCfg struct defines the logger configuration.
@@ -24,6 +25,15 @@ type HTTPServerCfg struct {
 	Host string
 }
 
+// AnalyzerCfg struct defines the page analyzer configuration.
+type AnalyzerCfg struct {
+	MaxCrawlPages         int
+	DefaultScopeMode      string
+	CertExpiryWarningDays int
+	VendorSignaturesPath  string
+	TechRulesPath         string
+}
+
 func loadConfig() (*Config, error) {
 	// Set the name of the configurations file
 	viper.SetConfigName("config")
@@ -42,6 +52,9 @@ func loadConfig() (*Config, error) {
 	viper.SetDefault("Logger.Level", "info")
 	viper.SetDefault("HTTPServer.Port", 8080)
 	viper.SetDefault("HTTPServer.Host", "0.0.0.0")
+	viper.SetDefault("Analyzer.MaxCrawlPages", 20)
+	viper.SetDefault("Analyzer.DefaultScopeMode", "host-www")
+	viper.SetDefault("Analyzer.CertExpiryWarningDays", 30)
 
 	var config Config
 
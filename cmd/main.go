@@ -39,8 +39,37 @@ func run() error {
 		return fmt.Errorf("can not configure logger: %v", err)
 	}
 
+	// An empty path loads the bundled vendor signatures
+	vendorSignatures, err := pageanalyzer.LoadVendorSignatures(cfg.Analyzer.VendorSignaturesPath)
+	if err != nil {
+		return fmt.Errorf("load vendor signatures failed: %v", err)
+	}
+
+	// An empty path loads the bundled technology rules
+	techRules, err := pageanalyzer.LoadTechRules(cfg.Analyzer.TechRulesPath)
+	if err != nil {
+		return fmt.Errorf("load technology rules failed: %v", err)
+	}
+
+	// The default scope applies to every request, so a wrong mode fails at startup. A list scope can't be the
+	// default, its domains are entered per request.
+	if cfg.Analyzer.DefaultScopeMode != "" {
+		if err := (pageanalyzer.Scope{Mode: cfg.Analyzer.DefaultScopeMode}).Validate(); err != nil {
+			return fmt.Errorf("invalid default link scope: %v", err)
+		}
+	}
+
 	pageDownloader := pagedownloader.New(http.DefaultClient)
-	pageAnalyzer := pageanalyzer.New(http.DefaultClient)
+	pageAnalyzer := pageanalyzer.New(
+		&pageanalyzer.Config{
+			MaxCrawlPages:         cfg.Analyzer.MaxCrawlPages,
+			DefaultScopeMode:      cfg.Analyzer.DefaultScopeMode,
+			CertExpiryWarningDays: cfg.Analyzer.CertExpiryWarningDays,
+			VendorSignatures:      vendorSignatures,
+			TechRules:             techRules,
+		},
+		http.DefaultClient,
+	)
 
 	httpServer := server.New(
 		&server.Config{
@@ -0,0 +1,47 @@
+package pageanalyzer
+
+import (
+	"sort"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+// severityRank orders accessibility severities from the most to the least impactful.
+var severityRank = map[string]int{
+	htmlextract.SeverityCritical: 0,
+	htmlextract.SeveritySerious:  1,
+	htmlextract.SeverityModerate: 2,
+	htmlextract.SeverityMinor:    3,
+}
+
+// AccessibilityReport holds the accessibility findings of the page, the most severe first.
+type AccessibilityReport struct {
+	Findings       []htmlextract.A11yFinding
+	SeverityCounts []SeverityCount
+}
+
+// SeverityCount is the number of findings of a severity.
+type SeverityCount struct {
+	Severity string
+	Count    int
+}
+
+func accessibilityReport(findings []htmlextract.A11yFinding) *AccessibilityReport {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank[findings[i].Severity] < severityRank[findings[j].Severity]
+	})
+
+	// Findings are sorted, so the counts follow the severity order too
+	var severityCounts []SeverityCount
+	for _, finding := range findings {
+		if len(severityCounts) == 0 || severityCounts[len(severityCounts)-1].Severity != finding.Severity {
+			severityCounts = append(severityCounts, SeverityCount{Severity: finding.Severity})
+		}
+		severityCounts[len(severityCounts)-1].Count++
+	}
+
+	return &AccessibilityReport{
+		Findings:       findings,
+		SeverityCounts: severityCounts,
+	}
+}
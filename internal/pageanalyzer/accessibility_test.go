@@ -0,0 +1,32 @@
+package pageanalyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestAccessibilityReport(t *testing.T) {
+	findings := []htmlextract.A11yFinding{
+		{Rule: "landmark-navigation", Severity: htmlextract.SeverityMinor},
+		{Rule: "label", Severity: htmlextract.SeveritySerious},
+		{Rule: "image-alt", Severity: htmlextract.SeverityCritical},
+		{Rule: "html-has-lang", Severity: htmlextract.SeveritySerious},
+	}
+
+	report := accessibilityReport(findings)
+
+	assert.Equal(t, []htmlextract.A11yFinding{
+		{Rule: "image-alt", Severity: htmlextract.SeverityCritical},
+		{Rule: "label", Severity: htmlextract.SeveritySerious},
+		{Rule: "html-has-lang", Severity: htmlextract.SeveritySerious},
+		{Rule: "landmark-navigation", Severity: htmlextract.SeverityMinor},
+	}, report.Findings)
+	assert.Equal(t, []SeverityCount{
+		{Severity: htmlextract.SeverityCritical, Count: 1},
+		{Severity: htmlextract.SeveritySerious, Count: 2},
+		{Severity: htmlextract.SeverityMinor, Count: 1},
+	}, report.SeverityCounts)
+}
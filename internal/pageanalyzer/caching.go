@@ -0,0 +1,220 @@
+package pageanalyzer
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// shortCacheTTL is the cache lifetime below which static assets are downloaded again too often.
+const shortCacheTTL = 7 * 24 * time.Hour
+
+// staticWeightTypes are the resource types that rarely change and should be cached for long.
+var staticWeightTypes = []string{WeightTypeJS, WeightTypeCSS, WeightTypeImage, WeightTypeFont}
+
+// CachingCheck is the cacheability of a resource, based on its Cache-Control, Expires, Vary and validator headers.
+type CachingCheck struct {
+	URL          string
+	Type         string
+	CacheControl string
+	Expires      string
+	Vary         string
+	// Cacheable is false if the resource must not be stored, e.g. because of no-store
+	Cacheable bool
+	// TTL is the freshness lifetime from max-age or Expires, 0 if the resource is revalidated on every use
+	TTL time.Duration
+	// Revalidatable is set if the resource has an ETag or Last-Modified validator
+	Revalidatable bool
+	Issues        []string
+	// SavingsBytes is the transfer saved on repeat visits if the static asset could be cached
+	SavingsBytes int64
+}
+
+// CachingReport is the cacheability of the page and its resources.
+type CachingReport struct {
+	Checks []*CachingCheck
+	// UncacheableNum is the number of static assets downloaded again on every visit
+	UncacheableNum int
+	SavingsBytes   int64
+}
+
+// TTLText returns the formatted cache lifetime.
+func (c *CachingCheck) TTLText() string {
+	return formatTTL(c.TTL)
+}
+
+// SavingsSize returns the formatted transfer saved on repeat visits.
+func (r *CachingReport) SavingsSize() string {
+	return formatBytes(r.SavingsBytes)
+}
+
+// cachingReport checks the caching headers of the downloaded resources.
+func cachingReport(resources []*WeightedResource, now time.Time) *CachingReport {
+	report := &CachingReport{}
+
+	for _, resource := range resources {
+		if resource.Error != "" {
+			continue
+		}
+
+		check := checkCaching(resource, now)
+		report.Checks = append(report.Checks, check)
+
+		if check.SavingsBytes > 0 {
+			report.UncacheableNum++
+			report.SavingsBytes += check.SavingsBytes
+		}
+	}
+
+	return report
+}
+
+// checkCaching checks the caching headers of a resource.
+func checkCaching(resource *WeightedResource, now time.Time) *CachingCheck {
+	check := &CachingCheck{
+		URL:           resource.URL,
+		Type:          resource.Type,
+		CacheControl:  resource.CacheControl,
+		Expires:       resource.Expires,
+		Vary:          resource.Vary,
+		Cacheable:     true,
+		Revalidatable: resource.ETag != "" || resource.LastModified != "",
+	}
+
+	directives := parseCacheControl(resource.CacheControl)
+	_, noStore := directives["no-store"]
+	_, noCache := directives["no-cache"]
+	_, public := directives["public"]
+	_, private := directives["private"]
+
+	if noStore {
+		check.Cacheable = false
+		for _, directive := range []string{"max-age", "s-maxage", "public", "immutable"} {
+			if _, ok := directives[directive]; ok {
+				check.Issues = append(check.Issues, fmt.Sprintf("Cache-Control combines no-store with %s, no-store wins", directive))
+			}
+		}
+	}
+	if public && private {
+		check.Issues = append(check.Issues, "Cache-Control combines public and private")
+	}
+
+	maxAge, hasMaxAge := -1, false
+	if value, ok := directives["max-age"]; ok {
+		if age, err := strconv.Atoi(value); err == nil && age >= 0 {
+			maxAge, hasMaxAge = age, true
+		} else {
+			check.Issues = append(check.Issues, fmt.Sprintf("invalid max-age %q", value))
+		}
+	}
+
+	var (
+		expiresTTL time.Duration
+		hasExpires bool
+	)
+	if resource.Expires != "" {
+		if expires, err := http.ParseTime(resource.Expires); err == nil {
+			expiresTTL, hasExpires = expires.Sub(now), true
+		} else if !hasMaxAge {
+			// Caches treat an invalid date as already expired, max-age takes precedence anyway
+			check.Issues = append(check.Issues, fmt.Sprintf("invalid Expires date %q, it is treated as already expired", resource.Expires))
+		}
+	}
+
+	// max-age takes precedence over Expires
+	switch {
+	case hasMaxAge:
+		check.TTL = time.Duration(maxAge) * time.Second
+	case hasExpires && expiresTTL > 0:
+		check.TTL = expiresTTL
+	}
+	if noCache || noStore {
+		check.TTL = 0
+	}
+
+	for _, field := range strings.Split(resource.Vary, ",") {
+		switch field = strings.ToLower(strings.TrimSpace(field)); field {
+		case "*":
+			check.Cacheable = false
+			check.Issues = append(check.Issues, `"Vary: *" prevents caching`)
+		case "user-agent", "cookie":
+			check.Issues = append(check.Issues, fmt.Sprintf("\"Vary: %s\" splits the cache into many variants and lowers the hit rate", field))
+		}
+	}
+
+	if !slices.Contains(staticWeightTypes, resource.Type) {
+		if check.Cacheable && !check.Revalidatable {
+			check.Issues = append(check.Issues, "no ETag or Last-Modified, so it cannot be revalidated")
+		}
+		return check
+	}
+
+	hasFreshness := hasMaxAge || hasExpires
+	switch {
+	case !check.Cacheable:
+		check.SavingsBytes = resource.CompressedBytes
+		check.Issues = append(check.Issues, "static asset is not cacheable, it is downloaded again on every visit")
+	case check.TTL == 0 && !check.Revalidatable:
+		check.SavingsBytes = resource.CompressedBytes
+		if hasFreshness || noCache {
+			check.Issues = append(check.Issues, "static asset expires immediately and has no validator, it is downloaded again on every visit")
+		} else {
+			check.Issues = append(check.Issues, "no Cache-Control max-age, Expires or validator, it is downloaded again on every visit")
+		}
+	case check.TTL == 0 && !hasFreshness && !noCache:
+		check.Issues = append(check.Issues, "no Cache-Control max-age or Expires, browsers guess the cache lifetime")
+	case check.TTL == 0:
+		check.Issues = append(check.Issues, "static asset is revalidated on every visit, which costs a round trip")
+	case check.TTL < shortCacheTTL:
+		check.Issues = append(check.Issues, fmt.Sprintf("short cache lifetime of %s, use at least %s for static assets",
+			formatTTL(check.TTL), formatTTL(shortCacheTTL)))
+	}
+
+	return check
+}
+
+// parseCacheControl returns the Cache-Control directives by lowercase name, with unquoted values.
+func parseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+
+	for _, directive := range strings.Split(value, ",") {
+		name, directiveValue, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			directives[name] = strings.Trim(strings.TrimSpace(directiveValue), `"`)
+		}
+	}
+
+	return directives
+}
+
+// formatTTL formats a cache lifetime in the largest whole unit, e.g. "7 days" or "90 seconds".
+func formatTTL(ttl time.Duration) string {
+	units := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{name: "day", duration: 24 * time.Hour},
+		{name: "hour", duration: time.Hour},
+		{name: "minute", duration: time.Minute},
+	}
+
+	for _, unit := range units {
+		if ttl >= unit.duration && ttl%unit.duration == 0 {
+			return pluralize(int(ttl/unit.duration), unit.name)
+		}
+	}
+
+	return pluralize(int(ttl/time.Second), "second")
+}
+
+func pluralize(count int, name string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, name)
+	}
+
+	return fmt.Sprintf("%d %ss", count, name)
+}
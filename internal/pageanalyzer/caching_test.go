@@ -0,0 +1,84 @@
+package pageanalyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachingReport(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	resources := []*WeightedResource{
+		{URL: "https://example.com/", Type: WeightTypeHTML, CacheControl: "no-cache"},
+		{URL: "https://example.com/app.js", Type: WeightTypeJS, CacheControl: "public, max-age=31536000, immutable", CompressedBytes: 1000},
+		{URL: "https://example.com/site.css", Type: WeightTypeCSS, CacheControl: "no-store, max-age=600", CompressedBytes: 2000},
+		{URL: "https://example.com/logo.png", Type: WeightTypeImage, CacheControl: "max-age=3600", Expires: "Sat, 01 Jun 2024 11:00:00 GMT", ETag: `"1"`, CompressedBytes: 3000},
+		{URL: "https://example.com/font.woff2", Type: WeightTypeFont, Expires: "Sun, 02 Jun 2024 12:00:00 GMT", Vary: "Accept-Encoding, User-Agent", CompressedBytes: 4000},
+		{URL: "https://example.com/photo.jpg", Type: WeightTypeImage, LastModified: "Wed, 01 May 2024 12:00:00 GMT", CompressedBytes: 5000},
+		{URL: "https://example.com/banner.jpg", Type: WeightTypeImage, Expires: "0", CompressedBytes: 6000},
+		{URL: "https://example.com/icon.png", Type: WeightTypeImage, CacheControl: "max-age=0, must-revalidate", ETag: `"2"`, CompressedBytes: 7000},
+		{URL: "https://example.com/missing.js", Type: WeightTypeJS, Error: "request failed with status 404"},
+	}
+
+	report := cachingReport(resources, now)
+
+	if !assert.Len(t, report.Checks, 8) {
+		return
+	}
+
+	page := report.Checks[0]
+	assert.True(t, page.Cacheable)
+	assert.Zero(t, page.TTL)
+	assert.Equal(t, []string{"no ETag or Last-Modified, so it cannot be revalidated"}, page.Issues)
+
+	app := report.Checks[1]
+	assert.Equal(t, 365*24*time.Hour, app.TTL)
+	assert.Equal(t, "365 days", app.TTLText())
+	assert.Empty(t, app.Issues)
+
+	stylesheet := report.Checks[2]
+	assert.False(t, stylesheet.Cacheable)
+	assert.Equal(t, int64(2000), stylesheet.SavingsBytes)
+	assert.Equal(t, []string{
+		"Cache-Control combines no-store with max-age, no-store wins",
+		"static asset is not cacheable, it is downloaded again on every visit",
+	}, stylesheet.Issues)
+
+	// max-age takes precedence over an Expires date in the past
+	logo := report.Checks[3]
+	assert.Equal(t, time.Hour, logo.TTL)
+	assert.Equal(t, []string{"short cache lifetime of 1 hour, use at least 7 days for static assets"}, logo.Issues)
+
+	font := report.Checks[4]
+	assert.Equal(t, 24*time.Hour, font.TTL)
+	assert.Equal(t, []string{
+		`"Vary: user-agent" splits the cache into many variants and lowers the hit rate`,
+		"short cache lifetime of 1 day, use at least 7 days for static assets",
+	}, font.Issues)
+
+	photo := report.Checks[5]
+	assert.True(t, photo.Revalidatable)
+	assert.Equal(t, []string{"no Cache-Control max-age or Expires, browsers guess the cache lifetime"}, photo.Issues)
+
+	banner := report.Checks[6]
+	assert.Equal(t, int64(6000), banner.SavingsBytes)
+	assert.Equal(t, []string{
+		`invalid Expires date "0", it is treated as already expired`,
+		"no Cache-Control max-age, Expires or validator, it is downloaded again on every visit",
+	}, banner.Issues)
+
+	icon := report.Checks[7]
+	assert.Equal(t, []string{"static asset is revalidated on every visit, which costs a round trip"}, icon.Issues)
+
+	assert.Equal(t, 2, report.UncacheableNum)
+	assert.Equal(t, int64(8000), report.SavingsBytes)
+}
+
+func TestFormatTTL(t *testing.T) {
+	assert.Equal(t, "0 seconds", formatTTL(0))
+	assert.Equal(t, "90 seconds", formatTTL(90*time.Second))
+	assert.Equal(t, "2 minutes", formatTTL(2*time.Minute))
+	assert.Equal(t, "36 hours", formatTTL(36*time.Hour))
+}
@@ -0,0 +1,272 @@
+package pageanalyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+)
+
+// minCompressibleBytes is the size below which compressing a resource does not pay off.
+const minCompressibleBytes = 1 << 10
+
+// Content encodings
+const (
+	EncodingGzip   = "gzip"
+	EncodingBrotli = "br"
+	EncodingZstd   = "zstd"
+	EncodingNone   = "none"
+)
+
+// probedEncodings are requested one at a time to find out which encodings the server supports.
+var probedEncodings = []string{EncodingGzip, EncodingBrotli, EncodingZstd}
+
+// compressibleMediaTypes are the formats other than text/*, +json and +xml that shrink with compression.
+var compressibleMediaTypes = []string{
+	"application/javascript", "application/x-javascript", "application/ecmascript", "application/json",
+	"application/xml", "application/wasm", "application/vnd.ms-fontobject", "font/ttf", "font/otf",
+	"application/x-font-ttf", "application/x-font-otf", "image/svg+xml", "image/x-icon", "image/vnd.microsoft.icon",
+	"image/bmp",
+}
+
+// EncodingSize is the transferred size of a resource served with a content encoding.
+type EncodingSize struct {
+	Encoding string
+	Bytes    int64
+}
+
+// CompressionCheck is the compression support of a text based resource.
+type CompressionCheck struct {
+	URL               string
+	Type              string
+	UncompressedBytes int64
+	// Encodings are the encodings the server supports, with the transferred size
+	Encodings []EncodingSize
+	// FailedEncodings are the encodings whose probe failed, their support is unknown
+	FailedEncodings []string
+	Issues          []string
+	// SavingsBytes is the estimated transfer saved by serving an uncompressed resource with gzip
+	SavingsBytes int64
+
+	// resource is the page weight download, its encoding answers the gzip probe
+	resource *WeightedResource
+	// uncompressed is set if a response was not compressed and no encoding is supported
+	uncompressed bool
+}
+
+// CompressionReport is the compression support of the compressible resources of the page.
+type CompressionReport struct {
+	Checks []*CompressionCheck
+	// EncodingCounts is the number of resources each encoding is supported for, EncodingNone counts the
+	// resources served uncompressed
+	EncodingCounts map[string]int
+	// FailedProbesNum is the number of encoding probes that failed
+	FailedProbesNum int
+	SavingsBytes    int64
+}
+
+// Size returns the formatted transferred size.
+func (e EncodingSize) Size() string {
+	return formatBytes(e.Bytes)
+}
+
+// SavingsSize returns the formatted estimated savings.
+func (r *CompressionReport) SavingsSize() string {
+	return formatBytes(r.SavingsBytes)
+}
+
+// encodingProbe is the response to a request that accepts a single encoding.
+type encodingProbe struct {
+	encoding string
+	content  []byte
+	vary     string
+}
+
+// compressionReport requests every compressible resource with brotli and zstd to find the encodings the server
+// supports. The page weight download accepted gzip, so its encoding tells whether gzip is supported. The savings of
+// uncompressed resources are estimated by compressing them with gzip.
+func (w *WebpageAnalyzer) compressionReport(ctx context.Context, resources []*WeightedResource) *CompressionReport {
+	report := &CompressionReport{EncodingCounts: make(map[string]int)}
+
+	for _, resource := range resources {
+		if resource.Error == "" && resource.UncompressedBytes >= minCompressibleBytes && isCompressible(resource.ContentType) {
+			report.Checks = append(report.Checks, &CompressionCheck{
+				URL:               resource.URL,
+				Type:              resource.Type,
+				UncompressedBytes: resource.UncompressedBytes,
+				resource:          resource,
+			})
+		}
+	}
+
+	var (
+		semaphore = make(chan struct{}, pageWeightConcurrency)
+		wg        sync.WaitGroup
+	)
+	for _, check := range report.Checks {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(check *CompressionCheck) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			w.checkCompression(ctx, check)
+		}(check)
+	}
+	wg.Wait()
+
+	for _, check := range report.Checks {
+		for _, encodingSize := range check.Encodings {
+			report.EncodingCounts[encodingSize.Encoding]++
+		}
+		if check.uncompressed {
+			report.EncodingCounts[EncodingNone]++
+		}
+		report.FailedProbesNum += len(check.FailedEncodings)
+		report.SavingsBytes += check.SavingsBytes
+	}
+
+	return report
+}
+
+// checkCompression probes the encodings of the resource the page weight download did not answer and fills in the
+// check.
+func (w *WebpageAnalyzer) checkCompression(ctx context.Context, check *CompressionCheck) {
+	var (
+		uncompressed       []byte
+		servedUncompressed bool
+		missingVary        []string
+	)
+
+	addEncoding := func(encoding string, size int64, vary string) {
+		check.Encodings = append(check.Encodings, EncodingSize{Encoding: encoding, Bytes: size})
+		if !headerListContains(vary, "Accept-Encoding") {
+			missingVary = append(missingVary, encoding)
+		}
+	}
+
+	for _, encoding := range probedEncodings {
+		// The page weight download accepted gzip, a gzip or identity response answers the gzip probe
+		if resource := check.resource; encoding == EncodingGzip && resource != nil {
+			switch resource.ContentEncoding {
+			case EncodingGzip, "x-gzip":
+				addEncoding(EncodingGzip, resource.CompressedBytes, resource.Vary)
+				continue
+			case "", "identity":
+				servedUncompressed = true
+				continue
+			}
+		}
+
+		probe, err := w.probeEncoding(ctx, check.URL, encoding)
+		if err != nil {
+			logrus.WithError(err).WithField("url", check.URL).Debug("probe content encoding failed")
+			check.FailedEncodings = append(check.FailedEncodings, encoding)
+			continue
+		}
+
+		switch probe.encoding {
+		case encoding:
+			addEncoding(encoding, int64(len(probe.content)), probe.vary)
+		case "", "identity":
+			uncompressed, servedUncompressed = probe.content, true
+		}
+	}
+
+	if len(missingVary) > 0 {
+		check.Issues = append(check.Issues, fmt.Sprintf(
+			"served with %s without \"Vary: Accept-Encoding\", shared caches may serve it to clients that cannot decode it",
+			strings.Join(missingVary, ", "),
+		))
+	}
+
+	if len(check.Encodings) > 0 || !servedUncompressed {
+		return
+	}
+	check.uncompressed = true
+
+	if savings := int64(len(uncompressed)) - gzipSize(uncompressed); uncompressed != nil && savings > 0 {
+		check.SavingsBytes = savings
+		check.Issues = append(check.Issues, fmt.Sprintf("served uncompressed, gzip would save about %s", formatBytes(savings)))
+	} else {
+		check.Issues = append(check.Issues, "served uncompressed")
+	}
+}
+
+// probeEncoding requests the resource accepting only the given encoding and returns the raw response body.
+func (w *WebpageAnalyzer) probeEncoding(ctx context.Context, resourceURL, encoding string) (*encodingProbe, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resourceURL, nil /* body */)
+	if err != nil {
+		return nil, fmt.Errorf("new request with context failed: %v", err)
+	}
+
+	// Setting the header disables the transparent decompression of the client
+	req.Header.Set("Accept-Encoding", encoding)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxResourceBytes))
+	if err != nil {
+		return nil, fmt.Errorf("read response body failed: %v", err)
+	}
+
+	return &encodingProbe{
+		encoding: strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))),
+		content:  content,
+		vary:     strings.Join(resp.Header.Values("Vary"), ", "),
+	}, nil
+}
+
+// isCompressible reports whether the Content-Type is a text based format that shrinks with compression.
+func isCompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml") || slices.Contains(compressibleMediaTypes, mediaType)
+}
+
+// gzipSize returns the size of the content compressed with gzip at the default level.
+func gzipSize(content []byte) int64 {
+	var buf bytes.Buffer
+
+	gzipWriter := gzip.NewWriter(&buf)
+	_, _ = gzipWriter.Write(content)
+	_ = gzipWriter.Close()
+
+	return int64(buf.Len())
+}
+
+// headerListContains reports whether a comma separated header value, e.g. Vary, contains the token.
+func headerListContains(value, token string) bool {
+	for _, item := range strings.Split(value, ",") {
+		if strings.EqualFold(strings.TrimSpace(item), token) {
+			return true
+		}
+	}
+
+	return false
+}
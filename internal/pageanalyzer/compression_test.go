@@ -0,0 +1,122 @@
+package pageanalyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionReport(t *testing.T) {
+	text := strings.Repeat("function compress() { return 'text'; }\n", 100)
+
+	var gzipped bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	_, _ = gzipWriter.Write([]byte(text))
+	_ = gzipWriter.Close()
+
+	brotli := []byte("brotli-bytes")
+
+	var (
+		probesLock sync.Mutex
+		gzipProbes []string
+	)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding := r.Header.Get("Accept-Encoding")
+		if acceptEncoding == EncodingGzip {
+			probesLock.Lock()
+			gzipProbes = append(gzipProbes, r.URL.Path)
+			probesLock.Unlock()
+		}
+
+		switch r.URL.Path {
+		case "/modern.js":
+			// Supports gzip and brotli, but forgets Vary for brotli
+			switch acceptEncoding {
+			case EncodingGzip:
+				w.Header().Set("Vary", "Accept-Encoding")
+				w.Header().Set("Content-Encoding", EncodingGzip)
+				_, _ = w.Write(gzipped.Bytes())
+			case EncodingBrotli:
+				w.Header().Set("Content-Encoding", EncodingBrotli)
+				_, _ = w.Write(brotli)
+			default:
+				_, _ = w.Write([]byte(text))
+			}
+		case "/plain.css":
+			_, _ = w.Write([]byte(text))
+		case "/unavailable.js":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer testServer.Close()
+
+	analyzer := New(&Config{}, testServer.Client())
+
+	// The page weight downloads accepted gzip, their encoding answers the gzip probe
+	resources := []*WeightedResource{
+		{
+			URL: testServer.URL + "/modern.js", Type: WeightTypeJS, ContentType: "text/javascript", ContentEncoding: EncodingGzip,
+			Vary: "Accept-Encoding", CompressedBytes: int64(gzipped.Len()), UncompressedBytes: int64(len(text)),
+		},
+		{URL: testServer.URL + "/plain.css", Type: WeightTypeCSS, ContentType: "text/css; charset=utf-8", UncompressedBytes: int64(len(text))},
+		// Served with deflate, so gzip support is unknown, and every probe fails
+		{URL: testServer.URL + "/unavailable.js", Type: WeightTypeJS, ContentType: "text/javascript", ContentEncoding: "deflate", UncompressedBytes: 5000},
+		// Skipped: already compressed format, too small to compress and failed downloads
+		{URL: testServer.URL + "/photo.jpg", Type: WeightTypeImage, ContentType: "image/jpeg", UncompressedBytes: 50000},
+		{URL: testServer.URL + "/tiny.css", Type: WeightTypeCSS, ContentType: "text/css", UncompressedBytes: 100},
+		{URL: testServer.URL + "/missing.js", Type: WeightTypeJS, ContentType: "text/javascript", UncompressedBytes: 5000, Error: "request failed with status 404"},
+	}
+
+	report := analyzer.compressionReport(context.Background(), resources)
+
+	if !assert.Len(t, report.Checks, 3) {
+		return
+	}
+
+	assert.Equal(t, []string{"/unavailable.js"}, gzipProbes)
+
+	modern := report.Checks[0]
+	assert.Equal(t, []EncodingSize{
+		{Encoding: EncodingGzip, Bytes: int64(gzipped.Len())},
+		{Encoding: EncodingBrotli, Bytes: int64(len(brotli))},
+	}, modern.Encodings)
+	assert.Equal(t, []string{
+		`served with br without "Vary: Accept-Encoding", shared caches may serve it to clients that cannot decode it`,
+	}, modern.Issues)
+	assert.Zero(t, modern.SavingsBytes)
+
+	plain := report.Checks[1]
+	assert.Empty(t, plain.Encodings)
+	assert.Equal(t, int64(len(text))-gzipSize([]byte(text)), plain.SavingsBytes)
+	assert.Greater(t, plain.SavingsBytes, int64(3000))
+	assert.Len(t, plain.Issues, 1)
+	assert.Contains(t, plain.Issues[0], "served uncompressed, gzip would save about")
+
+	unavailable := report.Checks[2]
+	assert.Empty(t, unavailable.Encodings)
+	assert.Equal(t, probedEncodings, unavailable.FailedEncodings)
+	assert.Empty(t, unavailable.Issues)
+
+	// The resource whose probes failed is not counted as uncompressed
+	assert.Equal(t, map[string]int{EncodingGzip: 1, EncodingBrotli: 1, EncodingNone: 1}, report.EncodingCounts)
+	assert.Equal(t, 3, report.FailedProbesNum)
+	assert.Equal(t, plain.SavingsBytes, report.SavingsBytes)
+}
+
+func TestIsCompressible(t *testing.T) {
+	assert.True(t, isCompressible("text/html; charset=utf-8"))
+	assert.True(t, isCompressible("application/manifest+json"))
+	assert.True(t, isCompressible("image/svg+xml"))
+	assert.False(t, isCompressible("image/png"))
+	assert.False(t, isCompressible("font/woff2"))
+	assert.False(t, isCompressible(""))
+}
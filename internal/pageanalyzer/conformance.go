@@ -0,0 +1,296 @@
+package pageanalyzer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/html"
+)
+
+// maxConformanceIssues caps the number of reported issues, broken pages can produce thousands.
+const maxConformanceIssues = 200
+
+// Conformance rules
+const (
+	ConformanceMissingDoctype     = "missing-doctype"
+	ConformanceQuirksMode         = "quirks-mode"
+	ConformanceXHTMLAsHTML        = "xhtml-as-html"
+	ConformanceUnclosedElement    = "unclosed-element"
+	ConformanceStrayEndTag        = "stray-end-tag"
+	ConformanceInvalidNesting     = "invalid-nesting"
+	ConformanceDuplicateAttribute = "duplicate-attribute"
+	ConformanceObsoleteElement    = "obsolete-element"
+	ConformanceSelfClosingNonVoid = "self-closing-non-void"
+)
+
+// voidElements never have content or an end tag.
+var voidElements = []string{
+	"area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "source", "track", "wbr",
+	"param", "keygen", "basefont", "bgsound", "frame",
+}
+
+// optionalEndTagElements may be closed implicitly by the parser.
+var optionalEndTagElements = []string{
+	"html", "head", "body", "p", "li", "dt", "dd", "option", "optgroup", "tr", "td", "th",
+	"thead", "tbody", "tfoot", "colgroup", "caption", "rb", "rt", "rtc", "rp",
+}
+
+// obsoleteElements are no longer part of HTML, with the suggested replacement.
+var obsoleteElements = map[string]string{
+	"acronym":   "use <abbr>",
+	"applet":    "use <embed> or <object>",
+	"basefont":  "use CSS",
+	"bgsound":   "use <audio>",
+	"big":       "use CSS",
+	"blink":     "use CSS animations",
+	"center":    "use CSS text-align or margins",
+	"dir":       "use <ul>",
+	"font":      "use CSS",
+	"frame":     "use <iframe> or CSS layouts",
+	"frameset":  "use <iframe> or CSS layouts",
+	"isindex":   "use a <form> with a text field",
+	"keygen":    "use the Web Crypto API",
+	"listing":   "use <pre> and <code>",
+	"marquee":   "use CSS animations",
+	"menuitem":  "use <button> or other interactive elements",
+	"multicol":  "use CSS columns",
+	"nextid":    "remove it",
+	"nobr":      "use CSS white-space: nowrap",
+	"noembed":   "use <object> fallback content",
+	"noframes":  "remove it",
+	"plaintext": "use <pre> and the text/plain MIME type",
+	"spacer":    "use CSS",
+	"strike":    "use <del> or <s>",
+	"tt":        "use <code>, <kbd>, <samp> or CSS",
+	"xmp":       "use <pre> and <code>",
+}
+
+// paragraphClosingElements implicitly close an open <p>.
+var paragraphClosingElements = []string{
+	"address", "article", "aside", "blockquote", "details", "dialog", "div", "dl", "fieldset", "figcaption", "figure",
+	"footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hgroup", "hr", "main", "menu", "nav", "ol", "p",
+	"pre", "section", "table", "ul",
+}
+
+// interactiveElements can't be nested in each other.
+var interactiveElements = []string{"a", "button", "details", "embed", "iframe", "label", "select", "textarea"}
+
+var headingElements = []string{"h1", "h2", "h3", "h4", "h5", "h6"}
+
+// ConformanceIssue is an HTML syntax or content model error, with the line it was found on.
+type ConformanceIssue struct {
+	Rule    string
+	Message string
+	Line    int
+}
+
+// ConformanceReport lists the HTML conformance issues of the page.
+type ConformanceReport struct {
+	Doctype *Doctype
+	Issues  []ConformanceIssue
+	// Truncated is set if more than maxConformanceIssues issues were found.
+	Truncated bool
+}
+
+// openElement is an element on the stack of open elements.
+type openElement struct {
+	name string
+	line int
+}
+
+// conformanceChecker tokenizes the page and tracks the open elements to find conformance issues.
+type conformanceChecker struct {
+	report *ConformanceReport
+	stack  []openElement
+	line   int
+}
+
+func (c *conformanceChecker) addIssue(rule string, line int, format string, args ...any) {
+	if len(c.report.Issues) >= maxConformanceIssues {
+		c.report.Truncated = true
+		return
+	}
+
+	c.report.Issues = append(c.report.Issues, ConformanceIssue{Rule: rule, Message: fmt.Sprintf(format, args...), Line: line})
+}
+
+// isOpen reports whether an element with one of the names is open.
+func (c *conformanceChecker) isOpen(names ...string) bool {
+	for _, element := range c.stack {
+		if slices.Contains(names, element.name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inForeignContent reports whether the tokenizer is inside SVG or MathML, where XML rules apply.
+func (c *conformanceChecker) inForeignContent() bool {
+	return c.isOpen("svg", "math")
+}
+
+// checkConformance checks the doctype and markup of the page. contentType is the Content-Type of the response,
+// an empty one is treated as text/html.
+func checkConformance(pageContent []byte, doctype *Doctype, contentType string) *ConformanceReport {
+	checker := &conformanceChecker{report: &ConformanceReport{Doctype: doctype}, line: 1}
+
+	switch {
+	case !doctype.Found:
+		checker.addIssue(ConformanceMissingDoctype, 0, "the page has no doctype, so browsers render it in quirks mode; add <!DOCTYPE html>")
+	case doctype.Mode != ModeNoQuirks:
+		checker.addIssue(ConformanceQuirksMode, 0, "the doctype puts browsers in %s mode; use <!DOCTYPE html>", doctype.Mode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if doctype.IsXHTML() && (contentType == "" || mediaType == "text/html") {
+		checker.addIssue(ConformanceXHTMLAsHTML, 0, "the %s doctype is served as text/html, so browsers parse the page as HTML, not XML", doctype.Version)
+	}
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(pageContent))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		// The token starts on the current line, the lines it spans are counted after it is checked
+		line := checker.line
+		newlines := bytes.Count(tokenizer.Raw(), []byte("\n"))
+
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			checker.startTag(token, tokenType == html.SelfClosingTagToken, line)
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			checker.endTag(string(name), line)
+		}
+
+		checker.line += newlines
+	}
+
+	for i := len(checker.stack) - 1; i >= 0; i-- {
+		element := checker.stack[i]
+		if !slices.Contains(optionalEndTagElements, element.name) {
+			checker.addIssue(ConformanceUnclosedElement, element.line, "<%s> is never closed", element.name)
+		}
+	}
+
+	return checker.report
+}
+
+func (c *conformanceChecker) startTag(token html.Token, selfClosing bool, line int) {
+	name := token.Data
+	foreign := c.inForeignContent()
+
+	seenAttrs := make(map[string]bool)
+	for _, attr := range token.Attr {
+		key := strings.ToLower(attr.Key)
+		if attr.Namespace != "" {
+			key = attr.Namespace + ":" + key
+		}
+		if seenAttrs[key] {
+			c.addIssue(ConformanceDuplicateAttribute, line, "<%s> has a duplicate %q attribute, browsers keep only the first one", name, attr.Key)
+		}
+		seenAttrs[key] = true
+	}
+
+	if foreign {
+		if !selfClosing {
+			c.stack = append(c.stack, openElement{name: name, line: line})
+		}
+		return
+	}
+
+	if replacement, ok := obsoleteElements[name]; ok {
+		c.addIssue(ConformanceObsoleteElement, line, "<%s> is obsolete, %s", name, replacement)
+	}
+
+	c.checkNesting(name, line)
+
+	if slices.Contains(voidElements, name) {
+		return
+	}
+
+	if selfClosing && name != "svg" && name != "math" {
+		c.addIssue(ConformanceSelfClosingNonVoid, line, "<%s/> is not a void element, the slash is ignored and the element stays open", name)
+	}
+
+	if selfClosing && (name == "svg" || name == "math") {
+		return
+	}
+
+	c.stack = append(c.stack, openElement{name: name, line: line})
+}
+
+// checkNesting reports elements the parser can't nest where they are, and closes the elements it closes implicitly.
+func (c *conformanceChecker) checkNesting(name string, line int) {
+	top := ""
+	if len(c.stack) > 0 {
+		top = c.stack[len(c.stack)-1].name
+	}
+
+	switch {
+	case slices.Contains(paragraphClosingElements, name) && c.isOpen("p") && !c.isOpen("button", "table"):
+		// The parser closes the paragraph, so the content ends up after it
+		if top != "p" {
+			c.addIssue(ConformanceInvalidNesting, line, "<%s> is not allowed inside <p>, the paragraph is closed before it", name)
+		}
+		c.closeImplicitly("p")
+	case name == "li" && top == "li", (name == "dt" || name == "dd") && (top == "dt" || top == "dd"),
+		name == "option" && top == "option", name == "tr" && top == "tr", (name == "td" || name == "th") && (top == "td" || top == "th"):
+		// Siblings with optional end tags close each other
+		c.stack = c.stack[:len(c.stack)-1]
+	}
+
+	switch {
+	case slices.Contains(interactiveElements, name) && c.isOpen("a", "button"):
+		c.addIssue(ConformanceInvalidNesting, line, "interactive <%s> is not allowed inside <a> or <button>", name)
+	case name == "form" && c.isOpen("form"):
+		c.addIssue(ConformanceInvalidNesting, line, "<form> can't be nested in another <form>, browsers ignore it")
+	case slices.Contains(headingElements, name) && c.isOpen(headingElements...):
+		c.addIssue(ConformanceInvalidNesting, line, "<%s> is not allowed inside another heading", name)
+	case name == "li" && !c.isOpen("ul", "ol", "menu"):
+		c.addIssue(ConformanceInvalidNesting, line, "<li> must be inside <ul>, <ol> or <menu>")
+	}
+}
+
+// closeImplicitly pops the stack up to and including the innermost element with the name.
+func (c *conformanceChecker) closeImplicitly(name string) {
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		if c.stack[i].name == name {
+			c.stack = c.stack[:i]
+			return
+		}
+	}
+}
+
+func (c *conformanceChecker) endTag(name string, line int) {
+	index := -1
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		if c.stack[i].name == name {
+			index = i
+			break
+		}
+	}
+
+	if index == -1 {
+		// </br> is turned into <br> by the parser, other end tags without a start tag are dropped
+		if !slices.Contains(voidElements, name) {
+			c.addIssue(ConformanceStrayEndTag, line, "</%s> has no matching start tag", name)
+		}
+		return
+	}
+
+	for _, element := range c.stack[index+1:] {
+		if !slices.Contains(optionalEndTagElements, element.name) {
+			c.addIssue(ConformanceUnclosedElement, element.line, "<%s> is not closed before </%s> on line %d", element.name, name, line)
+		}
+	}
+
+	c.stack = c.stack[:index]
+}
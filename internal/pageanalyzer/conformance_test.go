@@ -0,0 +1,74 @@
+package pageanalyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConformance(t *testing.T) {
+	t.Run("Conforming page", func(t *testing.T) {
+		htmlContent := []byte(`<!DOCTYPE html>
+<html lang="en">
+<head><title>Test</title><meta charset="utf-8"></head>
+<body>
+	<p>First paragraph
+	<p>Second paragraph</p>
+	<ul><li>One<li>Two</ul>
+	<svg><path d="M0 0"/><circle r="1"></circle></svg>
+	<br/><img src="a.png" alt="">
+	<script>if (a < b) { document.write("</div>") }</script>
+</body>
+</html>`)
+
+		report := checkConformance(htmlContent, parseDoctype(htmlContent), "text/html; charset=utf-8")
+
+		assert.Empty(t, report.Issues)
+	})
+
+	t.Run("Broken page", func(t *testing.T) {
+		htmlContent := []byte(`<html>
+<body>
+	<center><font color="red">Old</font></center>
+	<p>Text <div>block</div></p>
+	<a href="/a"><button>Go</button></a>
+	<div class="a" class="b">
+		<span>unclosed
+	</div>
+	<div/>
+	</section>
+	<li>orphan</li>
+</body>
+</html>`)
+
+		report := checkConformance(htmlContent, parseDoctype(htmlContent), "")
+
+		assert.Equal(t, []ConformanceIssue{
+			{Rule: ConformanceMissingDoctype, Message: "the page has no doctype, so browsers render it in quirks mode; add <!DOCTYPE html>"},
+			{Rule: ConformanceObsoleteElement, Message: "<center> is obsolete, use CSS text-align or margins", Line: 3},
+			{Rule: ConformanceObsoleteElement, Message: "<font> is obsolete, use CSS", Line: 3},
+			{Rule: ConformanceStrayEndTag, Message: "</p> has no matching start tag", Line: 4},
+			{Rule: ConformanceInvalidNesting, Message: "interactive <button> is not allowed inside <a> or <button>", Line: 5},
+			{Rule: ConformanceDuplicateAttribute, Message: `<div> has a duplicate "class" attribute, browsers keep only the first one`, Line: 6},
+			{Rule: ConformanceUnclosedElement, Message: "<span> is not closed before </div> on line 8", Line: 7},
+			{Rule: ConformanceSelfClosingNonVoid, Message: "<div/> is not a void element, the slash is ignored and the element stays open", Line: 9},
+			{Rule: ConformanceStrayEndTag, Message: "</section> has no matching start tag", Line: 10},
+			{Rule: ConformanceInvalidNesting, Message: "<li> must be inside <ul>, <ol> or <menu>", Line: 11},
+			{Rule: ConformanceUnclosedElement, Message: "<div> is not closed before </body> on line 12", Line: 9},
+		}, report.Issues)
+	})
+
+	t.Run("XHTML served as HTML", func(t *testing.T) {
+		htmlContent := []byte(`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>Test</title></head><body></body></html>`)
+
+		report := checkConformance(htmlContent, parseDoctype(htmlContent), "text/html")
+
+		assert.Equal(t, []ConformanceIssue{
+			{Rule: ConformanceQuirksMode, Message: "the doctype puts browsers in limited-quirks mode; use <!DOCTYPE html>"},
+			{Rule: ConformanceXHTMLAsHTML, Message: "the XHTML 1.0 doctype is served as text/html, so browsers parse the page as HTML, not XML"},
+		}, report.Issues)
+
+		assert.Empty(t, checkConformance(htmlContent, parseDoctype(htmlContent), "application/xhtml+xml").Issues[1:])
+	})
+}
@@ -0,0 +1,315 @@
+package pageanalyzer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/text/language"
+)
+
+const (
+	// maxKeywords caps the number of reported keywords and phrases.
+	maxKeywords = 10
+	// minLanguageStopWords is the number of stop words needed to detect a language.
+	minLanguageStopWords = 5
+)
+
+// readabilityFormula is a Flesch reading ease formula: Base - SentenceWeight*words per sentence - SyllableWeight*syllables per word.
+type readabilityFormula struct {
+	Name           string
+	Base           float64
+	SentenceWeight float64
+	SyllableWeight float64
+}
+
+// readabilityFormulas adapt the Flesch reading ease to each supported language, English is used for other languages.
+var readabilityFormulas = map[string]readabilityFormula{
+	"en": {Name: "Flesch reading ease", Base: 206.835, SentenceWeight: 1.015, SyllableWeight: 84.6},
+	"de": {Name: "Flesch reading ease (Amstad)", Base: 180, SentenceWeight: 1, SyllableWeight: 58.5},
+	"fr": {Name: "Flesch reading ease (Kandel & Moles)", Base: 207, SentenceWeight: 1.015, SyllableWeight: 73.6},
+	"es": {Name: "Flesch reading ease (Szigriszt-Pazos)", Base: 206.835, SentenceWeight: 1, SyllableWeight: 62.3},
+	"it": {Name: "Flesch reading ease (Flesch-Vacca)", Base: 217, SentenceWeight: 1.3, SyllableWeight: 60},
+	"nl": {Name: "Flesch reading ease (Douma)", Base: 206.835, SentenceWeight: 0.93, SyllableWeight: 77},
+	"pt": {Name: "Flesch reading ease (Martins)", Base: 248.835, SentenceWeight: 1.015, SyllableWeight: 84.6},
+}
+
+// vowels are the letters counted as syllable nuclei.
+const vowels = "aeiouyàáâãäåæèéêëìíîïòóôõöøùúûüýÿœ"
+
+// KeywordCount is a keyword or phrase with the number of times it occurs.
+type KeywordCount struct {
+	Term  string
+	Count int
+	// Density is the share of the words of the text, in percent.
+	Density float64
+}
+
+// ContentMetrics describes the visible text of the page.
+type ContentMetrics struct {
+	WordCount     int
+	SentenceCount int
+	SyllableCount int
+	// ReadingEase is a Flesch reading ease score, usually from 0 (very difficult) to 100 (very easy), computed with
+	// the adaptation of ReadabilityFormula for the detected language. Very short texts can score outside that range.
+	ReadingEase        float64
+	ReadabilityFormula string
+	// GradeLevel is the Flesch-Kincaid US school grade level, only meaningful for English text.
+	GradeLevel float64
+	// TextToHTMLRatio is the size of the visible text relative to the size of the HTML, in percent.
+	TextToHTMLRatio float64
+	// DetectedLanguage is the ISO 639-1 code of the dominant language, empty if it could not be detected.
+	DetectedLanguage string
+	DeclaredLanguage string
+	Keywords         []KeywordCount
+	Phrases          []KeywordCount
+	Issues           []string
+}
+
+// contentMetrics computes word, sentence and readability statistics, the language and the keywords of the text blocks.
+func contentMetrics(textBlocks []string, declaredLanguage string, htmlSize int) *ContentMetrics {
+	metrics := &ContentMetrics{DeclaredLanguage: declaredLanguage}
+
+	var (
+		sentences [][]string
+		textSize  int
+	)
+	for _, block := range textBlocks {
+		textSize += len(block)
+		sentences = append(sentences, splitSentences(block)...)
+	}
+
+	var words []string
+	for _, sentence := range sentences {
+		words = append(words, sentence...)
+	}
+
+	metrics.WordCount = len(words)
+	metrics.SentenceCount = len(sentences)
+	if htmlSize > 0 {
+		metrics.TextToHTMLRatio = float64(textSize) / float64(htmlSize) * 100
+	}
+
+	metrics.DetectedLanguage = detectLanguage(words)
+	metrics.Issues = languageIssues(declaredLanguage, metrics.DetectedLanguage)
+
+	if metrics.WordCount == 0 {
+		return metrics
+	}
+
+	for _, word := range words {
+		metrics.SyllableCount += countSyllables(word, metrics.DetectedLanguage)
+	}
+
+	formula, ok := readabilityFormulas[metrics.DetectedLanguage]
+	if !ok {
+		formula = readabilityFormulas["en"]
+	}
+
+	wordsPerSentence := float64(metrics.WordCount) / float64(metrics.SentenceCount)
+	syllablesPerWord := float64(metrics.SyllableCount) / float64(metrics.WordCount)
+	metrics.ReadabilityFormula = formula.Name
+	metrics.ReadingEase = formula.Base - formula.SentenceWeight*wordsPerSentence - formula.SyllableWeight*syllablesPerWord
+	metrics.GradeLevel = 0.39*wordsPerSentence + 11.8*syllablesPerWord - 15.59
+
+	keywordStopWords := stopWords[metrics.DetectedLanguage]
+	if keywordStopWords == nil {
+		keywordStopWords = stopWords["en"]
+	}
+	metrics.Keywords = topKeywords(sentences, keywordStopWords, metrics.WordCount)
+	metrics.Phrases = topPhrases(sentences, keywordStopWords, metrics.WordCount)
+
+	return metrics
+}
+
+// splitSentences splits the text into sentences of lowercase words. A sentence ends with ".", "!", "?" or "…",
+// and at the end of the text, since headings and list items often have no punctuation.
+func splitSentences(text string) [][]string {
+	var (
+		sentences [][]string
+		current   []string
+	)
+
+	for _, field := range strings.Fields(text) {
+		current = append(current, splitWords(field)...)
+
+		end := strings.TrimRight(field, `"'”’»)]`)
+		if strings.HasSuffix(end, ".") || strings.HasSuffix(end, "!") || strings.HasSuffix(end, "?") || strings.HasSuffix(end, "…") {
+			if len(current) > 0 {
+				sentences = append(sentences, current)
+			}
+			current = nil
+		}
+	}
+
+	if len(current) > 0 {
+		sentences = append(sentences, current)
+	}
+
+	return sentences
+}
+
+// splitWords returns the lowercase words of the text, keeping apostrophes inside words, e.g. "don't".
+func splitWords(text string) []string {
+	var words []string
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\'' && r != '’'
+	}) {
+		if word = strings.Trim(word, "'’"); word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+// countSyllables estimates the syllables of a word by counting vowel groups.
+func countSyllables(word, lang string) int {
+	syllables := 0
+	previousVowel := false
+	for _, r := range word {
+		isVowel := strings.ContainsRune(vowels, r)
+		if isVowel && !previousVowel {
+			syllables++
+		}
+		previousVowel = isVowel
+	}
+
+	// A final "e" after a consonant is silent in English, except in endings like "-le"
+	if lang == "en" && syllables > 1 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") {
+		if runes := []rune(word); !strings.ContainsRune(vowels, runes[len(runes)-2]) {
+			syllables--
+		}
+	}
+
+	if syllables == 0 {
+		return 1
+	}
+
+	return syllables
+}
+
+// detectLanguage returns the language whose stop words occur most often in the words.
+func detectLanguage(words []string) string {
+	var (
+		bestLanguage string
+		bestCount    int
+	)
+
+	languages := make([]string, 0, len(stopWords))
+	for lang := range stopWords {
+		languages = append(languages, lang)
+	}
+	slices.Sort(languages)
+
+	for _, lang := range languages {
+		count := 0
+		for _, word := range words {
+			if stopWords[lang][word] {
+				count++
+			}
+		}
+
+		if count > bestCount {
+			bestLanguage, bestCount = lang, count
+		}
+	}
+
+	if bestCount < minLanguageStopWords {
+		return ""
+	}
+
+	return bestLanguage
+}
+
+// languageIssues compares the lang attribute of the page with the detected language.
+func languageIssues(declaredLanguage, detectedLanguage string) []string {
+	if declaredLanguage == "" {
+		if detectedLanguage != "" {
+			return []string{fmt.Sprintf("<html> has no lang attribute, the content looks like %q", detectedLanguage)}
+		}
+		return []string{"<html> has no lang attribute"}
+	}
+
+	tag, err := language.Parse(declaredLanguage)
+	if err != nil {
+		return []string{fmt.Sprintf("lang attribute %q is not a valid language tag", declaredLanguage)}
+	}
+
+	if base, _ := tag.Base(); detectedLanguage != "" && base.String() != detectedLanguage {
+		return []string{fmt.Sprintf("lang attribute %q does not match the detected language %q", declaredLanguage, detectedLanguage)}
+	}
+
+	return nil
+}
+
+// isKeyword reports whether the word can be a keyword: at least three letters and not a stop word or a number.
+func isKeyword(word string, stopWords map[string]bool) bool {
+	if stopWords[word] || len([]rune(word)) < 3 {
+		return false
+	}
+
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func topKeywords(sentences [][]string, stopWords map[string]bool, wordCount int) []KeywordCount {
+	counts := make(map[string]int)
+	for _, sentence := range sentences {
+		for _, word := range sentence {
+			if isKeyword(word, stopWords) {
+				counts[word]++
+			}
+		}
+	}
+
+	return topCounts(counts, 1, wordCount)
+}
+
+// topPhrases counts the two and three word phrases made of keywords that occur at least twice.
+func topPhrases(sentences [][]string, stopWords map[string]bool, wordCount int) []KeywordCount {
+	counts := make(map[string]int)
+	for _, sentence := range sentences {
+		for size := 2; size <= 3; size++ {
+			for start := 0; start+size <= len(sentence); start++ {
+				phrase := sentence[start : start+size]
+				if slices.ContainsFunc(phrase, func(word string) bool { return !isKeyword(word, stopWords) }) {
+					continue
+				}
+				counts[strings.Join(phrase, " ")]++
+			}
+		}
+	}
+
+	return topCounts(counts, 2, wordCount)
+}
+
+// topCounts returns the most frequent terms occurring at least minCount times, by count and then alphabetically.
+func topCounts(counts map[string]int, minCount, wordCount int) []KeywordCount {
+	var keywords []KeywordCount
+	for term, count := range counts {
+		if count >= minCount {
+			keywords = append(keywords, KeywordCount{Term: term, Count: count, Density: float64(count) / float64(wordCount) * 100})
+		}
+	}
+
+	slices.SortFunc(keywords, func(a, b KeywordCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Term, b.Term)
+	})
+
+	if len(keywords) > maxKeywords {
+		keywords = keywords[:maxKeywords]
+	}
+
+	return keywords
+}
@@ -0,0 +1,72 @@
+package pageanalyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentMetrics(t *testing.T) {
+	t.Run("English text", func(t *testing.T) {
+		textBlocks := []string{
+			"Coffee brewing guide",
+			"The coffee beans are roasted before brewing. Fresh coffee beans make the best coffee!",
+			"Grind the coffee beans just before you brew them. Is it worth it? Yes, it is.",
+		}
+
+		metrics := contentMetrics(textBlocks, "en-US", 1000)
+
+		assert.Equal(t, 33, metrics.WordCount)
+		assert.Equal(t, 6, metrics.SentenceCount)
+		assert.Equal(t, "en", metrics.DetectedLanguage)
+		assert.Empty(t, metrics.Issues)
+		assert.Equal(t, "Flesch reading ease", metrics.ReadabilityFormula)
+		assert.InDelta(t, 91.0, metrics.ReadingEase, 0.1)
+		assert.InDelta(t, 18.2, metrics.TextToHTMLRatio, 0.1)
+
+		require.NotEmpty(t, metrics.Keywords)
+		assert.Equal(t, KeywordCount{Term: "coffee", Count: 5, Density: 5.0 / 33 * 100}, metrics.Keywords[0])
+		assert.Equal(t, KeywordCount{Term: "beans", Count: 3, Density: 3.0 / 33 * 100}, metrics.Keywords[1])
+		assert.Equal(t, KeywordCount{Term: "coffee beans", Count: 3, Density: 3.0 / 33 * 100}, metrics.Phrases[0])
+	})
+
+	t.Run("Language mismatch", func(t *testing.T) {
+		textBlocks := []string{"Der Kaffee ist frisch und die Bohnen sind aus dem Hochland, aber nicht für dich."}
+
+		metrics := contentMetrics(textBlocks, "en", 100)
+
+		assert.Equal(t, "de", metrics.DetectedLanguage)
+		assert.Equal(t, "Flesch reading ease (Amstad)", metrics.ReadabilityFormula)
+		assert.Equal(t, []string{`lang attribute "en" does not match the detected language "de"`}, metrics.Issues)
+	})
+
+	t.Run("Missing and invalid lang", func(t *testing.T) {
+		assert.Equal(t, []string{"<html> has no lang attribute"}, contentMetrics(nil, "", 100).Issues)
+		assert.Equal(t, []string{`lang attribute "not a tag" is not a valid language tag`}, contentMetrics(nil, "not a tag", 100).Issues)
+	})
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := []struct {
+		word     string
+		lang     string
+		expected int
+	}{
+		{word: "coffee", lang: "en", expected: 2},
+		{word: "make", lang: "en", expected: 1},
+		{word: "free", lang: "en", expected: 1},
+		{word: "table", lang: "en", expected: 2},
+		{word: "rhythm", lang: "en", expected: 1},
+		{word: "brewing", lang: "en", expected: 2},
+		{word: "hochland", lang: "de", expected: 2},
+		{word: "café", lang: "fr", expected: 2},
+		{word: "123", lang: "en", expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			assert.Equal(t, tt.expected, countSyllables(tt.word, tt.lang))
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package pageanalyzer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+const (
+	// crawlConcurrency caps the number of pages downloaded at the same time while crawling.
+	crawlConcurrency = 5
+	// maxCrawledPageBytes caps how much of a crawled page is read.
+	maxCrawledPageBytes = 5 << 20
+)
+
+// crawledPage is an internal page reached by following links from the analyzed page.
+type crawledPage struct {
+	URL        string
+	StatusCode int
+	// FinalURL is the URL of the page after following redirects, the same as URL if there were none.
+	FinalURL string
+	// Redirects are the hops followed before reaching FinalURL, in order.
+	Redirects []Redirect
+	// NoIndex is set if the page asks search engines not to index it, with a robots meta tag or X-Robots-Tag.
+	NoIndex bool
+	// BodyHash is the hex SHA-256 of the page content, empty if the page could not be downloaded.
+	BodyHash string
+	// Links are the navigational links of the page, resolved and normalized.
+	Links []string
+	// extractor is nil if the page could not be downloaded or is not HTML.
+	extractor *htmlextract.HTMLExtractor
+}
+
+// Redirect is a URL that responded with a redirect.
+type Redirect struct {
+	URL        string
+	StatusCode int
+}
+
+// fetchedHTML is the response to a page request.
+type fetchedHTML struct {
+	content    []byte
+	statusCode int
+	finalURL   string
+	redirects  []Redirect
+	header     http.Header
+}
+
+// crawl downloads the pages reachable from the given links of the analyzed page that are internal to the scope, level
+// by level, until MaxCrawlPages pages are downloaded. The result is keyed by the normalized page URL.
+func (w *WebpageAnalyzer) crawl(ctx context.Context, analyzedPage *crawledPage, links []string, scope Scope) map[string]*crawledPage {
+	pages := make(map[string]*crawledPage)
+	// The analyzed page is already downloaded, under the requested URL and the URL it redirects to
+	seen := map[string]bool{analyzedPage.URL: true, analyzedPage.FinalURL: true}
+	maxSeen := w.cfg.MaxCrawlPages + len(seen)
+
+	nextLevel := func(links []string) []string {
+		var level []string
+		for _, link := range links {
+			link = normalizeURL(link)
+			if seen[link] || !scope.IsInternal(link, analyzedPage.URL) || len(seen) >= maxSeen {
+				continue
+			}
+			seen[link] = true
+			level = append(level, link)
+		}
+		return level
+	}
+
+	for level := nextLevel(links); len(level) > 0 && ctx.Err() == nil; {
+		var (
+			lock      sync.Mutex
+			wg        sync.WaitGroup
+			semaphore = make(chan struct{}, crawlConcurrency)
+			found     []string
+		)
+
+		for _, link := range level {
+			wg.Add(1)
+			semaphore <- struct{}{}
+
+			go func(link string) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+
+				page := w.crawlPage(ctx, link)
+
+				lock.Lock()
+				pages[link] = page
+				found = append(found, page.Links...)
+				lock.Unlock()
+			}(link)
+		}
+
+		wg.Wait()
+
+		level = nextLevel(found)
+	}
+
+	return pages
+}
+
+func (w *WebpageAnalyzer) crawlPage(ctx context.Context, link string) *crawledPage {
+	page := &crawledPage{URL: link, FinalURL: link}
+
+	fetched, err := w.fetchHTML(ctx, link)
+	if fetched != nil {
+		page.StatusCode = fetched.statusCode
+		page.FinalURL = normalizeURL(fetched.finalURL)
+		page.Redirects = fetched.redirects
+		page.NoIndex = hasNoIndex(fetched.header.Values("X-Robots-Tag"))
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("url", link).Debug("crawl page failed")
+		return page
+	}
+	page.BodyHash = bodyHash(fetched.content)
+
+	extractor, err := htmlextract.New(fetched.content)
+	if err != nil {
+		logrus.WithError(err).WithField("url", link).Debug("initialize html extractor failed")
+		return page
+	}
+	page.extractor = extractor
+	page.NoIndex = page.NoIndex || pageNoIndex(extractor.MetaTags())
+	page.Links = navigationalLinks(classifyLinks(extractor.LinkElements(), page.FinalURL))
+
+	return page
+}
+
+// crawlPages downloads the pages concurrently and returns them in the order of the links.
+func (w *WebpageAnalyzer) crawlPages(ctx context.Context, links []string) []*crawledPage {
+	var (
+		pages     = make([]*crawledPage, len(links))
+		wg        sync.WaitGroup
+		semaphore = make(chan struct{}, crawlConcurrency)
+	)
+
+	for i, link := range links {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(i int, link string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			pages[i] = w.crawlPage(ctx, link)
+		}(i, link)
+	}
+	wg.Wait()
+
+	return pages
+}
+
+// pageIndex maps the pages by URL. Both the requested and the final URL of a redirected page identify it, the
+// requested URL wins if a page redirects to another page of the list.
+func pageIndex(pages []*crawledPage) map[string]*crawledPage {
+	pageByURL := make(map[string]*crawledPage)
+	for _, page := range pages {
+		pageByURL[page.FinalURL] = page
+	}
+	for _, page := range pages {
+		pageByURL[page.URL] = page
+	}
+
+	return pageByURL
+}
+
+// fetchMissing downloads the URLs that are not in the page index and adds them to it by requested URL.
+func (w *WebpageAnalyzer) fetchMissing(ctx context.Context, pageByURL map[string]*crawledPage, urls []string) {
+	var missing []string
+	for _, link := range urls {
+		if pageByURL[link] == nil && !slices.Contains(missing, link) {
+			missing = append(missing, link)
+		}
+	}
+	slices.Sort(missing)
+
+	for _, page := range w.crawlPages(ctx, missing) {
+		pageByURL[page.URL] = page
+	}
+}
+
+// fetchHTML downloads the page and returns its content if it is an HTML document. The status code, redirects and
+// header are returned with the error if the response was received.
+func (w *WebpageAnalyzer) fetchHTML(ctx context.Context, link string) (*fetchedHTML, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil /* body */)
+	if err != nil {
+		return nil, fmt.Errorf("new request with context failed: %v", err)
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	fetched := &fetchedHTML{
+		statusCode: resp.StatusCode,
+		finalURL:   resp.Request.URL.String(),
+		redirects:  redirectChain(resp),
+		header:     resp.Header,
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fetched, fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "html") {
+		return fetched, fmt.Errorf("unsupported content type %q", contentType)
+	}
+
+	fetched.content, err = io.ReadAll(io.LimitReader(resp.Body, maxCrawledPageBytes))
+	if err != nil {
+		return fetched, fmt.Errorf("read all data failed: %v", err)
+	}
+
+	return fetched, nil
+}
+
+// redirectChain returns the redirects the client followed to get the response, in order.
+func redirectChain(resp *http.Response) []Redirect {
+	var redirects []Redirect
+
+	// Every request made for a redirect keeps the response that caused it
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		redirects = append([]Redirect{{URL: req.Response.Request.URL.String(), StatusCode: req.Response.StatusCode}}, redirects...)
+	}
+
+	return redirects
+}
+
+// navigationalLinks returns the normalized URLs of the navigational links.
+func navigationalLinks(links []classifiedLink) []string {
+	var urls []string
+
+	for _, link := range links {
+		if link.Kind == LinkKindNavigational {
+			urls = append(urls, normalizeURL(link.URL))
+		}
+	}
+
+	return urls
+}
+
+// pageNoIndex reports whether the robots meta tags of the page ask search engines not to index it.
+func pageNoIndex(metaTags map[string][]string) bool {
+	return hasNoIndex(metaTags["robots"]) || hasNoIndex(metaTags["googlebot"])
+}
+
+// hasNoIndex reports whether the robots directives, e.g. of X-Robots-Tag headers, contain noindex or none.
+func hasNoIndex(directives []string) bool {
+	for _, value := range directives {
+		for _, directive := range strings.Split(value, ",") {
+			// X-Robots-Tag may prefix the directives with a user agent, e.g. "googlebot: noindex"
+			if _, after, found := strings.Cut(directive, ":"); found {
+				directive = after
+			}
+			if directive = strings.ToLower(strings.TrimSpace(directive)); directive == "noindex" || directive == "none" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// sortedPages returns a copy of the pages sorted by URL.
+func sortedPages(pages []*crawledPage) []*crawledPage {
+	sorted := append([]*crawledPage{}, pages...)
+	slices.SortFunc(sorted, func(a, b *crawledPage) int {
+		return strings.Compare(a.URL, b.URL)
+	})
+
+	return sorted
+}
+
+// normalizeURL returns the link without fragment, with a lowercase host and the root path if the path is empty, so
+// the URLs of a page compare equal however they are written.
+func normalizeURL(link string) string {
+	parsedLink, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+
+	parsedLink.Fragment, parsedLink.RawFragment = "", ""
+	parsedLink.Host = strings.ToLower(parsedLink.Host)
+	if parsedLink.Host != "" && parsedLink.Path == "" {
+		parsedLink.Path, parsedLink.RawPath = "/", ""
+	}
+
+	return parsedLink.String()
+}
+
+func stripFragment(link string) string {
+	parsedLink, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+
+	parsedLink.Fragment, parsedLink.RawFragment = "", ""
+
+	return parsedLink.String()
+}
@@ -0,0 +1,85 @@
+package pageanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrawl(t *testing.T) {
+	pages := map[string]string{
+		"/a": `<html><body><a href="/b">B</a><a href="/a#self">A</a></body></html>`,
+		"/b": `<html><body><a href="/c">C</a><a href="https://other.com/">Other</a></body></html>`,
+		"/c": `<html><body><a href="/d">D</a></body></html>`,
+		"/d": `<html><body></body></html>`,
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/file.txt" {
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprint(w, "plain text")
+			return
+		}
+
+		content, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, content)
+	}))
+	defer testServer.Close()
+
+	// The analyzed page is requested without the root path
+	analyzedPage := &crawledPage{URL: normalizeURL(testServer.URL), FinalURL: normalizeURL(testServer.URL)}
+
+	t.Run("Follows internal links", func(t *testing.T) {
+		analyzer := New(&Config{MaxCrawlPages: 10}, testServer.Client())
+
+		crawledPages := analyzer.crawl(context.Background(), analyzedPage, []string{
+			testServer.URL + "/",
+			testServer.URL + "/a#top",
+			testServer.URL + "/missing",
+			testServer.URL + "/file.txt",
+			"https://other.com/",
+		}, Scope{Mode: ScopeHostWithWWW})
+
+		assert.Len(t, crawledPages, 6)
+		assert.NotNil(t, crawledPages[testServer.URL+"/d"].extractor)
+		assert.Equal(t, http.StatusNotFound, crawledPages[testServer.URL+"/missing"].StatusCode)
+		assert.Nil(t, crawledPages[testServer.URL+"/missing"].extractor)
+		assert.Nil(t, crawledPages[testServer.URL+"/file.txt"].extractor)
+		assert.NotContains(t, crawledPages, "https://other.com/")
+		assert.NotContains(t, crawledPages, testServer.URL+"/")
+	})
+
+	t.Run("Stops at max pages", func(t *testing.T) {
+		analyzer := New(&Config{MaxCrawlPages: 2}, testServer.Client())
+
+		crawledPages := analyzer.crawl(context.Background(), analyzedPage, []string{testServer.URL + "/a"}, Scope{Mode: ScopeHostWithWWW})
+
+		assert.Len(t, crawledPages, 2)
+		assert.Contains(t, crawledPages, testServer.URL+"/a")
+		assert.Contains(t, crawledPages, testServer.URL+"/b")
+	})
+}
+
+func TestNormalizeURL(t *testing.T) {
+	assert.Equal(t, "https://example.com/", normalizeURL("https://Example.COM"))
+	assert.Equal(t, "https://example.com/?page=2", normalizeURL("https://example.com?page=2#top"))
+	assert.Equal(t, "https://example.com/Blog/", normalizeURL("HTTPS://example.com/Blog/#comments"))
+	assert.Equal(t, "mailto:info@example.com", normalizeURL("mailto:info@example.com"))
+}
+
+func TestHasNoIndex(t *testing.T) {
+	assert.True(t, hasNoIndex([]string{"index, follow", "NOINDEX"}))
+	assert.True(t, hasNoIndex([]string{"googlebot: none"}))
+	assert.False(t, hasNoIndex([]string{"nofollow", "unavailable_after: 25 Jun 2030 15:00:00 PST"}))
+	assert.True(t, pageNoIndex(map[string][]string{"googlebot": {"noindex"}}))
+	assert.False(t, pageNoIndex(map[string][]string{"description": {"noindex"}}))
+}
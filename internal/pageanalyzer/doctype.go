@@ -0,0 +1,247 @@
+package pageanalyzer
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/html"
+)
+
+// Document modes browsers render a page in, decided by its doctype.
+const (
+	ModeNoQuirks      = "no-quirks"
+	ModeLimitedQuirks = "limited-quirks"
+	ModeQuirks        = "quirks"
+)
+
+// quirksPublicIDPrefixes are the doctype public identifiers that put browsers in quirks mode,
+// from the HTML standard (https://html.spec.whatwg.org/multipage/parsing.html#the-initial-insertion-mode).
+var quirksPublicIDPrefixes = []string{
+	"+//silmaril//dtd html pro v0r11 19970101//",
+	"-//as//dtd html 3.0 aswedit + extensions//",
+	"-//advasoft ltd//dtd html 3.0 aswedit + extensions//",
+	"-//ietf//dtd html 2.0 level 1//",
+	"-//ietf//dtd html 2.0 level 2//",
+	"-//ietf//dtd html 2.0 strict level 1//",
+	"-//ietf//dtd html 2.0 strict level 2//",
+	"-//ietf//dtd html 2.0 strict//",
+	"-//ietf//dtd html 2.0//",
+	"-//ietf//dtd html 2.1e//",
+	"-//ietf//dtd html 3.0//",
+	"-//ietf//dtd html 3.2 final//",
+	"-//ietf//dtd html 3.2//",
+	"-//ietf//dtd html 3//",
+	"-//ietf//dtd html level 0//",
+	"-//ietf//dtd html level 1//",
+	"-//ietf//dtd html level 2//",
+	"-//ietf//dtd html level 3//",
+	"-//ietf//dtd html strict level 0//",
+	"-//ietf//dtd html strict level 1//",
+	"-//ietf//dtd html strict level 2//",
+	"-//ietf//dtd html strict level 3//",
+	"-//ietf//dtd html strict//",
+	"-//ietf//dtd html//",
+	"-//metrius//dtd metrius presentational//",
+	"-//microsoft//dtd internet explorer 2.0 html strict//",
+	"-//microsoft//dtd internet explorer 2.0 html//",
+	"-//microsoft//dtd internet explorer 2.0 tables//",
+	"-//microsoft//dtd internet explorer 3.0 html strict//",
+	"-//microsoft//dtd internet explorer 3.0 html//",
+	"-//microsoft//dtd internet explorer 3.0 tables//",
+	"-//netscape comm. corp.//dtd html//",
+	"-//netscape comm. corp.//dtd strict html//",
+	"-//o'reilly and associates//dtd html 2.0//",
+	"-//o'reilly and associates//dtd html extended 1.0//",
+	"-//o'reilly and associates//dtd html extended relaxed 1.0//",
+	"-//sq//dtd html 2.0 hotmetal + extensions//",
+	"-//softquad software//dtd hotmetal pro 6.0::19990601::extensions to html 4.0//",
+	"-//softquad//dtd hotmetal pro 4.0::19971010::extensions to html 4.0//",
+	"-//spyglass//dtd html 2.0 extended//",
+	"-//sun microsystems corp.//dtd hotjava html//",
+	"-//sun microsystems corp.//dtd hotjava strict html//",
+	"-//w3c//dtd html 3 1995-03-24//",
+	"-//w3c//dtd html 3.2 draft//",
+	"-//w3c//dtd html 3.2 final//",
+	"-//w3c//dtd html 3.2//",
+	"-//w3c//dtd html 3.2s draft//",
+	"-//w3c//dtd html 4.0 frameset//",
+	"-//w3c//dtd html 4.0 transitional//",
+	"-//w3c//dtd html experimental 19960712//",
+	"-//w3c//dtd html experimental 970421//",
+	"-//w3c//dtd w3 html//",
+	"-//w3o//dtd w3 html 3.0//",
+	"-//webtechs//dtd mozilla html 2.0//",
+	"-//webtechs//dtd mozilla html//",
+}
+
+// quirksPublicIDs are the doctype public identifiers that put browsers in quirks mode when matched exactly.
+var quirksPublicIDs = []string{"-//w3o//dtd w3 html strict 3.0//en//", "-/w3c/dtd html 4.0 transitional/en", "html"}
+
+// html401TransitionalPrefixes select quirks mode without a system identifier and limited-quirks mode with one.
+var html401TransitionalPrefixes = []string{"-//w3c//dtd html 4.01 frameset//", "-//w3c//dtd html 4.01 transitional//"}
+
+// limitedQuirksPublicIDPrefixes always select limited-quirks mode.
+var limitedQuirksPublicIDPrefixes = []string{"-//w3c//dtd xhtml 1.0 frameset//", "-//w3c//dtd xhtml 1.0 transitional//"}
+
+// w3cPublicIDRegex matches the public identifiers of the W3C HTML and XHTML DTDs, e.g. "-//W3C//DTD HTML 4.01 Transitional//EN".
+var w3cPublicIDRegex = regexp.MustCompile(`(?i)^-//W3C//DTD (X?HTML)( Basic)? ([\d.]+)(?: (Strict|Transitional|Frameset|Final))?//`)
+
+// Doctype is the document type declaration of the page.
+type Doctype struct {
+	// Found is false if the page has no doctype before its first element.
+	Found    bool
+	Name     string
+	PublicID string
+	SystemID string
+	// Version is the HTML version the doctype declares, e.g. "HTML5", "HTML 4.01" or "XHTML 1.0".
+	Version string
+	// Variant is the DTD flavor, e.g. "Strict", "Transitional" or "Frameset".
+	Variant string
+	// Mode is the document mode browsers render the page in.
+	Mode string
+	// HasHTMLElement tells whether the page has an <html> element.
+	HasHTMLElement bool
+}
+
+// IsXHTML reports whether the doctype declares an XHTML version.
+func (d *Doctype) IsXHTML() bool {
+	return strings.HasPrefix(d.Version, "XHTML")
+}
+
+// parseDoctype finds the doctype with the HTML tokenizer. Like browsers, it only accepts a doctype that is
+// preceded by nothing but a byte order mark, comments and whitespace.
+func parseDoctype(pageContent []byte) *Doctype {
+	doctype := &Doctype{Mode: ModeQuirks}
+	beforeContent := true
+
+	// The tokenizer reads a UTF-8 byte order mark as text, browsers skip it
+	pageContent = bytes.TrimPrefix(pageContent, []byte("\uFEFF"))
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(pageContent))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return doctype
+		case html.DoctypeToken:
+			if beforeContent && !doctype.Found {
+				doctype.Found = true
+				parseDoctypeDeclaration(doctype, string(tokenizer.Text()))
+			}
+		case html.TextToken:
+			if len(bytes.TrimSpace(tokenizer.Text())) > 0 {
+				beforeContent = false
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			beforeContent = false
+			if name, _ := tokenizer.TagName(); string(name) == "html" {
+				doctype.HasHTMLElement = true
+				return doctype
+			}
+		case html.EndTagToken:
+			beforeContent = false
+		}
+	}
+}
+
+// parseDoctypeDeclaration parses the declaration, e.g. `html PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://..."`.
+func parseDoctypeDeclaration(doctype *Doctype, declaration string) {
+	name, rest := cutSpace(declaration)
+	doctype.Name = strings.ToLower(name)
+
+	keyword, rest := cutSpace(rest)
+	var hasPublicID, hasSystemID bool
+	switch strings.ToUpper(keyword) {
+	case "PUBLIC":
+		doctype.PublicID, rest, hasPublicID = quotedString(rest)
+		doctype.SystemID, _, hasSystemID = quotedString(rest)
+	case "SYSTEM":
+		doctype.SystemID, _, hasSystemID = quotedString(rest)
+	}
+
+	doctype.Mode = doctypeMode(doctype, hasSystemID)
+
+	if doctype.Name != "html" {
+		doctype.Version = "Unknown"
+		return
+	}
+
+	if !hasPublicID && (!hasSystemID || strings.EqualFold(doctype.SystemID, "about:legacy-compat")) {
+		doctype.Version = "HTML5"
+		return
+	}
+
+	submatches := w3cPublicIDRegex.FindStringSubmatch(doctype.PublicID)
+	if submatches == nil {
+		doctype.Version = "Unknown"
+		return
+	}
+
+	family, basic, number, variant := strings.ToUpper(submatches[1]), submatches[2], submatches[3], submatches[4]
+	doctype.Version = family + basic + " " + number
+
+	switch {
+	case variant != "":
+		doctype.Variant = strings.ToUpper(variant[:1]) + strings.ToLower(variant[1:])
+	case family == "HTML" && strings.HasPrefix(number, "4"):
+		// The HTML 4 DTD without a variant is the strict one
+		doctype.Variant = "Strict"
+	}
+}
+
+// cutSpace returns the text before the first whitespace and the trimmed text after it.
+func cutSpace(s string) (string, string) {
+	s = strings.TrimSpace(s)
+
+	end := strings.IndexFunc(s, unicode.IsSpace)
+	if end == -1 {
+		return s, ""
+	}
+
+	return s[:end], strings.TrimSpace(s[end:])
+}
+
+// quotedString returns the leading single or double quoted string and the rest of the input.
+func quotedString(s string) (string, string, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return "", s, false
+	}
+
+	end := strings.IndexByte(s[1:], s[0])
+	if end == -1 {
+		return s[1:], "", true
+	}
+
+	return s[1 : end+1], s[end+2:], true
+}
+
+// doctypeMode decides the document mode as described in the HTML standard.
+func doctypeMode(doctype *Doctype, hasSystemID bool) string {
+	publicID := strings.ToLower(doctype.PublicID)
+	systemID := strings.ToLower(doctype.SystemID)
+
+	hasPrefix := func(prefixes []string) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(publicID, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case doctype.Name != "html",
+		hasPrefix(quirksPublicIDPrefixes),
+		slices.Contains(quirksPublicIDs, publicID),
+		systemID == "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd",
+		!hasSystemID && hasPrefix(html401TransitionalPrefixes):
+		return ModeQuirks
+	case hasPrefix(limitedQuirksPublicIDPrefixes), hasSystemID && hasPrefix(html401TransitionalPrefixes):
+		return ModeLimitedQuirks
+	default:
+		return ModeNoQuirks
+	}
+}
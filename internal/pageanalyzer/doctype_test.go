@@ -0,0 +1,74 @@
+package pageanalyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDoctype(t *testing.T) {
+	tests := []struct {
+		name        string
+		htmlContent string
+		expected    *Doctype
+	}{
+		{
+			name:        "HTML5",
+			htmlContent: "<!DOCTYPE html><html></html>",
+			expected:    &Doctype{Found: true, Name: "html", Version: "HTML5", Mode: ModeNoQuirks, HasHTMLElement: true},
+		},
+		{
+			name:        "Legacy compat",
+			htmlContent: `<!DOCTYPE html SYSTEM "about:legacy-compat"><html></html>`,
+			expected:    &Doctype{Found: true, Name: "html", SystemID: "about:legacy-compat", Version: "HTML5", Mode: ModeNoQuirks, HasHTMLElement: true},
+		},
+		{
+			name:        "HTML 4.01 Transitional with system identifier",
+			htmlContent: "<!DOCTYPE HTML PUBLIC \"-//W3C//DTD HTML 4.01 Transitional//EN\"\n\t\"http://www.w3.org/TR/html4/loose.dtd\">",
+			expected: &Doctype{
+				Found: true, Name: "html", PublicID: "-//W3C//DTD HTML 4.01 Transitional//EN", SystemID: "http://www.w3.org/TR/html4/loose.dtd",
+				Version: "HTML 4.01", Variant: "Transitional", Mode: ModeLimitedQuirks,
+			},
+		},
+		{
+			name:        "HTML 4.01 Frameset without system identifier",
+			htmlContent: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Frameset//EN">`,
+			expected: &Doctype{
+				Found: true, Name: "html", PublicID: "-//W3C//DTD HTML 4.01 Frameset//EN",
+				Version: "HTML 4.01", Variant: "Frameset", Mode: ModeQuirks,
+			},
+		},
+		{
+			name:        "XHTML 1.0 Strict",
+			htmlContent: `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">`,
+			expected: &Doctype{
+				Found: true, Name: "html", PublicID: "-//W3C//DTD XHTML 1.0 Strict//EN", SystemID: "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd",
+				Version: "XHTML 1.0", Variant: "Strict", Mode: ModeNoQuirks,
+			},
+		},
+		{
+			name:        "HTML 3.2",
+			htmlContent: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN"><HTML></HTML>`,
+			expected: &Doctype{
+				Found: true, Name: "html", PublicID: "-//W3C//DTD HTML 3.2 Final//EN",
+				Version: "HTML 3.2", Variant: "Final", Mode: ModeQuirks, HasHTMLElement: true,
+			},
+		},
+		{
+			name:        "Doctype after content is ignored",
+			htmlContent: "<p>text</p><!DOCTYPE html>",
+			expected:    &Doctype{Mode: ModeQuirks},
+		},
+		{
+			name:        "No doctype",
+			htmlContent: "<html><body></body></html>",
+			expected:    &Doctype{Mode: ModeQuirks, HasHTMLElement: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseDoctype([]byte(tt.htmlContent)))
+		})
+	}
+}
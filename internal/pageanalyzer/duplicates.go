@@ -0,0 +1,311 @@
+package pageanalyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"math/bits"
+	"net/url"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// simHashShingleSize is the number of consecutive words hashed together into the SimHash of a page.
+	simHashShingleSize = 3
+	// maxNearDuplicateDistance is the largest number of differing SimHash bits between near-duplicate pages.
+	maxNearDuplicateDistance = 3
+	// minSimHashWords is the content length below which SimHashes are too noisy to compare.
+	minSimHashWords = 50
+	// shortBodyHashLength is the number of hex digits of the body hash shown for exact duplicates.
+	shortBodyHashLength = 12
+)
+
+// DuplicateGroup is a set of pages with the same content, title, meta description or H1.
+type DuplicateGroup struct {
+	// Value is the shared title, meta description or H1, the short body hash for exact duplicates and empty for
+	// near duplicates
+	Value string
+	URLs  []string
+	// Parameterized is set if the URLs differ only in their query string
+	Parameterized bool
+	// MissingCanonical lists the pages of the group without a canonical link
+	MissingCanonical []string
+}
+
+// DuplicateReport groups the analyzed and crawled pages by duplicate content and metadata.
+type DuplicateReport struct {
+	// PagesNum is the number of compared HTML pages
+	PagesNum         int
+	ExactDuplicates  []*DuplicateGroup
+	NearDuplicates   []*DuplicateGroup
+	Titles           []*DuplicateGroup
+	MetaDescriptions []*DuplicateGroup
+	H1s              []*DuplicateGroup
+}
+
+// duplicatePage is the content and metadata of a page compared for duplicates.
+type duplicatePage struct {
+	url         string
+	bodyHash    string
+	canonical   string
+	title       string
+	description string
+	h1s         []string
+	simHash     uint64
+	hasSimHash  bool
+}
+
+// duplicateReport compares the HTML pages by body hash, SimHash of their main content, title, meta description
+// and H1.
+func duplicateReport(pages []*crawledPage) *DuplicateReport {
+	var (
+		duplicatePages []*duplicatePage
+		seen           = make(map[string]bool)
+	)
+	for _, page := range pages {
+		// A redirected page is the page it redirects to, compare it once
+		if page.extractor != nil && !seen[page.FinalURL] {
+			seen[page.FinalURL] = true
+			duplicatePages = append(duplicatePages, newDuplicatePage(page))
+		}
+	}
+
+	slices.SortFunc(duplicatePages, func(a, b *duplicatePage) int {
+		return strings.Compare(a.url, b.url)
+	})
+
+	report := &DuplicateReport{PagesNum: len(duplicatePages)}
+
+	report.ExactDuplicates = groupDuplicates(duplicatePages, func(page *duplicatePage) []string {
+		return []string{page.bodyHash}
+	})
+	for _, group := range report.ExactDuplicates {
+		group.Value = group.Value[:shortBodyHashLength]
+	}
+
+	report.NearDuplicates = nearDuplicates(duplicatePages)
+
+	report.Titles = groupDuplicates(duplicatePages, func(page *duplicatePage) []string {
+		return []string{page.title}
+	})
+	report.MetaDescriptions = groupDuplicates(duplicatePages, func(page *duplicatePage) []string {
+		return []string{page.description}
+	})
+	report.H1s = groupDuplicates(duplicatePages, func(page *duplicatePage) []string {
+		return page.h1s
+	})
+
+	return report
+}
+
+func newDuplicatePage(page *crawledPage) *duplicatePage {
+	duplicate := &duplicatePage{
+		url:       page.FinalURL,
+		bodyHash:  page.BodyHash,
+		canonical: page.extractor.Canonical(),
+		title:     strings.Join(strings.Fields(page.extractor.Title()), " "),
+	}
+
+	if descriptions := page.extractor.MetaTags()["description"]; len(descriptions) > 0 {
+		duplicate.description = strings.Join(strings.Fields(descriptions[0]), " ")
+	}
+
+	for _, heading := range page.extractor.Headings() {
+		if heading.Level == 1 {
+			duplicate.h1s = append(duplicate.h1s, heading.Text)
+		}
+	}
+
+	// Compare the main content, so shared navigation and footers do not make every page similar
+	var text string
+	if content := page.extractor.MainContent(); content != nil {
+		text = content.Text
+	} else {
+		text = strings.Join(page.extractor.TextBlocks(), " ")
+	}
+	if words := splitWords(text); len(words) >= minSimHashWords {
+		duplicate.simHash, duplicate.hasSimHash = simHash(words), true
+	}
+
+	return duplicate
+}
+
+// groupDuplicates groups the pages by the case-insensitive values and returns the groups of more than one page,
+// largest first. Empty values are ignored.
+func groupDuplicates(pages []*duplicatePage, values func(page *duplicatePage) []string) []*DuplicateGroup {
+	var (
+		groups     []*DuplicateGroup
+		groupByKey = make(map[string]*DuplicateGroup)
+		pageByURL  = make(map[string]*duplicatePage)
+	)
+
+	for _, page := range pages {
+		pageByURL[page.url] = page
+
+		seen := make(map[string]bool)
+		for _, value := range values(page) {
+			key := strings.ToLower(value)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			group, ok := groupByKey[key]
+			if !ok {
+				group = &DuplicateGroup{Value: value}
+				groupByKey[key] = group
+				groups = append(groups, group)
+			}
+			group.URLs = append(group.URLs, page.url)
+		}
+	}
+
+	var duplicateGroups []*DuplicateGroup
+	for _, group := range groups {
+		if len(group.URLs) > 1 {
+			fillDuplicateGroup(group, pageByURL)
+			duplicateGroups = append(duplicateGroups, group)
+		}
+	}
+
+	slices.SortStableFunc(duplicateGroups, func(a, b *DuplicateGroup) int {
+		return len(b.URLs) - len(a.URLs)
+	})
+
+	return duplicateGroups
+}
+
+// nearDuplicates clusters the pages whose SimHashes differ in at most maxNearDuplicateDistance bits. Clusters of
+// exact duplicates only are left out, they are already reported.
+func nearDuplicates(pages []*duplicatePage) []*DuplicateGroup {
+	// Union-find over the pairs of similar pages
+	parents := make([]int, len(pages))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+
+	for i := range pages {
+		for j := i + 1; j < len(pages); j++ {
+			if pages[i].hasSimHash && pages[j].hasSimHash &&
+				bits.OnesCount64(pages[i].simHash^pages[j].simHash) <= maxNearDuplicateDistance {
+				parents[find(j)] = find(i)
+			}
+		}
+	}
+
+	var (
+		groups    []*DuplicateGroup
+		clusters  = make(map[int]*DuplicateGroup)
+		hashes    = make(map[*DuplicateGroup]map[string]bool)
+		pageByURL = make(map[string]*duplicatePage)
+	)
+	for i, page := range pages {
+		pageByURL[page.url] = page
+
+		root := find(i)
+		group, ok := clusters[root]
+		if !ok {
+			group = &DuplicateGroup{}
+			clusters[root] = group
+			hashes[group] = make(map[string]bool)
+			groups = append(groups, group)
+		}
+		group.URLs = append(group.URLs, page.url)
+		hashes[group][page.bodyHash] = true
+	}
+
+	var nearGroups []*DuplicateGroup
+	for _, group := range groups {
+		if len(group.URLs) > 1 && len(hashes[group]) > 1 {
+			fillDuplicateGroup(group, pageByURL)
+			nearGroups = append(nearGroups, group)
+		}
+	}
+
+	slices.SortStableFunc(nearGroups, func(a, b *DuplicateGroup) int {
+		return len(b.URLs) - len(a.URLs)
+	})
+
+	return nearGroups
+}
+
+// fillDuplicateGroup flags parameterized URLs and lists the pages of the group without a canonical link.
+func fillDuplicateGroup(group *DuplicateGroup, pageByURL map[string]*duplicatePage) {
+	group.Parameterized = differOnlyInQuery(group.URLs)
+
+	for _, pageURL := range group.URLs {
+		if pageByURL[pageURL].canonical == "" {
+			group.MissingCanonical = append(group.MissingCanonical, pageURL)
+		}
+	}
+}
+
+// differOnlyInQuery reports whether the URLs have a query string and are the same without it.
+func differOnlyInQuery(urls []string) bool {
+	var (
+		base     string
+		hasQuery bool
+	)
+
+	for i, link := range urls {
+		parsedLink, err := url.Parse(link)
+		if err != nil {
+			return false
+		}
+
+		hasQuery = hasQuery || parsedLink.RawQuery != ""
+		parsedLink.RawQuery, parsedLink.ForceQuery = "", false
+
+		if i == 0 {
+			base = parsedLink.String()
+		} else if parsedLink.String() != base {
+			return false
+		}
+	}
+
+	return hasQuery
+}
+
+// simHash returns the 64-bit SimHash of the word shingles, near-duplicate texts have hashes that differ in few bits.
+func simHash(words []string) uint64 {
+	var weights [64]int
+
+	for i := 0; i+simHashShingleSize <= len(words); i++ {
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(strings.Join(words[i:i+simHashShingleSize], " ")))
+		sum := hash.Sum64()
+
+		for bit := range weights {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+
+	return fingerprint
+}
+
+// bodyHash returns the hex SHA-256 of the page content.
+func bodyHash(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,98 @@
+package pageanalyzer
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestDuplicateReport(t *testing.T) {
+	article := func(topic string, changedWord string) string {
+		var sentences []string
+		for i := 0; i < 20; i++ {
+			sentences = append(sentences, fmt.Sprintf("Sentence number %d explains how %s works in practice.", i, topic))
+		}
+		sentences[10] = fmt.Sprintf("Sentence number ten explains how %s works in %s.", topic, changedWord)
+		return strings.Join(sentences, " ")
+	}
+
+	page := func(t *testing.T, pageURL, head, h1, text string) *crawledPage {
+		content := fmt.Sprintf(`<html><head>%s</head><body><h1>%s</h1><article><p>%s</p></article></body></html>`,
+			head, h1, text)
+
+		extractor, err := htmlextract.New([]byte(content))
+		if err != nil {
+			t.Fatalf("failed to create extractor: %v", err)
+		}
+
+		return &crawledPage{URL: pageURL, StatusCode: 200, FinalURL: pageURL, BodyHash: bodyHash([]byte(content)), extractor: extractor}
+	}
+
+	pages := []*crawledPage{
+		page(t, "https://example.com/shoes?color=red", `<title>Shoes</title>`, "Shoes", article("shoes", "practice")),
+		page(t, "https://example.com/shoes?color=blue", `<title>Shoes</title>`, "Shoes", article("shoes", "practice")),
+		page(t, "https://example.com/boots",
+			`<title>Boots</title><meta name="description" content="Our  catalog"><link rel="canonical" href="/boots">`,
+			"Boots", article("boots", "theory")),
+		page(t, "https://example.com/boots-sale",
+			`<title>boots</title><meta name="description" content="Our catalog">`,
+			"Boots", article("boots", "reality")),
+		page(t, "https://example.com/about", `<title>About</title><meta name="description" content="Our catalog">`,
+			"About", "Short page."),
+		{URL: "https://example.com/missing", StatusCode: 404},
+	}
+
+	report := duplicateReport(pages)
+
+	assert.Equal(t, 5, report.PagesNum)
+
+	if assert.Len(t, report.ExactDuplicates, 1) {
+		assert.Len(t, report.ExactDuplicates[0].Value, shortBodyHashLength)
+		assert.Equal(t, []string{"https://example.com/shoes?color=blue", "https://example.com/shoes?color=red"},
+			report.ExactDuplicates[0].URLs)
+		assert.True(t, report.ExactDuplicates[0].Parameterized)
+		assert.Equal(t, report.ExactDuplicates[0].URLs, report.ExactDuplicates[0].MissingCanonical)
+	}
+
+	// The shoe pages are identical, so only the boot pages are reported as near duplicates
+	if assert.Len(t, report.NearDuplicates, 1) {
+		assert.Equal(t, &DuplicateGroup{
+			URLs:             []string{"https://example.com/boots", "https://example.com/boots-sale"},
+			MissingCanonical: []string{"https://example.com/boots-sale"},
+		}, report.NearDuplicates[0])
+	}
+
+	assert.Equal(t, []string{"Boots", "Shoes"}, []string{report.Titles[0].Value, report.Titles[1].Value})
+	assert.Len(t, report.Titles, 2)
+
+	if assert.Len(t, report.MetaDescriptions, 1) {
+		assert.Equal(t, "Our catalog", report.MetaDescriptions[0].Value)
+		assert.Len(t, report.MetaDescriptions[0].URLs, 3)
+		assert.False(t, report.MetaDescriptions[0].Parameterized)
+	}
+
+	assert.Len(t, report.H1s, 2)
+}
+
+func TestSimHash(t *testing.T) {
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog while the cat sleeps ", 10)
+	changed := strings.Replace(text, "cat sleeps", "cat naps", 1)
+	other := strings.Repeat("lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor ", 10)
+
+	hash := simHash(splitWords(text))
+
+	assert.Equal(t, hash, simHash(splitWords(text)))
+	assert.LessOrEqual(t, bits.OnesCount64(hash^simHash(splitWords(changed))), maxNearDuplicateDistance)
+	assert.Greater(t, bits.OnesCount64(hash^simHash(splitWords(other))), maxNearDuplicateDistance)
+}
+
+func TestDifferOnlyInQuery(t *testing.T) {
+	assert.True(t, differOnlyInQuery([]string{"https://example.com/a?page=1", "https://example.com/a"}))
+	assert.False(t, differOnlyInQuery([]string{"https://example.com/a", "https://example.com/a"}))
+	assert.False(t, differOnlyInQuery([]string{"https://example.com/a?page=1", "https://example.com/b?page=1"}))
+}
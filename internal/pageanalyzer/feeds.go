@@ -0,0 +1,541 @@
+package pageanalyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/html/charset"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+const (
+	// maxFeedBytes caps how much of a feed is read.
+	maxFeedBytes = 5 << 20
+	// maxCheckedFeedItemLinks caps the number of item links checked per feed.
+	maxCheckedFeedItemLinks = 20
+)
+
+// Feed formats
+const (
+	FeedFormatRSS  = "RSS 2.0"
+	FeedFormatAtom = "Atom"
+	FeedFormatJSON = "JSON Feed"
+)
+
+const (
+	// atomNamespace is the XML namespace of Atom 1.0 documents.
+	atomNamespace = "http://www.w3.org/2005/Atom"
+	// jsonFeedVersionPrefix starts the version URL of every JSON Feed.
+	jsonFeedVersionPrefix = "https://jsonfeed.org/version/"
+	// genericJSONType is the MIME type of JSON Feed 1.0, also used by alternate links to other JSON documents.
+	genericJSONType = "application/json"
+)
+
+// feedFormatTypes are the MIME types a feed of each format should be announced with.
+var feedFormatTypes = map[string][]string{
+	FeedFormatRSS:  {"application/rss+xml"},
+	FeedFormatAtom: {"application/atom+xml"},
+	FeedFormatJSON: {"application/feed+json", genericJSONType},
+}
+
+// rssDateLayouts are the RFC 822 date variants found in RSS feeds, with one or two digit days and years.
+var rssDateLayouts = []string{
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"Mon, 2 Jan 06 15:04:05 -0700",
+	"Mon, 2 Jan 06 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+}
+
+// FeedReport is the validation of an RSS, Atom or JSON Feed document announced by the page.
+type FeedReport struct {
+	URL string
+	// Type and Title are declared by the <link> element
+	Type       string
+	Title      string
+	StatusCode int
+	// Format is detected from the content, empty if the document is not a feed
+	Format    string
+	FeedTitle string
+	ItemsNum  int
+	// LatestItem is the most recent publication or update date of the items, zero if no item is dated
+	LatestItem      time.Time
+	BrokenItemLinks []string
+	Issues          []string
+	Error           string
+}
+
+// parsedFeed is the content of a feed, independent of its format.
+type parsedFeed struct {
+	format string
+	title  string
+	items  []feedItem
+	issues []string
+}
+
+// feedItem is an RSS item, Atom entry or JSON Feed item.
+type feedItem struct {
+	link string
+	date time.Time
+}
+
+type rssDocument struct {
+	Version string      `xml:"version,attr"`
+	Channel *rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       *string   `xml:"title"`
+	Link        *string   `xml:"link"`
+	Description *string   `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	PubDate     string `xml:"pubDate"`
+}
+
+type atomFeed struct {
+	ID      string       `xml:"http://www.w3.org/2005/Atom id"`
+	Title   string       `xml:"http://www.w3.org/2005/Atom title"`
+	Updated string       `xml:"http://www.w3.org/2005/Atom updated"`
+	Authors []atomAuthor `xml:"http://www.w3.org/2005/Atom author"`
+	Entries []atomEntry  `xml:"http://www.w3.org/2005/Atom entry"`
+}
+
+type atomEntry struct {
+	ID        string       `xml:"http://www.w3.org/2005/Atom id"`
+	Title     string       `xml:"http://www.w3.org/2005/Atom title"`
+	Updated   string       `xml:"http://www.w3.org/2005/Atom updated"`
+	Published string       `xml:"http://www.w3.org/2005/Atom published"`
+	Authors   []atomAuthor `xml:"http://www.w3.org/2005/Atom author"`
+	Links     []atomLink   `xml:"http://www.w3.org/2005/Atom link"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"http://www.w3.org/2005/Atom name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type jsonFeed struct {
+	Version *string        `json:"version"`
+	Title   *string        `json:"title"`
+	Items   []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            any    `json:"id"`
+	URL           string `json:"url"`
+	ContentHTML   string `json:"content_html"`
+	ContentText   string `json:"content_text"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+}
+
+// feedReports downloads and validates the feeds announced by the page. Links announced as application/json are
+// only reported if they are JSON Feeds, the type is shared with other JSON documents such as the WordPress REST API.
+func (w *WebpageAnalyzer) feedReports(ctx context.Context, feeds []htmlextract.Feed, pageURL string) []*FeedReport {
+	var reports []*FeedReport
+
+	for _, feed := range feeds {
+		report := &FeedReport{URL: feed.URL, Type: feed.Type, Title: feed.Title}
+		if resolvedURL, err := resolveLink(feed.URL, pageURL); err == nil {
+			report.URL = resolvedURL
+		}
+		reports = append(reports, report)
+	}
+
+	var (
+		isFeed = make([]bool, len(reports))
+		wg     sync.WaitGroup
+	)
+	for i, report := range reports {
+		wg.Add(1)
+
+		go func(i int, report *FeedReport) {
+			defer wg.Done()
+
+			isFeed[i] = w.checkFeed(ctx, report)
+		}(i, report)
+	}
+	wg.Wait()
+
+	var feedReports []*FeedReport
+	for i, report := range reports {
+		if isFeed[i] {
+			feedReports = append(feedReports, report)
+		}
+	}
+
+	return feedReports
+}
+
+// checkFeed downloads the feed, parses it and checks the links of its items. It returns false if the link is
+// announced as application/json but is not a JSON Feed.
+func (w *WebpageAnalyzer) checkFeed(ctx context.Context, report *FeedReport) bool {
+	content, statusCode, err := w.fetchFeed(ctx, report.URL)
+	if report.Type == genericJSONType && !isJSONFeed(content) {
+		return false
+	}
+
+	report.StatusCode = statusCode
+	if err != nil {
+		report.Error = err.Error()
+		return true
+	}
+
+	feed, err := parseFeed(content)
+	if err != nil {
+		report.Error = err.Error()
+		return true
+	}
+
+	report.Format = feed.format
+	report.FeedTitle = feed.title
+	report.ItemsNum = len(feed.items)
+	report.Issues = feed.issues
+
+	if !slices.Contains(feedFormatTypes[feed.format], report.Type) {
+		report.Issues = append(report.Issues, fmt.Sprintf("%s feed is announced as %s, use %s",
+			feed.format, report.Type, feedFormatTypes[feed.format][0]))
+	}
+
+	var itemLinks []string
+	for _, item := range feed.items {
+		if item.date.After(report.LatestItem) {
+			report.LatestItem = item.date
+		}
+
+		if item.link == "" || len(itemLinks) == maxCheckedFeedItemLinks {
+			continue
+		}
+		if itemLink, err := resolveLink(item.link, report.URL); err == nil && !slices.Contains(itemLinks, itemLink) {
+			itemLinks = append(itemLinks, itemLink)
+		}
+	}
+
+	inaccessibleLinks := w.inaccessibleLinks(ctx, itemLinks)
+	for _, itemLink := range itemLinks {
+		if inaccessibleLinks[itemLink] {
+			report.BrokenItemLinks = append(report.BrokenItemLinks, itemLink)
+		}
+	}
+
+	return true
+}
+
+// fetchFeed downloads the feed and returns its content and the status code.
+func (w *WebpageAnalyzer) fetchFeed(ctx context.Context, feedURL string) ([]byte, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil /* body */)
+	if err != nil {
+		return nil, 0, fmt.Errorf("new request with context failed: %v", err)
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("send request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes))
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("read response body failed: %v", err)
+	}
+
+	return content, resp.StatusCode, nil
+}
+
+// parseFeed detects the format of the feed from its content and parses it.
+func parseFeed(content []byte) (*parsedFeed, error) {
+	content = bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+
+	if bytes.HasPrefix(content, []byte("{")) {
+		return parseJSONFeed(content)
+	}
+
+	root, err := xmlRootElement(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse feed failed: %v", err)
+	}
+
+	switch {
+	case root.Local == "rss":
+		return parseRSS(content)
+	case root.Local == "feed" && root.Space == atomNamespace:
+		return parseAtom(content)
+	case root.Local == "feed":
+		return nil, fmt.Errorf("feed element without the Atom namespace %s", atomNamespace)
+	case root.Local == "RDF":
+		return nil, fmt.Errorf("RSS 1.0 (RDF) feeds are not supported")
+	default:
+		return nil, fmt.Errorf("unknown feed root element <%s>", root.Local)
+	}
+}
+
+// xmlRootElement returns the name of the root element of the XML document.
+func xmlRootElement(content []byte) (xml.Name, error) {
+	decoder := newFeedDecoder(content)
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+func parseRSS(content []byte) (*parsedFeed, error) {
+	var document rssDocument
+	if err := newFeedDecoder(content).Decode(&document); err != nil {
+		return nil, fmt.Errorf("parse RSS failed: %v", err)
+	}
+
+	feed := &parsedFeed{format: FeedFormatRSS}
+
+	if document.Version != "2.0" {
+		feed.issues = append(feed.issues, fmt.Sprintf("rss version is %q, expected \"2.0\"", document.Version))
+	}
+	if document.Channel == nil {
+		feed.issues = append(feed.issues, "missing required <channel> element")
+		return feed, nil
+	}
+
+	channel := document.Channel
+	for _, element := range []struct {
+		name  string
+		value *string
+	}{
+		{name: "title", value: channel.Title},
+		{name: "link", value: channel.Link},
+		{name: "description", value: channel.Description},
+	} {
+		if element.value == nil {
+			feed.issues = append(feed.issues, fmt.Sprintf("channel is missing the required <%s> element", element.name))
+		}
+	}
+	if channel.Title != nil {
+		feed.title = strings.TrimSpace(*channel.Title)
+	}
+
+	for i, item := range channel.Items {
+		parsedItem := feedItem{link: strings.TrimSpace(item.Link)}
+
+		if strings.TrimSpace(item.Title) == "" && strings.TrimSpace(item.Description) == "" {
+			feed.issues = append(feed.issues, fmt.Sprintf("item %d has neither a title nor a description", i+1))
+		}
+		if parsedItem.link != "" && !isAbsoluteURL(parsedItem.link) {
+			feed.issues = append(feed.issues, fmt.Sprintf("item %d link %q is not an absolute URL", i+1, parsedItem.link))
+		}
+		if pubDate := strings.TrimSpace(item.PubDate); pubDate != "" {
+			if date, ok := parseRSSDate(pubDate); ok {
+				parsedItem.date = date
+			} else {
+				feed.issues = append(feed.issues, fmt.Sprintf("item %d pubDate %q is not an RFC 822 date", i+1, pubDate))
+			}
+		}
+
+		feed.items = append(feed.items, parsedItem)
+	}
+
+	return feed, nil
+}
+
+func parseAtom(content []byte) (*parsedFeed, error) {
+	var document atomFeed
+	if err := newFeedDecoder(content).Decode(&document); err != nil {
+		return nil, fmt.Errorf("parse Atom failed: %v", err)
+	}
+
+	feed := &parsedFeed{format: FeedFormatAtom, title: strings.TrimSpace(document.Title)}
+
+	for _, element := range []struct{ name, value string }{
+		{name: "id", value: document.ID},
+		{name: "title", value: document.Title},
+		{name: "updated", value: document.Updated},
+	} {
+		if strings.TrimSpace(element.value) == "" {
+			feed.issues = append(feed.issues, fmt.Sprintf("feed is missing the required <%s> element", element.name))
+		}
+	}
+	if updated := strings.TrimSpace(document.Updated); updated != "" {
+		if _, err := time.Parse(time.RFC3339, updated); err != nil {
+			feed.issues = append(feed.issues, fmt.Sprintf("feed updated %q is not an RFC 3339 date", updated))
+		}
+	}
+
+	entriesHaveAuthors := true
+	for i, entry := range document.Entries {
+		var parsedItem feedItem
+
+		for _, element := range []struct{ name, value string }{
+			{name: "id", value: entry.ID},
+			{name: "title", value: entry.Title},
+			{name: "updated", value: entry.Updated},
+		} {
+			if strings.TrimSpace(element.value) == "" {
+				feed.issues = append(feed.issues, fmt.Sprintf("entry %d is missing the required <%s> element", i+1, element.name))
+			}
+		}
+
+		for _, element := range []struct{ name, value string }{
+			{name: "updated", value: entry.Updated},
+			{name: "published", value: entry.Published},
+		} {
+			value := strings.TrimSpace(element.value)
+			if value == "" {
+				continue
+			}
+			date, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				feed.issues = append(feed.issues, fmt.Sprintf("entry %d %s %q is not an RFC 3339 date", i+1, element.name, value))
+				continue
+			}
+			if date.After(parsedItem.date) {
+				parsedItem.date = date
+			}
+		}
+
+		// The link without rel is the alternate link
+		for _, link := range entry.Links {
+			if link.Rel == "" || link.Rel == "alternate" {
+				parsedItem.link = strings.TrimSpace(link.Href)
+				break
+			}
+		}
+
+		entriesHaveAuthors = entriesHaveAuthors && len(entry.Authors) > 0
+		feed.items = append(feed.items, parsedItem)
+	}
+
+	if len(document.Authors) == 0 && !entriesHaveAuthors {
+		feed.issues = append(feed.issues, "feed has no <author> and not every entry has one")
+	}
+
+	return feed, nil
+}
+
+func parseJSONFeed(content []byte) (*parsedFeed, error) {
+	var document jsonFeed
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("parse JSON Feed failed: %v", err)
+	}
+
+	feed := &parsedFeed{format: FeedFormatJSON}
+
+	switch {
+	case document.Version == nil:
+		feed.issues = append(feed.issues, `missing required "version"`)
+	case !strings.HasPrefix(*document.Version, jsonFeedVersionPrefix):
+		feed.issues = append(feed.issues, fmt.Sprintf("version %q is not a JSON Feed version URL", *document.Version))
+	}
+	if document.Title == nil {
+		feed.issues = append(feed.issues, `missing required "title"`)
+	} else {
+		feed.title = strings.TrimSpace(*document.Title)
+	}
+	if document.Items == nil {
+		feed.issues = append(feed.issues, `missing required "items"`)
+	}
+
+	for i, item := range document.Items {
+		parsedItem := feedItem{link: strings.TrimSpace(item.URL)}
+
+		if id, ok := item.ID.(string); !ok || id == "" {
+			feed.issues = append(feed.issues, fmt.Sprintf(`item %d is missing the required string "id"`, i+1))
+		}
+		if item.ContentHTML == "" && item.ContentText == "" {
+			feed.issues = append(feed.issues, fmt.Sprintf(`item %d has neither "content_html" nor "content_text"`, i+1))
+		}
+
+		for _, field := range []struct{ name, value string }{
+			{name: "date_published", value: item.DatePublished},
+			{name: "date_modified", value: item.DateModified},
+		} {
+			if field.value == "" {
+				continue
+			}
+			date, err := time.Parse(time.RFC3339, field.value)
+			if err != nil {
+				feed.issues = append(feed.issues, fmt.Sprintf("item %d %s %q is not an RFC 3339 date", i+1, field.name, field.value))
+				continue
+			}
+			if date.After(parsedItem.date) {
+				parsedItem.date = date
+			}
+		}
+
+		feed.items = append(feed.items, parsedItem)
+	}
+
+	return feed, nil
+}
+
+// isJSONFeed reports whether the content is a JSON object with a JSON Feed version.
+func isJSONFeed(content []byte) bool {
+	var document struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), &document); err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(document.Version, jsonFeedVersionPrefix)
+}
+
+// newFeedDecoder returns an XML decoder that accepts the HTML entities and non UTF-8 charsets found in feeds.
+func newFeedDecoder(content []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	return decoder
+}
+
+// parseRSSDate parses an RFC 822 date.
+func parseRSSDate(value string) (time.Time, bool) {
+	for _, layout := range rssDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func isAbsoluteURL(link string) bool {
+	parsedLink, err := url.Parse(link)
+
+	return err == nil && parsedLink.IsAbs() && parsedLink.Host != ""
+}
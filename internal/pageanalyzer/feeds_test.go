@@ -0,0 +1,197 @@
+package pageanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestParseFeed(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		expectedFormat string
+		expectedTitle  string
+		expectedItems  []feedItem
+		expectedIssues []string
+		expectedError  string
+	}{
+		{
+			name: "RSS 2.0",
+			content: "\xef\xbb\xbf" + `<?xml version="1.0" encoding="ISO-8859-1"?>
+				<rss version="2.0"><channel>
+					<title>Caf` + "\xe9" + ` news</title><link>https://example.com/</link><description>News</description>
+					<item><title>First &amp; best</title><link>https://example.com/first</link><pubDate>Mon, 2 Jan 2006 15:04:05 GMT</pubDate></item>
+					<item><title>Second&nbsp;post</title><link>/second</link><pubDate>Tue, 3 Jan 2006 15:04:05 +0000</pubDate></item>
+					<item><link>https://example.com/third</link><pubDate>2006-01-04</pubDate></item>
+				</channel></rss>`,
+			expectedFormat: FeedFormatRSS,
+			expectedTitle:  "Café news",
+			expectedItems: []feedItem{
+				{link: "https://example.com/first", date: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+				{link: "/second", date: time.Date(2006, 1, 3, 15, 4, 5, 0, time.UTC)},
+				{link: "https://example.com/third"},
+			},
+			expectedIssues: []string{
+				`item 2 link "/second" is not an absolute URL`,
+				"item 3 has neither a title nor a description",
+				`item 3 pubDate "2006-01-04" is not an RFC 822 date`,
+			},
+		},
+		{
+			name:           "RSS without channel elements",
+			content:        `<rss version="0.91"><channel><title>Old</title></channel></rss>`,
+			expectedFormat: FeedFormatRSS,
+			expectedTitle:  "Old",
+			expectedIssues: []string{
+				`rss version is "0.91", expected "2.0"`,
+				"channel is missing the required <link> element",
+				"channel is missing the required <description> element",
+			},
+		},
+		{
+			name: "Atom",
+			content: `<feed xmlns="http://www.w3.org/2005/Atom">
+				<id>urn:feed</id><title>Blog</title><updated>2024-05-01T10:00:00Z</updated>
+				<entry>
+					<id>urn:1</id><title>One</title><updated>2024-05-01T10:00:00Z</updated><published>2024-04-01T10:00:00Z</published>
+					<link rel="edit" href="/edit/1"/><link href="/posts/1"/>
+				</entry>
+				<entry><title>Two</title><updated>yesterday</updated></entry>
+			</feed>`,
+			expectedFormat: FeedFormatAtom,
+			expectedTitle:  "Blog",
+			expectedItems: []feedItem{
+				{link: "/posts/1", date: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+				{},
+			},
+			expectedIssues: []string{
+				"entry 2 is missing the required <id> element",
+				`entry 2 updated "yesterday" is not an RFC 3339 date`,
+				"feed has no <author> and not every entry has one",
+			},
+		},
+		{
+			name: "JSON Feed",
+			content: `{"version": "https://jsonfeed.org/version/1.1", "title": "JSON blog", "items": [
+				{"id": "1", "url": "https://example.com/1", "content_text": "One", "date_published": "2024-05-01T10:00:00+02:00"},
+				{"id": 2, "date_modified": "May 1st"}
+			]}`,
+			expectedFormat: FeedFormatJSON,
+			expectedTitle:  "JSON blog",
+			expectedItems: []feedItem{
+				{link: "https://example.com/1", date: time.Date(2024, 5, 1, 10, 0, 0, 0, time.FixedZone("", 2*60*60))},
+				{},
+			},
+			expectedIssues: []string{
+				`item 2 is missing the required string "id"`,
+				`item 2 has neither "content_html" nor "content_text"`,
+				`item 2 date_modified "May 1st" is not an RFC 3339 date`,
+			},
+		},
+		{
+			name:           "JSON Feed without required fields",
+			content:        `{"version": "1"}`,
+			expectedFormat: FeedFormatJSON,
+			expectedIssues: []string{
+				`version "1" is not a JSON Feed version URL`,
+				`missing required "title"`,
+				`missing required "items"`,
+			},
+		},
+		{
+			name:          "Not a feed",
+			content:       `<html><body>Not found</body></html>`,
+			expectedError: "unknown feed root element <html>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			feed, err := parseFeed([]byte(test.content))
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, test.expectedFormat, feed.format)
+			assert.Equal(t, test.expectedTitle, feed.title)
+			assert.Equal(t, len(test.expectedItems), len(feed.items))
+			for i := range test.expectedItems {
+				if i < len(feed.items) {
+					assert.Equal(t, test.expectedItems[i].link, feed.items[i].link)
+					assert.True(t, test.expectedItems[i].date.Equal(feed.items[i].date), "item %d date %v", i+1, feed.items[i].date)
+				}
+			}
+			assert.Equal(t, test.expectedIssues, feed.issues)
+		})
+	}
+}
+
+func TestFeedReports(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "application/atom+xml")
+			fmt.Fprint(w, `<feed xmlns="http://www.w3.org/2005/Atom">
+				<id>urn:feed</id><title>Blog</title><updated>2024-05-02T10:00:00Z</updated><author><name>A</name></author>
+				<entry><id>urn:1</id><title>One</title><updated>2024-05-01T10:00:00Z</updated><link href="/posts/1"/></entry>
+				<entry><id>urn:2</id><title>Two</title><updated>2024-05-02T10:00:00Z</updated><link href="/posts/2"/></entry>
+			</feed>`)
+		case "/posts/1":
+			fmt.Fprint(w, "post")
+		case "/legacy.json":
+			fmt.Fprint(w, `{"version": "https://jsonfeed.org/version/1", "title": "Legacy", "items": []}`)
+		case "/wp-json/":
+			fmt.Fprint(w, `{"name": "Blog", "namespaces": ["wp/v2"]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer testServer.Close()
+
+	analyzer := New(&Config{}, testServer.Client())
+
+	reports := analyzer.feedReports(context.Background(), []htmlextract.Feed{
+		{URL: "/feed.xml", Type: "application/rss+xml", Title: "Posts"},
+		{URL: "/missing.json", Type: "application/feed+json"},
+		{URL: "/legacy.json", Type: "application/json"},
+		// Other JSON documents share the type of JSON Feed 1.0
+		{URL: "/wp-json/", Type: "application/json"},
+		{URL: "/missing-legacy.json", Type: "application/json"},
+	}, testServer.URL+"/blog/")
+
+	if !assert.Len(t, reports, 3) {
+		return
+	}
+
+	assert.Equal(t, &FeedReport{
+		URL:             testServer.URL + "/feed.xml",
+		Type:            "application/rss+xml",
+		Title:           "Posts",
+		StatusCode:      http.StatusOK,
+		Format:          FeedFormatAtom,
+		FeedTitle:       "Blog",
+		ItemsNum:        2,
+		LatestItem:      time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC),
+		BrokenItemLinks: []string{testServer.URL + "/posts/2"},
+		Issues:          []string{"Atom feed is announced as application/rss+xml, use application/atom+xml"},
+	}, reports[0])
+
+	assert.Equal(t, http.StatusNotFound, reports[1].StatusCode)
+	assert.Equal(t, "request failed with status 404", reports[1].Error)
+
+	assert.Equal(t, FeedFormatJSON, reports[2].Format)
+	assert.Equal(t, "Legacy", reports[2].FeedTitle)
+	assert.Empty(t, reports[2].Issues)
+}
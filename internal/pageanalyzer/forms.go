@@ -0,0 +1,99 @@
+package pageanalyzer
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+// FormReport is a form of the page with the security problems found in it.
+type FormReport struct {
+	htmlextract.Form
+	// ResolvedAction is the absolute URL the form submits to.
+	ResolvedAction string
+	Issues         []string
+}
+
+// ConfidencePercent returns the classification confidence as a percentage.
+func (f *FormReport) ConfidencePercent() int {
+	return int(math.Round(f.Confidence * 100))
+}
+
+func formReports(forms []htmlextract.Form, pageURL string) []*FormReport {
+	var reports []*FormReport
+
+	for _, form := range forms {
+		report := &FormReport{Form: form}
+		reports = append(reports, report)
+
+		// A form without an action submits to the page itself
+		resolvedAction, err := resolveLink(form.Action, pageURL)
+		if err != nil {
+			report.Issues = append(report.Issues, fmt.Sprintf("invalid form action %q", form.Action))
+			continue
+		}
+		report.ResolvedAction = resolvedAction
+
+		report.Issues = append(report.Issues, formIssues(report, pageURL)...)
+	}
+
+	return reports
+}
+
+func formIssues(report *FormReport, pageURL string) []string {
+	var (
+		issues      []string
+		hasPassword bool
+	)
+
+	for _, field := range report.Fields {
+		if field.Type == "password" {
+			hasPassword = true
+		}
+
+		// Browsers and password managers rely on autocomplete to fill credentials and payment details
+		if !field.HasAutocomplete && needsAutocomplete(report.Kind, field) {
+			issues = append(issues, fmt.Sprintf("%s field %q has no autocomplete attribute", field.Type, field.Name))
+		}
+	}
+
+	if !hasPassword {
+		return issues
+	}
+
+	action, err := url.Parse(report.ResolvedAction)
+	if err != nil {
+		return issues
+	}
+
+	if action.Scheme == "http" {
+		issues = append(issues, "password form submits over insecure HTTP")
+	}
+
+	if page, err := url.Parse(pageURL); err == nil && (page.Scheme != action.Scheme || page.Host != action.Host) {
+		issues = append(issues, fmt.Sprintf("password form submits to a different origin %s://%s", action.Scheme, action.Host))
+	}
+
+	if report.Method == "GET" {
+		issues = append(issues, "password form uses GET, so the credentials end up in the URL")
+	}
+
+	return issues
+}
+
+func needsAutocomplete(formKind string, field htmlextract.FormField) bool {
+	if field.Type == "password" {
+		return true
+	}
+
+	switch formKind {
+	case htmlextract.FormKindLogin, htmlextract.FormKindSignup, htmlextract.FormKindPayment:
+		return field.Type == "email" || field.Type == "tel" ||
+			(field.Type == "text" && !strings.Contains(strings.ToLower(field.Name), "search"))
+	}
+
+	return false
+}
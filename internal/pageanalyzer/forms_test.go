@@ -0,0 +1,76 @@
+package pageanalyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestFormReports(t *testing.T) {
+	tests := []struct {
+		name           string
+		form           htmlextract.Form
+		pageURL        string
+		expectedAction string
+		expectedIssues []string
+	}{
+		{
+			name: "Secure login",
+			form: htmlextract.Form{
+				Action: "/session",
+				Method: "POST",
+				Kind:   htmlextract.FormKindLogin,
+				Fields: []htmlextract.FormField{
+					{Type: "text", Name: "username", Autocomplete: "username", HasAutocomplete: true},
+					{Type: "password", Name: "password", Autocomplete: "current-password", HasAutocomplete: true},
+				},
+			},
+			pageURL:        "https://example.com/login",
+			expectedAction: "https://example.com/session",
+		},
+		{
+			name: "Insecure login",
+			form: htmlextract.Form{
+				Action: "http://auth.other.com/session",
+				Method: "GET",
+				Kind:   htmlextract.FormKindLogin,
+				Fields: []htmlextract.FormField{
+					{Type: "email", Name: "email"},
+					{Type: "password", Name: "password"},
+				},
+			},
+			pageURL:        "https://example.com/login",
+			expectedAction: "http://auth.other.com/session",
+			expectedIssues: []string{
+				`email field "email" has no autocomplete attribute`,
+				`password field "password" has no autocomplete attribute`,
+				"password form submits over insecure HTTP",
+				"password form submits to a different origin http://auth.other.com",
+				"password form uses GET, so the credentials end up in the URL",
+			},
+		},
+		{
+			name: "Search without action",
+			form: htmlextract.Form{
+				Method: "GET",
+				Kind:   htmlextract.FormKindSearch,
+				Fields: []htmlextract.FormField{
+					{Type: "search", Name: "q"},
+				},
+			},
+			pageURL:        "http://example.com/page?x=1",
+			expectedAction: "http://example.com/page?x=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := formReports([]htmlextract.Form{tt.form}, tt.pageURL)
+			assert.Len(t, reports, 1)
+			assert.Equal(t, tt.expectedAction, reports[0].ResolvedAction)
+			assert.Equal(t, tt.expectedIssues, reports[0].Issues)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package pageanalyzer
+
+import (
+	"net/url"
+	"strings"
+)
+
+// brokenFragmentLinks returns the links whose fragment doesn't match an anchor of the target page.
+// Same-page anchors are checked against the analyzed page, internal links against the crawled pages.
+func brokenFragmentLinks(links []classifiedLink, pageAnchors map[string]bool, crawledPages map[string]*crawledPage) []string {
+	var brokenLinks []string
+
+	// Extracting anchors walks the whole document, so do it once per crawled page
+	pageToAnchors := make(map[string]map[string]bool)
+
+	for _, link := range links {
+		if link.Kind != LinkKindAnchor && link.Kind != LinkKindNavigational {
+			continue
+		}
+
+		parsedLink, err := url.Parse(link.URL)
+		if err != nil || !needsAnchor(parsedLink.Fragment) {
+			continue
+		}
+
+		anchors := pageAnchors
+		if link.Kind == LinkKindNavigational {
+			// The crawled pages are keyed by normalized URL
+			target := normalizeURL(link.URL)
+			page, ok := crawledPages[target]
+			if !ok || page.extractor == nil {
+				continue
+			}
+
+			if _, ok := pageToAnchors[target]; !ok {
+				pageToAnchors[target] = page.extractor.Anchors()
+			}
+			anchors = pageToAnchors[target]
+		}
+
+		if !anchors[parsedLink.Fragment] {
+			brokenLinks = append(brokenLinks, link.URL)
+		}
+	}
+
+	return brokenLinks
+}
+
+// needsAnchor reports whether the fragment must match an anchor. Browsers scroll to the top for an empty fragment
+// or "top", and text fragments (#:~:text=) don't point to anchors.
+func needsAnchor(fragment string) bool {
+	return fragment != "" && !strings.EqualFold(fragment, "top") && !strings.HasPrefix(fragment, ":~:")
+}
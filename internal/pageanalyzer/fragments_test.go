@@ -0,0 +1,42 @@
+package pageanalyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestBrokenFragmentLinks(t *testing.T) {
+	docsExtractor, err := htmlextract.New([]byte(`<html><body><h2 id="install">Install</h2></body></html>`))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	crawledPages := map[string]*crawledPage{
+		"https://example.com/docs": {URL: "https://example.com/docs", StatusCode: 200, extractor: docsExtractor},
+		"https://example.org/":     {URL: "https://example.org/", StatusCode: 200, extractor: docsExtractor},
+	}
+
+	links := []classifiedLink{
+		{Kind: LinkKindAnchor, URL: "https://example.com/#intro"},
+		{Kind: LinkKindAnchor, URL: "https://example.com/#missing"},
+		{Kind: LinkKindAnchor, URL: "https://example.com/#top"},
+		{Kind: LinkKindAnchor, URL: "https://example.com/#:~:text=hello"},
+		{Kind: LinkKindNavigational, URL: "https://example.com/docs#install"},
+		{Kind: LinkKindNavigational, URL: "https://example.com/docs#usage"},
+		{Kind: LinkKindNavigational, URL: "https://example.com/not-crawled#usage"},
+		{Kind: LinkKindNavigational, URL: "https://Example.org#install"},
+		{Kind: LinkKindNavigational, URL: "https://Example.org#missing"},
+		{Kind: LinkKindMailto, URL: "mailto:info@example.com#x"},
+	}
+
+	expected := []string{
+		"https://example.com/#missing",
+		"https://example.com/docs#usage",
+		"https://Example.org#missing",
+	}
+
+	assert.Equal(t, expected, brokenFragmentLinks(links, map[string]bool{"intro": true}, crawledPages))
+}
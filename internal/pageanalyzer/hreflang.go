@@ -0,0 +1,234 @@
+package pageanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// hreflangXDefault is the hreflang value of the fallback page for users whose language is not listed.
+const hreflangXDefault = "x-default"
+
+// HreflangAlternate is a language version of a page, declared with <link rel="alternate" hreflang>.
+type HreflangAlternate struct {
+	Hreflang   string
+	URL        string
+	StatusCode int
+	Issues     []string
+}
+
+// HreflangPage is the hreflang set declared by a page.
+type HreflangPage struct {
+	URL        string
+	Alternates []*HreflangAlternate
+	Issues     []string
+}
+
+// HreflangReport is the validation of the hreflang sets of the analyzed and crawled pages.
+type HreflangReport struct {
+	Pages     []*HreflangPage
+	IssuesNum int
+}
+
+// hreflangReport validates the hreflang sets of the pages. The alternates that were not crawled are downloaded to
+// check their status, canonical and return links. It returns nil if no page declares hreflang alternates.
+func (w *WebpageAnalyzer) hreflangReport(ctx context.Context, pages []*crawledPage) *HreflangReport {
+	pages = sortedPages(pages)
+
+	pageByURL := pageIndex(pages)
+
+	// A redirected page is the page it redirects to, validate it once
+	var (
+		hreflangPages []*crawledPage
+		alternates    = make(map[*crawledPage][]*HreflangAlternate)
+		seen          = make(map[string]bool)
+	)
+	for _, page := range pages {
+		if page.extractor == nil || seen[page.FinalURL] {
+			continue
+		}
+		seen[page.FinalURL] = true
+
+		if pageAlternates := hreflangAlternates(page); len(pageAlternates) > 0 {
+			hreflangPages = append(hreflangPages, page)
+			alternates[page] = pageAlternates
+		}
+	}
+	if len(hreflangPages) == 0 {
+		return nil
+	}
+
+	// Download the alternates that were not crawled
+	var alternateURLs []string
+	for _, page := range hreflangPages {
+		for _, alternate := range alternates[page] {
+			alternateURLs = append(alternateURLs, alternate.URL)
+		}
+	}
+	w.fetchMissing(ctx, pageByURL, alternateURLs)
+
+	report := &HreflangReport{}
+	for _, page := range hreflangPages {
+		hreflangPage := checkHreflangPage(page, alternates[page], pageByURL)
+
+		report.Pages = append(report.Pages, hreflangPage)
+		report.IssuesNum += len(hreflangPage.Issues)
+		for _, alternate := range hreflangPage.Alternates {
+			report.IssuesNum += len(alternate.Issues)
+		}
+	}
+
+	return report
+}
+
+// hreflangAlternates returns the resolved hreflang alternates of the page.
+func hreflangAlternates(page *crawledPage) []*HreflangAlternate {
+	var alternates []*HreflangAlternate
+
+	for _, alternate := range page.extractor.Alternates() {
+		if alternate.Hreflang == "" || alternate.Href == "" {
+			continue
+		}
+
+		alternateURL, err := resolveLink(alternate.Href, page.FinalURL)
+		if err != nil {
+			continue
+		}
+
+		alternates = append(alternates, &HreflangAlternate{Hreflang: alternate.Hreflang, URL: normalizeURL(alternateURL)})
+	}
+
+	return alternates
+}
+
+// checkHreflangPage validates the hreflang set of the page and the language versions it points to.
+func checkHreflangPage(page *crawledPage, alternates []*HreflangAlternate, pageByURL map[string]*crawledPage) *HreflangPage {
+	hreflangPage := &HreflangPage{URL: page.FinalURL, Alternates: alternates}
+
+	var (
+		hasSelf     bool
+		hasXDefault bool
+		urlByCode   = make(map[string]string)
+	)
+
+	for _, alternate := range alternates {
+		code := strings.ToLower(alternate.Hreflang)
+		hasXDefault = hasXDefault || code == hreflangXDefault
+
+		if issue := validateHreflang(alternate.Hreflang); issue != "" {
+			alternate.Issues = append(alternate.Issues, issue)
+		}
+
+		if otherURL, ok := urlByCode[code]; ok && otherURL != alternate.URL {
+			alternate.Issues = append(alternate.Issues, fmt.Sprintf("hreflang %s is also declared for %s", alternate.Hreflang, otherURL))
+		}
+		urlByCode[code] = alternate.URL
+
+		if alternate.URL == page.FinalURL {
+			hasSelf = true
+			alternate.StatusCode = page.StatusCode
+			continue
+		}
+
+		target := pageByURL[alternate.URL]
+		if target != nil {
+			alternate.StatusCode = target.StatusCode
+		}
+		alternate.Issues = append(alternate.Issues, hreflangTargetIssues(page, alternate, target)...)
+	}
+
+	if !hasSelf {
+		hreflangPage.Issues = append(hreflangPage.Issues, "missing self-reference, the page must list itself as an alternate")
+	}
+	if !hasXDefault {
+		hreflangPage.Issues = append(hreflangPage.Issues, "missing x-default alternate for users whose language is not listed")
+	}
+
+	return hreflangPage
+}
+
+// hreflangTargetIssues checks that the alternate is an indexable canonical page that links back to the page.
+func hreflangTargetIssues(page *crawledPage, alternate *HreflangAlternate, target *crawledPage) []string {
+	switch {
+	case target == nil || target.StatusCode == 0:
+		return []string{"alternate could not be downloaded"}
+	case len(target.Redirects) > 0 && target.URL == alternate.URL:
+		return []string{fmt.Sprintf("alternate is a %d redirect to %s", target.Redirects[0].StatusCode, target.FinalURL)}
+	case target.StatusCode != http.StatusOK:
+		return []string{fmt.Sprintf("alternate responds with status %d", target.StatusCode)}
+	case target.extractor == nil:
+		return []string{"alternate is not an HTML page"}
+	}
+
+	var issues []string
+
+	if canonical := pageCanonicals([]*crawledPage{target})[target.FinalURL]; canonical != "" {
+		issues = append(issues, fmt.Sprintf("alternate is canonicalized to %s", canonical))
+	}
+	if target.NoIndex {
+		issues = append(issues, "alternate is noindexed")
+	}
+
+	hasReturnLink := false
+	for _, targetAlternate := range hreflangAlternates(target) {
+		if targetAlternate.URL == page.FinalURL {
+			hasReturnLink = true
+			break
+		}
+	}
+	if !hasReturnLink {
+		issues = append(issues, "missing return link, the alternate does not list this page")
+	}
+
+	return issues
+}
+
+// validateHreflang checks that the hreflang value is x-default or an ISO 639 language code of two or three letters,
+// optionally followed by a script and an ISO 3166-1 alpha-2 region code. It returns the issue, empty if the value is valid.
+func validateHreflang(code string) string {
+	if strings.EqualFold(code, hreflangXDefault) {
+		return ""
+	}
+	if strings.Contains(code, "_") {
+		return fmt.Sprintf("hreflang %q uses an underscore, separate the language and region with a hyphen", code)
+	}
+
+	subtags := strings.Split(code, "-")
+
+	if _, err := language.ParseBase(subtags[0]); err != nil || len(subtags[0]) > 3 {
+		if region, regionErr := language.ParseRegion(subtags[0]); regionErr == nil && region.IsCountry() {
+			return fmt.Sprintf("hreflang %q starts with a region, it must start with a language code", code)
+		}
+		return fmt.Sprintf("hreflang %q has an unknown language code %q", code, subtags[0])
+	}
+	subtags = subtags[1:]
+
+	if len(subtags) > 0 && len(subtags[0]) == 4 {
+		if _, err := language.ParseScript(subtags[0]); err != nil {
+			return fmt.Sprintf("hreflang %q has an unknown script %q", code, subtags[0])
+		}
+		subtags = subtags[1:]
+	}
+
+	if len(subtags) > 0 {
+		region, err := language.ParseRegion(subtags[0])
+		switch {
+		case err != nil:
+			return fmt.Sprintf("hreflang %q has an unknown region code %q", code, subtags[0])
+		case region.Canonicalize() != region:
+			return fmt.Sprintf("hreflang %q has the deprecated region code %q, use %s", code, subtags[0], region.Canonicalize())
+		case !region.IsCountry():
+			return fmt.Sprintf("hreflang %q has the region %q, which is not an ISO 3166-1 country code", code, subtags[0])
+		}
+		subtags = subtags[1:]
+	}
+
+	if len(subtags) > 0 {
+		return fmt.Sprintf("hreflang %q has unexpected subtags, use a language and an optional region", code)
+	}
+
+	return ""
+}
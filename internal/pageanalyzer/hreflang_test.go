@@ -0,0 +1,132 @@
+package pageanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateHreflang(t *testing.T) {
+	tests := []struct {
+		code          string
+		expectedIssue string
+	}{
+		{code: "en"},
+		{code: "en-US"},
+		{code: "zh-Hant-TW"},
+		{code: "fil-PH"},
+		{code: "X-Default"},
+		{code: "en_US", expectedIssue: `hreflang "en_US" uses an underscore, separate the language and region with a hyphen`},
+		{code: "us", expectedIssue: `hreflang "us" starts with a region, it must start with a language code`},
+		{code: "xx", expectedIssue: `hreflang "xx" has an unknown language code "xx"`},
+		{code: "engl", expectedIssue: `hreflang "engl" has an unknown language code "engl"`},
+		{code: "en-UK", expectedIssue: `hreflang "en-UK" has the deprecated region code "UK", use GB`},
+		{code: "en-EU", expectedIssue: `hreflang "en-EU" has the region "EU", which is not an ISO 3166-1 country code`},
+		{code: "es-419", expectedIssue: `hreflang "es-419" has the region "419", which is not an ISO 3166-1 country code`},
+		{code: "en-US-x", expectedIssue: `hreflang "en-US-x" has unexpected subtags, use a language and an optional region`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			assert.Equal(t, test.expectedIssue, validateHreflang(test.code))
+		})
+	}
+}
+
+func TestHreflangReport(t *testing.T) {
+	page := func(head string, alternates ...string) string {
+		content := `<html><head>` + head
+		for i := 0; i+1 < len(alternates); i += 2 {
+			content += fmt.Sprintf(`<link rel="alternate" hreflang="%s" href="%s">`, alternates[i], alternates[i+1])
+		}
+		return content + `</head><body></body></html>`
+	}
+
+	pages := map[string]string{
+		"/en": page("", "en", "/en", "de", "/de", "fr", "/fr", "it", "/it", "es", "/es", "nl", "/old-nl"),
+		"/de": page("", "de", "/de", "en", "/en", "x-default", "/en"),
+		"/fr": page(""),
+		"/it": page(`<link rel="canonical" href="/it/home">`, "it", "/it", "en", "/en"),
+		"/nl": page("", "nl", "/nl", "en", "/en"),
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old-nl" {
+			http.Redirect(w, r, "/nl", http.StatusMovedPermanently)
+			return
+		}
+
+		content, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, content)
+	}))
+	defer testServer.Close()
+
+	analyzer := New(&Config{}, testServer.Client())
+	u := func(path string) string { return testServer.URL + path }
+
+	// The home page is declared without the root path
+	pages["/"] = page("", "en", testServer.URL, "pt", "/pt", "x-default", testServer.URL)
+	pages["/pt"] = page("", "pt", "/pt", "en", testServer.URL, "x-default", testServer.URL)
+
+	t.Run("Validates the set of the analyzed page", func(t *testing.T) {
+		report := analyzer.hreflangReport(context.Background(), analyzer.crawlPages(context.Background(), []string{u("/en")}))
+
+		if !assert.NotNil(t, report) || !assert.Len(t, report.Pages, 1) {
+			return
+		}
+
+		page := report.Pages[0]
+		assert.Equal(t, u("/en"), page.URL)
+		assert.Equal(t, []string{"missing x-default alternate for users whose language is not listed"}, page.Issues)
+
+		issues := make(map[string][]string)
+		for _, alternate := range page.Alternates {
+			issues[alternate.Hreflang] = alternate.Issues
+		}
+		assert.Equal(t, map[string][]string{
+			"en": nil,
+			"de": nil,
+			"fr": {"missing return link, the alternate does not list this page"},
+			"it": {fmt.Sprintf("alternate is canonicalized to %s", u("/it/home"))},
+			"es": {"alternate responds with status 404"},
+			"nl": {fmt.Sprintf("alternate is a 301 redirect to %s", u("/nl"))},
+		}, issues)
+		assert.Equal(t, 5, report.IssuesNum)
+	})
+
+	t.Run("Validates the crawled pages", func(t *testing.T) {
+		report := analyzer.hreflangReport(context.Background(),
+			analyzer.crawlPages(context.Background(), []string{u("/en"), u("/de"), u("/fr"), u("/it")}))
+
+		var urls []string
+		for _, page := range report.Pages {
+			urls = append(urls, page.URL)
+		}
+		assert.Equal(t, []string{u("/de"), u("/en"), u("/it")}, urls)
+		assert.Empty(t, report.Pages[0].Issues)
+		assert.Equal(t, []string{"missing x-default alternate for users whose language is not listed"}, report.Pages[2].Issues)
+	})
+
+	t.Run("Compares normalized URLs", func(t *testing.T) {
+		report := analyzer.hreflangReport(context.Background(), analyzer.crawlPages(context.Background(), []string{testServer.URL}))
+
+		if !assert.NotNil(t, report) || !assert.Len(t, report.Pages, 1) {
+			return
+		}
+		assert.Equal(t, u("/"), report.Pages[0].URL)
+		assert.Equal(t, 0, report.IssuesNum)
+	})
+
+	t.Run("No hreflang alternates", func(t *testing.T) {
+		assert.Nil(t, analyzer.hreflangReport(context.Background(), analyzer.crawlPages(context.Background(), []string{u("/fr")})))
+	})
+}
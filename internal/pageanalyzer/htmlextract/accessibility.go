@@ -0,0 +1,339 @@
+package htmlextract
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/exp/slices"
+)
+
+// Accessibility finding severities, from the most to the least impactful.
+const (
+	SeverityCritical = "critical"
+	SeveritySerious  = "serious"
+	SeverityModerate = "moderate"
+	SeverityMinor    = "minor"
+)
+
+// A11yFinding is a single accessibility problem found in the page.
+type A11yFinding struct {
+	Rule     string
+	Severity string
+	Message  string
+	// Path is a CSS selector locating the element, empty for page level findings.
+	Path string
+}
+
+// genericLinkTexts are link texts that don't describe the link target out of context.
+var genericLinkTexts = []string{
+	"click here", "click", "here", "read more", "more", "learn more", "link", "this", "this page", "go", "details",
+}
+
+// validRoles are the WAI-ARIA 1.2 and DPUB-ARIA roles.
+var validRoles = []string{
+	"alert", "alertdialog", "application", "article", "banner", "blockquote", "button", "caption", "cell",
+	"checkbox", "code", "columnheader", "combobox", "complementary", "contentinfo", "definition", "deletion",
+	"dialog", "directory", "document", "emphasis", "feed", "figure", "form", "generic", "grid", "gridcell",
+	"group", "heading", "img", "insertion", "link", "list", "listbox", "listitem", "log", "main", "marquee",
+	"math", "menu", "menubar", "menuitem", "menuitemcheckbox", "menuitemradio", "meter", "navigation", "none",
+	"note", "option", "paragraph", "presentation", "progressbar", "radio", "radiogroup", "region", "row",
+	"rowgroup", "rowheader", "scrollbar", "search", "searchbox", "separator", "slider", "spinbutton", "status",
+	"strong", "subscript", "superscript", "switch", "tab", "table", "tablist", "tabpanel", "term", "textbox",
+	"time", "timer", "toolbar", "tooltip", "tree", "treegrid", "treeitem",
+	"doc-abstract", "doc-acknowledgments", "doc-afterword", "doc-appendix", "doc-backlink", "doc-biblioentry",
+	"doc-bibliography", "doc-biblioref", "doc-chapter", "doc-colophon", "doc-conclusion", "doc-cover",
+	"doc-credit", "doc-credits", "doc-dedication", "doc-endnote", "doc-endnotes", "doc-epigraph", "doc-epilogue",
+	"doc-errata", "doc-example", "doc-footnote", "doc-foreword", "doc-glossary", "doc-glossref", "doc-index",
+	"doc-introduction", "doc-noteref", "doc-notice", "doc-pagebreak", "doc-pagelist", "doc-part", "doc-preface",
+	"doc-prologue", "doc-pullquote", "doc-qna", "doc-subtitle", "doc-tip", "doc-toc",
+}
+
+// Kinds of ARIA attribute values, used to validate them.
+const (
+	ariaString   = "string"
+	ariaIDRefs   = "idrefs"
+	ariaInteger  = "integer"
+	ariaNumber   = "number"
+	ariaBoolean  = "boolean"
+	ariaTristate = "tristate"
+	ariaToken    = "token"
+	ariaTokens   = "tokens"
+)
+
+type ariaAttribute struct {
+	kind   string
+	tokens []string
+}
+
+// ariaAttributes are the WAI-ARIA 1.2 states and properties.
+var ariaAttributes = map[string]ariaAttribute{
+	"aria-activedescendant":       {kind: ariaIDRefs},
+	"aria-atomic":                 {kind: ariaBoolean},
+	"aria-autocomplete":           {kind: ariaToken, tokens: []string{"inline", "list", "both", "none"}},
+	"aria-braillelabel":           {kind: ariaString},
+	"aria-brailleroledescription": {kind: ariaString},
+	"aria-busy":                   {kind: ariaBoolean},
+	"aria-checked":                {kind: ariaTristate},
+	"aria-colcount":               {kind: ariaInteger},
+	"aria-colindex":               {kind: ariaInteger},
+	"aria-colindextext":           {kind: ariaString},
+	"aria-colspan":                {kind: ariaInteger},
+	"aria-controls":               {kind: ariaIDRefs},
+	"aria-current":                {kind: ariaToken, tokens: []string{"page", "step", "location", "date", "time", "true", "false"}},
+	"aria-describedby":            {kind: ariaIDRefs},
+	"aria-description":            {kind: ariaString},
+	"aria-details":                {kind: ariaIDRefs},
+	"aria-disabled":               {kind: ariaBoolean},
+	"aria-dropeffect":             {kind: ariaTokens, tokens: []string{"copy", "execute", "link", "move", "none", "popup"}},
+	"aria-errormessage":           {kind: ariaIDRefs},
+	"aria-expanded":               {kind: ariaToken, tokens: []string{"true", "false", "undefined"}},
+	"aria-flowto":                 {kind: ariaIDRefs},
+	"aria-grabbed":                {kind: ariaToken, tokens: []string{"true", "false", "undefined"}},
+	"aria-haspopup":               {kind: ariaToken, tokens: []string{"false", "true", "menu", "listbox", "tree", "grid", "dialog"}},
+	"aria-hidden":                 {kind: ariaToken, tokens: []string{"true", "false", "undefined"}},
+	"aria-invalid":                {kind: ariaToken, tokens: []string{"grammar", "false", "spelling", "true"}},
+	"aria-keyshortcuts":           {kind: ariaString},
+	"aria-label":                  {kind: ariaString},
+	"aria-labelledby":             {kind: ariaIDRefs},
+	"aria-level":                  {kind: ariaInteger},
+	"aria-live":                   {kind: ariaToken, tokens: []string{"assertive", "off", "polite"}},
+	"aria-modal":                  {kind: ariaBoolean},
+	"aria-multiline":              {kind: ariaBoolean},
+	"aria-multiselectable":        {kind: ariaBoolean},
+	"aria-orientation":            {kind: ariaToken, tokens: []string{"horizontal", "undefined", "vertical"}},
+	"aria-owns":                   {kind: ariaIDRefs},
+	"aria-placeholder":            {kind: ariaString},
+	"aria-posinset":               {kind: ariaInteger},
+	"aria-pressed":                {kind: ariaTristate},
+	"aria-readonly":               {kind: ariaBoolean},
+	"aria-relevant":               {kind: ariaTokens, tokens: []string{"additions", "all", "removals", "text"}},
+	"aria-required":               {kind: ariaBoolean},
+	"aria-roledescription":        {kind: ariaString},
+	"aria-rowcount":               {kind: ariaInteger},
+	"aria-rowindex":               {kind: ariaInteger},
+	"aria-rowindextext":           {kind: ariaString},
+	"aria-rowspan":                {kind: ariaInteger},
+	"aria-selected":               {kind: ariaToken, tokens: []string{"true", "false", "undefined"}},
+	"aria-setsize":                {kind: ariaInteger},
+	"aria-sort":                   {kind: ariaToken, tokens: []string{"ascending", "descending", "none", "other"}},
+	"aria-valuemax":               {kind: ariaNumber},
+	"aria-valuemin":               {kind: ariaNumber},
+	"aria-valuenow":               {kind: ariaNumber},
+	"aria-valuetext":              {kind: ariaString},
+}
+
+// AccessibilityFindings runs a first-pass accessibility audit over the document.
+func (h *HTMLExtractor) AccessibilityFindings() []A11yFinding {
+	var findings []A11yFinding
+
+	report := func(rule, severity string, selection *goquery.Selection, format string, args ...any) {
+		finding := A11yFinding{
+			Rule:     rule,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		}
+		if selection != nil {
+			finding.Path = h.cssPath(selection)
+		}
+		findings = append(findings, finding)
+	}
+
+	// Language of the page
+	if lang := strings.TrimSpace(h.goQueryDoc.Find("html").AttrOr("lang", "")); lang == "" {
+		report("html-has-lang", SeveritySerious, nil, "<html> element has no lang attribute")
+	}
+
+	// Text alternatives
+	h.goQueryDoc.Find("img, area[href], input[type='image']").Each(func(index int, item *goquery.Selection) {
+		if _, ok := item.Attr("alt"); ok || hasARIAName(item) || isPresentational(item) {
+			return
+		}
+		report("image-alt", SeverityCritical, item, "<%s> has no alt text", goquery.NodeName(item))
+	})
+
+	// Form field labels
+	h.goQueryDoc.Find("input, select, textarea").Each(func(index int, item *goquery.Selection) {
+		switch strings.ToLower(item.AttrOr("type", "")) {
+		case "hidden", "submit", "reset", "button", "image":
+			return
+		}
+		if h.hasLabel(item) {
+			return
+		}
+		report("label", SeveritySerious, item, "form field %q has no label", item.AttrOr("name", goquery.NodeName(item)))
+	})
+
+	// Link names
+	h.goQueryDoc.Find("a[href]").Each(func(index int, item *goquery.Selection) {
+		name := strings.ToLower(h.accessibleName(item))
+		switch {
+		case name == "":
+			report("link-name", SeveritySerious, item, "link to %q has no accessible name", item.AttrOr("href", ""))
+		case slices.Contains(genericLinkTexts, strings.Trim(name, ".!:…» ")):
+			report("link-generic-text", SeverityModerate, item, "link text %q doesn't describe the link target", name)
+		}
+	})
+
+	// Duplicate ids
+	seenIDs := make(map[string]bool)
+	h.goQueryDoc.Find("[id]").Each(func(index int, item *goquery.Selection) {
+		id := item.AttrOr("id", "")
+		if seenIDs[id] {
+			report("duplicate-id", SeverityModerate, item, "id %q is used more than once", id)
+		}
+		seenIDs[id] = true
+	})
+
+	// Landmarks
+	if h.goQueryDoc.Find("main, [role='main']").Length() == 0 {
+		report("landmark-main", SeverityModerate, nil, "page has no main landmark")
+	}
+	if h.goQueryDoc.Find("nav, [role='navigation']").Length() == 0 {
+		report("landmark-navigation", SeverityMinor, nil, "page has no navigation landmark")
+	}
+
+	// ARIA roles and attributes
+	h.goQueryDoc.Find("*").Each(func(index int, item *goquery.Selection) {
+		if role, ok := item.Attr("role"); ok {
+			for _, r := range strings.Fields(strings.ToLower(role)) {
+				if !slices.Contains(validRoles, r) {
+					report("aria-role", SeveritySerious, item, "invalid ARIA role %q", r)
+				}
+			}
+		}
+
+		for _, attr := range item.Get(0).Attr {
+			name := strings.ToLower(attr.Key)
+			if !strings.HasPrefix(name, "aria-") {
+				continue
+			}
+			if message := h.validateARIAAttribute(name, attr.Val); message != "" {
+				report("aria-attribute", SeveritySerious, item, "%s", message)
+			}
+		}
+	})
+
+	return findings
+}
+
+func (h *HTMLExtractor) hasLabel(field *goquery.Selection) bool {
+	if hasARIAName(field) || strings.TrimSpace(field.AttrOr("title", "")) != "" {
+		return true
+	}
+
+	// Wrapped in a label
+	if normalizeSpace(field.Closest("label").Text()) != "" {
+		return true
+	}
+
+	// Referenced by a label
+	id := field.AttrOr("id", "")
+	if id == "" {
+		return false
+	}
+
+	hasLabel := false
+	h.goQueryDoc.Find("label[for]").EachWithBreak(func(index int, label *goquery.Selection) bool {
+		if label.AttrOr("for", "") == id && normalizeSpace(label.Text()) != "" {
+			hasLabel = true
+		}
+		return !hasLabel
+	})
+
+	return hasLabel
+}
+
+// accessibleName approximates the name assistive technologies announce for the element.
+func (h *HTMLExtractor) accessibleName(selection *goquery.Selection) string {
+	var labels []string
+	for _, id := range strings.Fields(selection.AttrOr("aria-labelledby", "")) {
+		labels = append(labels, h.elementByID(id).Text())
+	}
+	if label := normalizeSpace(strings.Join(labels, " ")); label != "" {
+		return label
+	}
+
+	if label := strings.TrimSpace(selection.AttrOr("aria-label", "")); label != "" {
+		return label
+	}
+
+	if text := normalizeSpace(selection.Text()); text != "" {
+		return text
+	}
+
+	var alts []string
+	selection.Find("img[alt]").Each(func(index int, img *goquery.Selection) {
+		alts = append(alts, img.AttrOr("alt", ""))
+	})
+	if alt := normalizeSpace(strings.Join(alts, " ")); alt != "" {
+		return alt
+	}
+
+	return strings.TrimSpace(selection.AttrOr("title", ""))
+}
+
+func hasARIAName(selection *goquery.Selection) bool {
+	return strings.TrimSpace(selection.AttrOr("aria-label", "")) != "" ||
+		strings.TrimSpace(selection.AttrOr("aria-labelledby", "")) != ""
+}
+
+func isPresentational(selection *goquery.Selection) bool {
+	role := strings.ToLower(selection.AttrOr("role", ""))
+	return role == "presentation" || role == "none" || selection.AttrOr("aria-hidden", "") == "true"
+}
+
+// validateARIAAttribute returns a description of the problem, or an empty string if the attribute is valid.
+func (h *HTMLExtractor) validateARIAAttribute(name, value string) string {
+	attribute, ok := ariaAttributes[name]
+	if !ok {
+		return fmt.Sprintf("unknown ARIA attribute %q", name)
+	}
+
+	value = strings.TrimSpace(value)
+
+	switch attribute.kind {
+	case ariaBoolean:
+		if value != "true" && value != "false" {
+			return fmt.Sprintf("%s must be true or false, got %q", name, value)
+		}
+	case ariaTristate:
+		if !slices.Contains([]string{"true", "false", "mixed", "undefined"}, value) {
+			return fmt.Sprintf("%s must be true, false or mixed, got %q", name, value)
+		}
+	case ariaInteger:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Sprintf("%s must be an integer, got %q", name, value)
+		}
+	case ariaNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Sprintf("%s must be a number, got %q", name, value)
+		}
+	case ariaToken:
+		if !slices.Contains(attribute.tokens, strings.ToLower(value)) {
+			return fmt.Sprintf("%s must be one of %s, got %q", name, strings.Join(attribute.tokens, ", "), value)
+		}
+	case ariaTokens:
+		for _, token := range strings.Fields(strings.ToLower(value)) {
+			if !slices.Contains(attribute.tokens, token) {
+				return fmt.Sprintf("%s must be a list of %s, got %q", name, strings.Join(attribute.tokens, ", "), value)
+			}
+		}
+	case ariaIDRefs:
+		for _, id := range strings.Fields(value) {
+			if h.elementByID(id).Length() == 0 {
+				return fmt.Sprintf("%s references missing id %q", name, id)
+			}
+		}
+	}
+
+	return ""
+}
+
+// elementByID finds elements by id without building a selector, so any id value is supported.
+func (h *HTMLExtractor) elementByID(id string) *goquery.Selection {
+	return h.goQueryDoc.Find("[id]").FilterFunction(func(index int, item *goquery.Selection) bool {
+		return item.AttrOr("id", "") == id
+	})
+}
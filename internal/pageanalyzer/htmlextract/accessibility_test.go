@@ -0,0 +1,84 @@
+package htmlextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessibilityFindings(t *testing.T) {
+	t.Run("Accessible page", func(t *testing.T) {
+		htmlContent := `
+			<!DOCTYPE html>
+			<html lang="en">
+			<head>
+				<title>Test Page</title>
+			</head>
+			<body>
+				<nav><a href="/pricing">Pricing</a></nav>
+				<main>
+					<img src="/decorative.png" alt="">
+					<a href="/home" aria-labelledby="home-label"><img src="/logo.png" alt=""></a>
+					<span id="home-label">Home</span>
+					<form>
+						<label for="email">Email</label>
+						<input type="email" id="email" name="email">
+						<label>Password <input type="password" name="password"></label>
+						<input type="hidden" name="token">
+						<button aria-expanded="false" role="button">Login</button>
+					</form>
+				</main>
+			</body>
+			</html>
+		`
+
+		extractor, err := New([]byte(htmlContent))
+		if err != nil {
+			t.Fatalf("failed to create extractor: %v", err)
+		}
+
+		assert.Empty(t, extractor.AccessibilityFindings())
+	})
+
+	t.Run("Inaccessible page", func(t *testing.T) {
+		htmlContent := `
+			<!DOCTYPE html>
+			<html>
+			<head>
+				<title>Test Page</title>
+			</head>
+			<body>
+				<div id="content">
+					<img src="/photo.png">
+					<a href="/more">Click here</a>
+					<a href="/icon"><i class="icon"></i></a>
+					<input type="text" name="query" placeholder="Search">
+				</div>
+				<div id="content" role="bogus" aria-hidden="yes" aria-foo="1" aria-controls="missing"></div>
+			</body>
+			</html>
+		`
+
+		extractor, err := New([]byte(htmlContent))
+		if err != nil {
+			t.Fatalf("failed to create extractor: %v", err)
+		}
+
+		expectedFindings := []A11yFinding{
+			{Rule: "html-has-lang", Severity: SeveritySerious, Message: "<html> element has no lang attribute"},
+			{Rule: "image-alt", Severity: SeverityCritical, Message: "<img> has no alt text", Path: "html > body > div:nth-of-type(1) > img"},
+			{Rule: "label", Severity: SeveritySerious, Message: `form field "query" has no label`, Path: "html > body > div:nth-of-type(1) > input"},
+			{Rule: "link-generic-text", Severity: SeverityModerate, Message: `link text "click here" doesn't describe the link target`, Path: "html > body > div:nth-of-type(1) > a:nth-of-type(1)"},
+			{Rule: "link-name", Severity: SeveritySerious, Message: `link to "/icon" has no accessible name`, Path: "html > body > div:nth-of-type(1) > a:nth-of-type(2)"},
+			{Rule: "duplicate-id", Severity: SeverityModerate, Message: `id "content" is used more than once`, Path: "html > body > div:nth-of-type(2)"},
+			{Rule: "landmark-main", Severity: SeverityModerate, Message: "page has no main landmark"},
+			{Rule: "landmark-navigation", Severity: SeverityMinor, Message: "page has no navigation landmark"},
+			{Rule: "aria-role", Severity: SeveritySerious, Message: `invalid ARIA role "bogus"`, Path: "html > body > div:nth-of-type(2)"},
+			{Rule: "aria-attribute", Severity: SeveritySerious, Message: `aria-hidden must be one of true, false, undefined, got "yes"`, Path: "html > body > div:nth-of-type(2)"},
+			{Rule: "aria-attribute", Severity: SeveritySerious, Message: `unknown ARIA attribute "aria-foo"`, Path: "html > body > div:nth-of-type(2)"},
+			{Rule: "aria-attribute", Severity: SeveritySerious, Message: `aria-controls references missing id "missing"`, Path: "html > body > div:nth-of-type(2)"},
+		}
+
+		assert.Equal(t, expectedFindings, extractor.AccessibilityFindings())
+	})
+}
@@ -0,0 +1,40 @@
+package htmlextract
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Alternate is a <link rel="alternate"> of the page, e.g. a translation with hreflang or a feed with type.
+type Alternate struct {
+	Href     string
+	Hreflang string
+	Type     string
+	Title    string
+	Media    string
+}
+
+// Alternates returns the alternate links of the page in document order.
+func (h *HTMLExtractor) Alternates() []Alternate {
+	var alternates []Alternate
+
+	h.goQueryDoc.Find("link[rel][href]").Each(func(index int, item *goquery.Selection) {
+		for _, rel := range strings.Fields(strings.ToLower(item.AttrOr("rel", ""))) {
+			if rel != "alternate" {
+				continue
+			}
+
+			alternates = append(alternates, Alternate{
+				Href:     strings.TrimSpace(item.AttrOr("href", "")),
+				Hreflang: strings.TrimSpace(item.AttrOr("hreflang", "")),
+				Type:     strings.ToLower(strings.TrimSpace(item.AttrOr("type", ""))),
+				Title:    normalizeSpace(item.AttrOr("title", "")),
+				Media:    strings.TrimSpace(item.AttrOr("media", "")),
+			})
+			return
+		}
+	})
+
+	return alternates
+}
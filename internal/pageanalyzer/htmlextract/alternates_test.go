@@ -0,0 +1,37 @@
+package htmlextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlternates(t *testing.T) {
+	htmlContent := `
+		<html>
+		<head>
+			<link rel="canonical" href="https://example.com/en/">
+			<link rel="alternate" hreflang="en" href="https://example.com/en/">
+			<link rel="Alternate" hreflang=" de-DE " href=" https://example.com/de/ ">
+			<link rel="alternate" type="application/RSS+xml" title="News  feed" href="/feed.xml">
+			<link rel="alternate" media="only screen and (max-width: 640px)" href="https://m.example.com/">
+			<link rel="alternate stylesheet" href="/dark.css" title="Dark">
+		</head>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	expected := []Alternate{
+		{Href: "https://example.com/en/", Hreflang: "en"},
+		{Href: "https://example.com/de/", Hreflang: "de-DE"},
+		{Href: "/feed.xml", Type: "application/rss+xml", Title: "News feed"},
+		{Href: "https://m.example.com/", Media: "only screen and (max-width: 640px)"},
+		{Href: "/dark.css", Title: "Dark"},
+	}
+
+	assert.Equal(t, expected, extractor.Alternates())
+}
@@ -0,0 +1,20 @@
+package htmlextract
+
+import (
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Anchors returns the fragment identifiers a link can point to: element ids and named anchors.
+func (h *HTMLExtractor) Anchors() map[string]bool {
+	anchors := make(map[string]bool)
+
+	h.goQueryDoc.Find("[id]").Each(func(index int, item *goquery.Selection) {
+		anchors[item.AttrOr("id", "")] = true
+	})
+
+	h.goQueryDoc.Find("a[name]").Each(func(index int, item *goquery.Selection) {
+		anchors[item.AttrOr("name", "")] = true
+	})
+
+	return anchors
+}
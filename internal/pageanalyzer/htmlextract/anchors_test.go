@@ -0,0 +1,35 @@
+package htmlextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnchors(t *testing.T) {
+	htmlContent := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<title>Test Page</title>
+		</head>
+		<body>
+			<h2 id="install">Install</h2>
+			<a name="legacy"></a>
+			<div name="not-an-anchor"></div>
+		</body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	expectedAnchors := map[string]bool{
+		"install": true,
+		"legacy":  true,
+	}
+
+	assert.Equal(t, expectedAnchors, extractor.Anchors())
+}
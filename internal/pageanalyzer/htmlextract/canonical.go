@@ -0,0 +1,24 @@
+package htmlextract
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Canonical returns the href of the first <link rel="canonical"> of the page, empty if there is none.
+func (h *HTMLExtractor) Canonical() string {
+	var canonical string
+
+	h.goQueryDoc.Find("link[rel][href]").EachWithBreak(func(index int, item *goquery.Selection) bool {
+		for _, rel := range strings.Fields(strings.ToLower(item.AttrOr("rel", ""))) {
+			if rel == "canonical" {
+				canonical = strings.TrimSpace(item.AttrOr("href", ""))
+				return false
+			}
+		}
+		return true
+	})
+
+	return canonical
+}
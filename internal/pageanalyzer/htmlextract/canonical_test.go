@@ -0,0 +1,41 @@
+package htmlextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name        string
+		htmlContent string
+		expected    string
+	}{
+		{
+			name: "First canonical link",
+			htmlContent: `<html><head>
+				<link rel="stylesheet" href="/style.css">
+				<link rel="Canonical" href=" https://example.com/page ">
+				<link rel="canonical" href="https://example.com/other">
+			</head></html>`,
+			expected: "https://example.com/page",
+		},
+		{
+			name:        "No canonical link",
+			htmlContent: `<html><head><link rel="alternate" href="/feed.xml"></head></html>`,
+			expected:    "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			extractor, err := New([]byte(test.htmlContent))
+			if err != nil {
+				t.Fatalf("failed to create extractor: %v", err)
+			}
+
+			assert.Equal(t, test.expected, extractor.Canonical())
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package htmlextract
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// cssIdentifierRegex matches ids that can be used in a selector without escaping.
+var cssIdentifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// cssPath builds a CSS selector that locates the element in the document, e.g. "body > div:nth-of-type(2) > img".
+// The path starts at the closest ancestor with a unique id when there is one.
+func (h *HTMLExtractor) cssPath(selection *goquery.Selection) string {
+	var segments []string
+
+	for current := selection.First(); current.Length() > 0; current = current.Parent() {
+		tag := goquery.NodeName(current)
+		if tag == "" || tag == "#document" {
+			break
+		}
+
+		if id := current.AttrOr("id", ""); cssIdentifierRegex.MatchString(id) &&
+			h.goQueryDoc.Find("#"+id).Length() == 1 {
+			segments = append(segments, "#"+id)
+			break
+		}
+
+		segment := tag
+		if siblings := current.Parent().Children().Filter(tag); siblings.Length() > 1 {
+			segment = fmt.Sprintf("%s:nth-of-type(%d)", tag, siblings.IndexOfSelection(current)+1)
+		}
+		segments = append(segments, segment)
+	}
+
+	// The segments were collected from the element up to the root
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+
+	return strings.Join(segments, " > ")
+}
@@ -0,0 +1,35 @@
+package htmlextract
+
+import (
+	"mime"
+
+	"golang.org/x/exp/slices"
+)
+
+// FeedTypes are the MIME types of the RSS, Atom and JSON Feed documents announced with <link rel="alternate">.
+// JSON Feed 1.0 used application/json before application/feed+json was registered, links of that type may point to
+// other JSON documents.
+var FeedTypes = []string{"application/rss+xml", "application/atom+xml", "application/feed+json", "application/json"}
+
+// Feed is an RSS, Atom or JSON Feed document announced by the page.
+type Feed struct {
+	URL   string
+	Type  string
+	Title string
+}
+
+// Feeds returns the feeds announced by the alternate links of the page, in document order.
+func (h *HTMLExtractor) Feeds() []Feed {
+	var feeds []Feed
+
+	for _, alternate := range h.Alternates() {
+		mediaType, _, err := mime.ParseMediaType(alternate.Type)
+		if err != nil || !slices.Contains(FeedTypes, mediaType) {
+			continue
+		}
+
+		feeds = append(feeds, Feed{URL: alternate.Href, Type: mediaType, Title: alternate.Title})
+	}
+
+	return feeds
+}
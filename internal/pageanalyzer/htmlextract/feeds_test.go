@@ -0,0 +1,34 @@
+package htmlextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeds(t *testing.T) {
+	htmlContent := `
+		<html>
+		<head>
+			<link rel="alternate" type="application/rss+xml" title="Posts" href="/feed.xml">
+			<link rel="alternate" type="application/atom+xml; charset=utf-8" href="/atom.xml">
+			<link rel="alternate" type="application/feed+json" title="JSON" href="https://example.com/feed.json">
+			<link rel="alternate" hreflang="de" href="https://example.com/de/">
+			<link rel="stylesheet" type="application/rss+xml" href="/not-a-feed.xml">
+		</head>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	expected := []Feed{
+		{URL: "/feed.xml", Type: "application/rss+xml", Title: "Posts"},
+		{URL: "/atom.xml", Type: "application/atom+xml"},
+		{URL: "https://example.com/feed.json", Type: "application/feed+json", Title: "JSON"},
+	}
+
+	assert.Equal(t, expected, extractor.Feeds())
+}
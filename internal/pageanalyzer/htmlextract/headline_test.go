@@ -37,3 +37,35 @@ func TestHeadingTagToTexts(t *testing.T) {
 
 	assert.Equal(t, expectedHeadings, headings)
 }
+
+func TestHeadings(t *testing.T) {
+	htmlContent := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<title>Test Page</title>
+		</head>
+		<body>
+			<h2>Intro</h2>
+			<h1>  Main
+				Heading </h1>
+			<h3><img src="/logo.png" alt="Logo"></h3>
+			<h2></h2>
+		</body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	expectedHeadings := []Heading{
+		{Level: 2, Text: "Intro"},
+		{Level: 1, Text: "Main Heading"},
+		{Level: 3, Text: "Logo"},
+		{Level: 2, Text: ""},
+	}
+
+	assert.Equal(t, expectedHeadings, extractor.Headings())
+}
@@ -2,10 +2,18 @@ package htmlextract
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
+// Heading is a single h1-h6 element of the page.
+type Heading struct {
+	Level int
+	// Text is the heading text with whitespace collapsed, falling back to the alt text of images inside it.
+	Text string
+}
+
 func (h *HTMLExtractor) HeadingTagToTexts() map[string][]string {
 	headingTagToTexts := make(map[string][]string)
 
@@ -18,3 +26,32 @@ func (h *HTMLExtractor) HeadingTagToTexts() map[string][]string {
 
 	return headingTagToTexts
 }
+
+// Headings returns all headings in document order.
+func (h *HTMLExtractor) Headings() []Heading {
+	var headings []Heading
+
+	h.goQueryDoc.Find("h1, h2, h3, h4, h5, h6").Each(func(index int, item *goquery.Selection) {
+		text := normalizeSpace(item.Text())
+		if text == "" {
+			var alts []string
+			item.Find("img[alt]").Each(func(index int, img *goquery.Selection) {
+				alts = append(alts, img.AttrOr("alt", ""))
+			})
+			text = normalizeSpace(strings.Join(alts, " "))
+		}
+
+		headings = append(headings, Heading{
+			// The tag is one of h1-h6, so the second byte is the level digit
+			Level: int(goquery.NodeName(item)[1] - '0'),
+			Text:  text,
+		})
+	})
+
+	return headings
+}
+
+// normalizeSpace trims the text and collapses every whitespace sequence into a single space.
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
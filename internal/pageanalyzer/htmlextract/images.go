@@ -0,0 +1,129 @@
+package htmlextract
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// cssURLRegex matches the url() values of a CSS declaration, with or without quotes.
+var cssURLRegex = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)'"\s]*))\s*\)`)
+
+// Image is an <img>, with the sources of its <picture>, or an element with an inline CSS background image.
+type Image struct {
+	Element string
+	CSSPath string
+	// Width, Height and Loading are the attributes of the <img> as declared, empty for backgrounds
+	Width   string
+	Height  string
+	Loading string
+	// Pixel is set for images of at most 1x1 pixels, which are usually tracking pixels.
+	Pixel   bool
+	Sources []ImageSource
+}
+
+// ImageSource is a URL the browser may load for an image.
+type ImageSource struct {
+	URL string
+	// Element and Attr tell where the URL was found, e.g. "source" and "srcset".
+	Element string
+	Attr    string
+	// Descriptor is the srcset width or pixel density descriptor, e.g. "800w" or "2x"
+	Descriptor string
+	// Type is the type attribute of a <picture> source, e.g. "image/webp"
+	Type string
+}
+
+// Images returns the images of the page in document order.
+func (h *HTMLExtractor) Images() []Image {
+	var images []Image
+
+	h.goQueryDoc.Find("img, [style]").Each(func(index int, item *goquery.Selection) {
+		if goquery.NodeName(item) == "img" {
+			if image := h.imgImage(item); len(image.Sources) > 0 {
+				images = append(images, image)
+			}
+		}
+
+		if image := h.backgroundImage(item); len(image.Sources) > 0 {
+			images = append(images, image)
+		}
+	})
+
+	return images
+}
+
+// imgImage returns the image of an <img> with the sources of its <picture> first, in the order the browser
+// considers them.
+func (h *HTMLExtractor) imgImage(img *goquery.Selection) Image {
+	image := Image{
+		Element: "img",
+		CSSPath: h.cssPath(img),
+		Width:   strings.TrimSpace(img.AttrOr("width", "")),
+		Height:  strings.TrimSpace(img.AttrOr("height", "")),
+		Loading: strings.ToLower(strings.TrimSpace(img.AttrOr("loading", ""))),
+		Pixel:   isPixel(img),
+	}
+
+	addSrcset := func(item *goquery.Selection) {
+		for _, candidate := range parseSrcset(item.AttrOr("srcset", "")) {
+			image.Sources = append(image.Sources, ImageSource{
+				URL:        candidate.url,
+				Element:    goquery.NodeName(item),
+				Attr:       "srcset",
+				Descriptor: candidate.descriptor,
+				Type:       strings.ToLower(strings.TrimSpace(item.AttrOr("type", ""))),
+			})
+		}
+	}
+
+	if parent := img.Parent(); goquery.NodeName(parent) == "picture" {
+		parent.ChildrenFiltered("source[srcset]").Each(func(index int, source *goquery.Selection) {
+			addSrcset(source)
+		})
+	}
+
+	addSrcset(img)
+
+	if src := strings.TrimSpace(img.AttrOr("src", "")); src != "" {
+		image.Sources = append(image.Sources, ImageSource{URL: src, Element: "img", Attr: "src"})
+	}
+
+	return image
+}
+
+// backgroundImage returns the images of the background declarations in the style attribute of the element.
+func (h *HTMLExtractor) backgroundImage(item *goquery.Selection) Image {
+	image := Image{Element: goquery.NodeName(item)}
+
+	style := item.AttrOr("style", "")
+	matches := cssURLRegex.FindAllStringSubmatch(style, -1)
+	if len(matches) == 0 {
+		return image
+	}
+
+	// Mask the URLs before splitting the declarations, data URLs contain semicolons
+	masked := cssURLRegex.ReplaceAllLiteralString(style, "url()")
+
+	nextMatch := 0
+	for _, declaration := range strings.Split(masked, ";") {
+		property, _, _ := strings.Cut(declaration, ":")
+		isBackground := strings.HasPrefix(strings.ToLower(strings.TrimSpace(property)), "background")
+
+		for urlsNum := strings.Count(declaration, "url()"); urlsNum > 0 && nextMatch < len(matches); urlsNum-- {
+			match := matches[nextMatch]
+			nextMatch++
+
+			if url := strings.TrimSpace(match[1] + match[2] + match[3]); isBackground && url != "" {
+				image.Sources = append(image.Sources, ImageSource{URL: url, Element: image.Element, Attr: "style"})
+			}
+		}
+	}
+
+	if len(image.Sources) > 0 {
+		image.CSSPath = h.cssPath(item)
+	}
+
+	return image
+}
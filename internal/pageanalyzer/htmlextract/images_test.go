@@ -0,0 +1,74 @@
+package htmlextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImages(t *testing.T) {
+	htmlContent := `
+		<html>
+		<body>
+			<img src="/logo.png" width="120" height="40" alt="Logo">
+			<div id="hero" style="color: red; background: #000 url('/hero.jpg') no-repeat; mask-image: url(/mask.svg)">
+				<picture>
+					<source srcset="/photo.avif" type="image/avif">
+					<source srcset="/photo-800.webp 800w, /photo-1600.webp 1600w" type="image/webp">
+					<img src="/photo.jpg" srcset="/photo@2x.jpg 2x" loading="lazy" alt="Photo">
+				</picture>
+			</div>
+			<span style="background-image: url(data:image/png;base64,iVBORw0KGgo=)"></span>
+			<img src="https://tracker.example.com/pixel.gif" width="1" height="1" alt="">
+			<img alt="No source">
+		</body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	expected := []Image{
+		{
+			Element: "img",
+			CSSPath: "html > body > img:nth-of-type(1)",
+			Width:   "120",
+			Height:  "40",
+			Sources: []ImageSource{{URL: "/logo.png", Element: "img", Attr: "src"}},
+		},
+		{
+			Element: "div",
+			CSSPath: "#hero",
+			Sources: []ImageSource{{URL: "/hero.jpg", Element: "div", Attr: "style"}},
+		},
+		{
+			Element: "img",
+			CSSPath: "#hero > picture > img",
+			Loading: "lazy",
+			Sources: []ImageSource{
+				{URL: "/photo.avif", Element: "source", Attr: "srcset", Type: "image/avif"},
+				{URL: "/photo-800.webp", Element: "source", Attr: "srcset", Descriptor: "800w", Type: "image/webp"},
+				{URL: "/photo-1600.webp", Element: "source", Attr: "srcset", Descriptor: "1600w", Type: "image/webp"},
+				{URL: "/photo@2x.jpg", Element: "img", Attr: "srcset", Descriptor: "2x"},
+				{URL: "/photo.jpg", Element: "img", Attr: "src"},
+			},
+		},
+		{
+			Element: "span",
+			CSSPath: "html > body > span",
+			Sources: []ImageSource{{URL: "data:image/png;base64,iVBORw0KGgo=", Element: "span", Attr: "style"}},
+		},
+		{
+			Element: "img",
+			CSSPath: "html > body > img:nth-of-type(2)",
+			Width:   "1",
+			Height:  "1",
+			Pixel:   true,
+			Sources: []ImageSource{{URL: "https://tracker.example.com/pixel.gif", Element: "img", Attr: "src"}},
+		},
+	}
+
+	assert.Equal(t, expected, extractor.Images())
+}
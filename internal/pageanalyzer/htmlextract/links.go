@@ -1,17 +1,63 @@
 package htmlextract
 
 import (
+	"strings"
+
 	"github.com/PuerkitoBio/goquery"
 )
 
+// Link is an <a href> element of the page.
+type Link struct {
+	Href string
+	// Text is the anchor text with whitespace collapsed.
+	Text string
+	// Rel holds the lower-cased link types of the rel attribute, e.g. nofollow, sponsored, ugc, noopener.
+	Rel    []string
+	Target string
+	Title  string
+	// Download reports whether the link has the download attribute.
+	Download bool
+	// HasImage reports whether the link wraps an image.
+	HasImage bool
+}
+
+// HasRel reports whether the rel attribute contains the link type.
+func (l Link) HasRel(linkType string) bool {
+	for _, rel := range l.Rel {
+		if rel == linkType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Links returns the href of the links of the page.
 func (h *HTMLExtractor) Links() []string {
 	var links []string
 
+	for _, link := range h.LinkElements() {
+		links = append(links, link.Href)
+	}
+
+	return links
+}
+
+// LinkElements returns the links of the page with the attributes of their elements.
+func (h *HTMLExtractor) LinkElements() []Link {
+	var links []Link
+
 	h.goQueryDoc.Find("a[href]").Each(func(index int, item *goquery.Selection) {
-		href, exists := item.Attr("href")
-		if exists {
-			links = append(links, href)
-		}
+		_, download := item.Attr("download")
+		links = append(links, Link{
+			Href:     strings.TrimSpace(item.AttrOr("href", "")),
+			Text:     normalizeSpace(item.Text()),
+			Rel:      strings.Fields(strings.ToLower(item.AttrOr("rel", ""))),
+			Target:   strings.TrimSpace(item.AttrOr("target", "")),
+			Title:    strings.TrimSpace(item.AttrOr("title", "")),
+			Download: download,
+			HasImage: item.Find("img, svg, picture").Length() > 0,
+		})
 	})
 
 	return links
@@ -36,3 +36,42 @@ func TestLinks(t *testing.T) {
 
 	assert.ElementsMatch(t, expectedLinks, links)
 }
+
+func TestLinkElements(t *testing.T) {
+	htmlContent := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<title>Test Page</title>
+		</head>
+		<body>
+			<a href=" http://example.com " rel="NoFollow  sponsored" target="_blank" title="Example site">
+				Example   site
+			</a>
+			<a href="/report.pdf" download><img src="/pdf.png" alt="Report"></a>
+			<a name="anchor">No href</a>
+		</body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	expectedLinks := []Link{
+		{
+			Href:   "http://example.com",
+			Text:   "Example site",
+			Rel:    []string{"nofollow", "sponsored"},
+			Target: "_blank",
+			Title:  "Example site",
+		},
+		{Href: "/report.pdf", Rel: []string{}, Download: true, HasImage: true},
+	}
+
+	links := extractor.LinkElements()
+	assert.Equal(t, expectedLinks, links)
+	assert.True(t, links[0].HasRel("nofollow"))
+	assert.False(t, links[0].HasRel("ugc"))
+}
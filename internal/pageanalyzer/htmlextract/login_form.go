@@ -1,15 +1,222 @@
 package htmlextract
 
 import (
+	"math"
+	"regexp"
+	"strings"
+
 	"github.com/PuerkitoBio/goquery"
 )
 
+// Form kinds
+const (
+	FormKindLogin      = "login"
+	FormKindSignup     = "signup"
+	FormKindSearch     = "search"
+	FormKindNewsletter = "newsletter"
+	FormKindPayment    = "payment"
+	FormKindOther      = "other"
+)
+
+// Form is a <form> element of the page with its fields and its classification.
+type Form struct {
+	Action string
+	// Method is the upper-cased submission method, GET when not set.
+	Method string
+	Fields []FormField
+	Kind   string
+	// Confidence in [0, 1] tells how strongly the fields and texts of the form support Kind.
+	Confidence float64
+	Path       string
+}
+
+// FormField is an input, select or textarea of a form.
+type FormField struct {
+	Tag          string
+	Type         string
+	Name         string
+	Autocomplete string
+	// HasAutocomplete reports whether the autocomplete attribute is set, even if empty.
+	HasAutocomplete bool
+}
+
+var (
+	loginKeywordsRegex      = regexp.MustCompile(`\b(log ?in|sign ?in|signin|logon|authenticate)\b`)
+	signupKeywordsRegex     = regexp.MustCompile(`\b(sign ?up|register|registration|create (an |your )?account|join)\b`)
+	searchKeywordsRegex     = regexp.MustCompile(`\bsearch\b`)
+	newsletterKeywordsRegex = regexp.MustCompile(`\b(subscribe|newsletter|mailing list)\b`)
+	paymentKeywordsRegex    = regexp.MustCompile(`\b(card ?number|credit card|cvc|cvv|expiry|expiration|pay|payment|checkout)\b`)
+	nextStepKeywordsRegex   = regexp.MustCompile(`\b(next|continue)\b`)
+	usernameFieldRegex      = regexp.MustCompile(`(user|login|email|account|identifier)`)
+	signupFieldRegex        = regexp.MustCompile(`(first.?name|last.?name|full.?name|phone|birth|confirm|repeat|retype)`)
+	paymentFieldRegex       = regexp.MustCompile(`(card|cc.?num|cvc|cvv|expir)`)
+	searchFieldRegex        = regexp.MustCompile(`^(q|s|query|search|keywords?)$`)
+)
+
 func (h *HTMLExtractor) HasLoginForm() bool {
-	var hasLoginForm bool
+	for _, form := range h.Forms() {
+		if form.Kind == FormKindLogin {
+			return true
+		}
+	}
+
+	// Password fields outside a form are usually submitted by a script, treat them as a login
+	return h.goQueryDoc.Find("input[type='password']").FilterFunction(func(index int, item *goquery.Selection) bool {
+		return item.Closest("form").Length() == 0 && item.AttrOr("autocomplete", "") != "new-password"
+	}).Length() > 0
+}
+
+// Forms returns all forms of the page in document order.
+func (h *HTMLExtractor) Forms() []Form {
+	var forms []Form
+
+	h.goQueryDoc.Find("form").Each(func(index int, item *goquery.Selection) {
+		form := Form{
+			Action: strings.TrimSpace(item.AttrOr("action", "")),
+			Method: strings.ToUpper(strings.TrimSpace(item.AttrOr("method", "GET"))),
+			Path:   h.cssPath(item),
+		}
+
+		item.Find("input, select, textarea").Each(func(index int, field *goquery.Selection) {
+			tag := goquery.NodeName(field)
+			fieldType := tag
+			if tag == "input" {
+				fieldType = strings.ToLower(field.AttrOr("type", "text"))
+			}
+
+			// Buttons are not fields
+			switch fieldType {
+			case "submit", "button", "reset", "image":
+				return
+			}
+
+			autocomplete, hasAutocomplete := field.Attr("autocomplete")
+			form.Fields = append(form.Fields, FormField{
+				Tag:             tag,
+				Type:            fieldType,
+				Name:            field.AttrOr("name", field.AttrOr("id", "")),
+				Autocomplete:    strings.ToLower(strings.TrimSpace(autocomplete)),
+				HasAutocomplete: hasAutocomplete,
+			})
+		})
 
-	h.goQueryDoc.Find("input[type='password']").Each(func(index int, item *goquery.Selection) {
-		hasLoginForm = true
+		form.Kind, form.Confidence = classifyForm(item, form.Fields)
+		forms = append(forms, form)
 	})
 
-	return hasLoginForm
+	return forms
+}
+
+// classifyForm scores the evidence for every kind and returns the best one.
+func classifyForm(selection *goquery.Selection, fields []FormField) (string, float64) {
+	scores := make(map[string]float64)
+
+	// Texts that describe the form: submit buttons, headings, legends, and the form attributes
+	var texts []string
+	selection.Find("button, input[type='submit'], input[type='image'], legend, h1, h2, h3, h4, label").Each(
+		func(index int, item *goquery.Selection) {
+			texts = append(texts, item.Text(), item.AttrOr("value", ""), item.AttrOr("aria-label", ""))
+		},
+	)
+	for _, attr := range []string{"action", "id", "class", "name", "aria-label", "role"} {
+		texts = append(texts, selection.AttrOr(attr, ""))
+	}
+	text := strings.ToLower(normalizeSpace(strings.Join(texts, " ")))
+
+	var (
+		passwordNum, visibleNum, usernameNum, emailNum int
+		hasCurrentPassword, hasNewPassword             bool
+	)
+	for _, field := range fields {
+		name := strings.ToLower(field.Name)
+
+		if field.Type != "hidden" {
+			visibleNum++
+		}
+
+		switch {
+		case field.Type == "password":
+			passwordNum++
+			switch field.Autocomplete {
+			case "current-password":
+				hasCurrentPassword = true
+				scores[FormKindLogin] += 3
+			case "new-password":
+				hasNewPassword = true
+				scores[FormKindSignup] += 3
+			}
+		case field.Type == "search" || searchFieldRegex.MatchString(name):
+			scores[FormKindSearch] += 3
+		case field.Type == "email" || strings.Contains(name, "email"):
+			emailNum++
+		case field.Autocomplete == "username" || (field.Type == "text" && usernameFieldRegex.MatchString(name)):
+			usernameNum++
+		}
+
+		if strings.HasPrefix(field.Autocomplete, "cc-") || paymentFieldRegex.MatchString(name) {
+			scores[FormKindPayment] += 3
+		}
+		if signupFieldRegex.MatchString(name) {
+			scores[FormKindSignup]++
+		}
+	}
+
+	// Changing the password is neither a login nor a signup
+	if hasCurrentPassword && hasNewPassword {
+		return FormKindOther, 0.9
+	}
+
+	switch {
+	case passwordNum == 1:
+		scores[FormKindLogin] += 3
+	case passwordNum > 1:
+		// Password and confirmation, or current and new password
+		scores[FormKindSignup] += 3
+	}
+
+	// Multi-step logins ask only for the username first
+	if passwordNum == 0 && visibleNum == 1 && usernameNum+emailNum == 1 &&
+		(loginKeywordsRegex.MatchString(text) || nextStepKeywordsRegex.MatchString(text)) {
+		scores[FormKindLogin] += 3
+	}
+
+	// A single email field is usually a newsletter subscription
+	if passwordNum == 0 && visibleNum == 1 && emailNum == 1 {
+		scores[FormKindNewsletter] += 2
+	}
+
+	if loginKeywordsRegex.MatchString(text) {
+		scores[FormKindLogin] += 2
+	}
+	// Login forms rarely mention signing up, so it outweighs the login keywords
+	if signupKeywordsRegex.MatchString(text) {
+		scores[FormKindSignup] += 3
+	}
+	if searchKeywordsRegex.MatchString(text) {
+		scores[FormKindSearch] += 2
+	}
+	if newsletterKeywordsRegex.MatchString(text) {
+		scores[FormKindNewsletter] += 2
+	}
+	if paymentKeywordsRegex.MatchString(text) {
+		scores[FormKindPayment] += 2
+	}
+
+	// Pick the best kind in a fixed order so ties are deterministic
+	bestKind, bestScore, totalScore := FormKindOther, 0.0, 0.0
+	for _, kind := range []string{FormKindLogin, FormKindSignup, FormKindPayment, FormKindSearch, FormKindNewsletter} {
+		totalScore += scores[kind]
+		if scores[kind] > bestScore {
+			bestKind, bestScore = kind, scores[kind]
+		}
+	}
+
+	// The confidence is the share of the evidence for the best kind, scaled down when there is little evidence
+	const strongEvidenceScore = 5
+	if bestScore < 3 {
+		return FormKindOther, 0.5
+	}
+	confidence := bestScore / totalScore * math.Min(1, bestScore/strongEvidenceScore)
+
+	return bestKind, math.Round(confidence*100) / 100
 }
@@ -51,3 +51,153 @@ func TestHasLoginForm(t *testing.T) {
 	assert.True(t, extractorWithLogin.HasLoginForm())
 	assert.False(t, extractorWithoutLogin.HasLoginForm())
 }
+
+func TestHasLoginFormMultiStep(t *testing.T) {
+	htmlContent := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<title>Test Page</title>
+		</head>
+		<body>
+			<form action="/identifier" method="post">
+				<input type="email" name="identifier" autocomplete="username">
+				<button type="submit">Next</button>
+			</form>
+		</body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	assert.True(t, extractor.HasLoginForm())
+}
+
+func TestForms(t *testing.T) {
+	tests := []struct {
+		name         string
+		formContent  string
+		expectedKind string
+	}{
+		{
+			name: "Login",
+			formContent: `
+				<form action="/session" method="post">
+					<input type="text" name="username" autocomplete="username">
+					<input type="password" name="password" autocomplete="current-password">
+					<button type="submit">Sign in</button>
+				</form>
+			`,
+			expectedKind: FormKindLogin,
+		},
+		{
+			name: "Signup",
+			formContent: `
+				<form action="/users" method="post">
+					<input type="text" name="full_name">
+					<input type="email" name="email">
+					<input type="password" name="password">
+					<button type="submit">Create account</button>
+				</form>
+			`,
+			expectedKind: FormKindSignup,
+		},
+		{
+			name: "Password change",
+			formContent: `
+				<form action="/password" method="post">
+					<input type="password" name="current" autocomplete="current-password">
+					<input type="password" name="new" autocomplete="new-password">
+					<input type="password" name="confirm" autocomplete="new-password">
+					<button type="submit">Change password</button>
+				</form>
+			`,
+			expectedKind: FormKindOther,
+		},
+		{
+			name: "Search",
+			formContent: `
+				<form action="/search" role="search">
+					<input type="search" name="q">
+				</form>
+			`,
+			expectedKind: FormKindSearch,
+		},
+		{
+			name: "Newsletter",
+			formContent: `
+				<form action="/subscribe" method="post">
+					<input type="email" name="email">
+					<button type="submit">Subscribe</button>
+				</form>
+			`,
+			expectedKind: FormKindNewsletter,
+		},
+		{
+			name: "Payment",
+			formContent: `
+				<form action="/checkout" method="post">
+					<input type="text" name="cardnumber" autocomplete="cc-number">
+					<input type="text" name="cvc" autocomplete="cc-csc">
+					<button type="submit">Pay</button>
+				</form>
+			`,
+			expectedKind: FormKindPayment,
+		},
+		{
+			name: "Other",
+			formContent: `
+				<form action="/feedback" method="post">
+					<textarea name="message"></textarea>
+					<input type="submit" value="Send">
+				</form>
+			`,
+			expectedKind: FormKindOther,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor, err := New([]byte("<html><body>" + tt.formContent + "</body></html>"))
+			if err != nil {
+				t.Fatalf("failed to create extractor: %v", err)
+			}
+
+			forms := extractor.Forms()
+			assert.Len(t, forms, 1)
+			assert.Equal(t, tt.expectedKind, forms[0].Kind)
+			assert.Greater(t, forms[0].Confidence, 0.0)
+			assert.Equal(t, tt.expectedKind == FormKindLogin, extractor.HasLoginForm())
+		})
+	}
+}
+
+func TestFormFields(t *testing.T) {
+	extractor, err := New([]byte(`
+		<html><body>
+			<form action="/session" method="post">
+				<input type="hidden" name="csrf">
+				<input name="username" autocomplete="username">
+				<select name="domain"></select>
+				<input type="submit" value="Login">
+			</form>
+		</body></html>
+	`))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	forms := extractor.Forms()
+	assert.Len(t, forms, 1)
+	assert.Equal(t, "/session", forms[0].Action)
+	assert.Equal(t, "POST", forms[0].Method)
+	assert.Equal(t, "html > body > form", forms[0].Path)
+	assert.Equal(t, []FormField{
+		{Tag: "input", Type: "hidden", Name: "csrf"},
+		{Tag: "input", Type: "text", Name: "username", Autocomplete: "username", HasAutocomplete: true},
+		{Tag: "select", Type: "select", Name: "domain"},
+	}, forms[0].Fields)
+}
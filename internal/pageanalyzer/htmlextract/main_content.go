@@ -0,0 +1,359 @@
+package htmlextract
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// The scoring follows the Readability algorithm: paragraphs award points to their parent and grandparent, the
+// candidates are weighted by tag, class and id, and penalised by their link density.
+var (
+	// unlikelyCandidateRegex matches the class and id of boilerplate blocks such as menus, banners and comments.
+	unlikelyCandidateRegex = regexp.MustCompile(`(?i)ad-break|agegate|banner|breadcrumb|combx|comment|community|consent|cookie|disqus|extra|footer|gdpr|menu|modal|newsletter|pager|pagination|popup|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|supplemental`)
+
+	// maybeCandidateRegex rescues blocks whose class or id also looks like content.
+	maybeCandidateRegex = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+
+	positiveWeightRegex = regexp.MustCompile(`(?i)article|blog|body|content|entry|h-entry|hentry|main|page|post|story|text`)
+	negativeWeightRegex = regexp.MustCompile(`(?i)-ad-|banner|combx|comment|com-|contact|consent|cookie|foot|gdpr|hidden|masthead|meta|modal|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|tags|tool|widget`)
+
+	bylineRegex = regexp.MustCompile(`(?i)byline|author|writtenby`)
+)
+
+// boilerplateElements never hold main content. Forms are removed conditionally, some frameworks such as ASP.NET
+// WebForms wrap the whole page in one.
+var boilerplateElements = []string{
+	"nav", "aside", "footer", "dialog", "button", "input", "select", "textarea", "script", "style",
+	"noscript", "template", "iframe", "object", "embed",
+}
+
+// scoredElements are the elements whose text awards points to the enclosing blocks.
+var scoredElements = []string{"p", "pre", "td", "blockquote", "section", "div"}
+
+// blockElements stop a div or section from being scored as a paragraph of its own.
+var blockElements = []string{
+	"address", "article", "blockquote", "div", "dl", "figure", "h1", "h2", "h3", "h4", "h5", "h6", "ol", "p",
+	"pre", "section", "table", "ul",
+}
+
+const (
+	// minScoredTextLen is the shortest paragraph that awards points.
+	minScoredTextLen = 25
+
+	// maxBylineLen is the longest text accepted as a byline.
+	maxBylineLen = 100
+
+	// minFormTextPerField is the text a form needs per field to be kept as content rather than a search, login or
+	// comment form.
+	minFormTextPerField = 200
+)
+
+// MainContent is the article body of the page with navigation, footers, banners and other boilerplate removed.
+type MainContent struct {
+	Title     string
+	Byline    string
+	LeadImage string // The src of the image, unresolved
+	Text      string // Text blocks separated by blank lines
+	HTML      string
+}
+
+// MainContent isolates the article body of the page.
+// It returns nil if no block of the page looks like text content.
+func (h *HTMLExtractor) MainContent() *MainContent {
+	// The document is modified while scoring, so work on a copy
+	doc := goquery.CloneDocument(h.goQueryDoc)
+	body := doc.Find("body")
+
+	removeBoilerplate(body)
+	byline := h.byline(body)
+
+	content := topCandidateContent(body)
+	if content == nil {
+		return nil
+	}
+	cleanContent(content)
+
+	text := strings.Join(textBlocks(content.Nodes), "\n\n")
+	if text == "" {
+		return nil
+	}
+
+	contentHTML, _ := content.Html()
+
+	return &MainContent{
+		Title:     h.mainContentTitle(content),
+		Byline:    byline,
+		LeadImage: h.leadImage(content),
+		Text:      text,
+		HTML:      strings.TrimSpace(contentHTML),
+	}
+}
+
+// removeBoilerplate drops the elements that never hold content and the blocks that look like boilerplate.
+func removeBoilerplate(body *goquery.Selection) {
+	// The fields of the forms are counted before they are removed
+	body.Find("form").Each(func(index int, item *goquery.Selection) {
+		if isBoilerplateForm(item) {
+			item.Remove()
+		}
+	})
+	body.Find(strings.Join(boilerplateElements, ", ")).Remove()
+
+	// A header inside the article usually holds its title and byline
+	body.Find("header").Each(func(index int, item *goquery.Selection) {
+		if item.Closest("article, main").Length() == 0 {
+			item.Remove()
+		}
+	})
+
+	body.Find("*").Each(func(index int, item *goquery.Selection) {
+		switch goquery.NodeName(item) {
+		case "body", "html", "article", "main", "a":
+			return
+		}
+		if item.Closest("body").Length() == 0 {
+			// Already removed with an ancestor
+			return
+		}
+
+		classAndID := item.AttrOr("class", "") + " " + item.AttrOr("id", "")
+		if unlikelyCandidateRegex.MatchString(classAndID) && !maybeCandidateRegex.MatchString(classAndID) {
+			item.Remove()
+			return
+		}
+
+		if role := item.AttrOr("role", ""); role == "navigation" || role == "banner" || role == "contentinfo" ||
+			role == "complementary" || role == "dialog" || role == "alertdialog" {
+			item.Remove()
+			return
+		}
+
+		if isHiddenNode(item.Get(0)) {
+			item.Remove()
+		}
+	})
+}
+
+// isBoilerplateForm reports whether the form is mostly links or fields, as search, login and comment forms are,
+// rather than a form wrapping the content of the page.
+func isBoilerplateForm(form *goquery.Selection) bool {
+	if linkDensity(form) > 0.5 {
+		return true
+	}
+
+	// Hidden inputs and buttons are not fields the visitor fills in
+	fields := form.Find(`input:not([type="hidden"]):not([type="submit"]):not([type="button"]):not([type="image"]):not([type="reset"]), select, textarea`).Length()
+	return fields > 0 && len(normalizeSpace(form.Text())) < fields*minFormTextPerField
+}
+
+// topCandidateContent scores the blocks of the body and returns a wrapper holding the best candidate and the
+// siblings that belong to it.
+func topCandidateContent(body *goquery.Selection) *goquery.Selection {
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+
+	addScore := func(node *html.Node, score float64) {
+		if node == nil || node.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[node]; !ok {
+			scores[node] = initialScore(node)
+			candidates = append(candidates, node)
+		}
+		scores[node] += score
+	}
+
+	body.Find(strings.Join(scoredElements, ", ")).Each(func(index int, item *goquery.Selection) {
+		name := goquery.NodeName(item)
+		if (name == "div" || name == "section") && item.ChildrenFiltered(strings.Join(blockElements, ", ")).Length() > 0 {
+			return
+		}
+
+		text := normalizeSpace(item.Text())
+		if len(text) < minScoredTextLen {
+			return
+		}
+
+		// One point for the paragraph, one per comma and one per 100 characters, up to three
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text)/100), 3)
+
+		node := item.Get(0)
+		addScore(node.Parent, score)
+		if node.Parent != nil {
+			addScore(node.Parent.Parent, score/2)
+		}
+	})
+
+	var top *html.Node
+	for _, node := range candidates {
+		scores[node] *= 1 - linkDensity(goquery.NewDocumentFromNode(node).Selection)
+		if top == nil || scores[node] > scores[top] {
+			top = node
+		}
+	}
+	if top == nil || top.Parent == nil {
+		return nil
+	}
+
+	wrapper := goquery.NewDocumentFromNode(&html.Node{Type: html.ElementNode, Data: "div"}).Selection
+
+	// Siblings of the top candidate often hold the rest of the article, e.g. a lead paragraph or a related image
+	threshold := math.Max(10, scores[top]*0.2)
+	for sibling := top.Parent.FirstChild; sibling != nil; {
+		next := sibling.NextSibling
+		if sibling == top || sibling.Type == html.ElementNode && isContentSibling(sibling, scores, threshold) {
+			sibling.Parent.RemoveChild(sibling)
+			wrapper.Get(0).AppendChild(sibling)
+		}
+		sibling = next
+	}
+
+	return wrapper
+}
+
+// isContentSibling reports whether a sibling of the top candidate belongs to the main content.
+func isContentSibling(node *html.Node, scores map[*html.Node]float64, threshold float64) bool {
+	if score, ok := scores[node]; ok && score >= threshold {
+		return true
+	}
+	if node.Data != "p" {
+		return false
+	}
+
+	item := goquery.NewDocumentFromNode(node).Selection
+	text := normalizeSpace(item.Text())
+	density := linkDensity(item)
+
+	return len(text) > 80 && density < 0.25 || len(text) > 0 && density == 0 && strings.Contains(text, ". ")
+}
+
+// initialScore weights a candidate by its tag, class and id.
+func initialScore(node *html.Node) float64 {
+	var score float64
+	switch node.Data {
+	case "article":
+		score = 10
+	case "div", "main":
+		score = 5
+	case "pre", "td", "blockquote":
+		score = 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li":
+		score = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score = -5
+	}
+
+	for _, attr := range node.Attr {
+		if attr.Key != "class" && attr.Key != "id" {
+			continue
+		}
+		if negativeWeightRegex.MatchString(attr.Val) {
+			score -= 25
+		}
+		if positiveWeightRegex.MatchString(attr.Val) {
+			score += 25
+		}
+	}
+
+	return score
+}
+
+// linkDensity returns the share of the text of the selection that is link text.
+func linkDensity(item *goquery.Selection) float64 {
+	textLen := len(normalizeSpace(item.Text()))
+	if textLen == 0 {
+		return 0
+	}
+
+	var linkLen int
+	item.Find("a").Each(func(index int, link *goquery.Selection) {
+		linkLen += len(normalizeSpace(link.Text()))
+	})
+
+	return float64(linkLen) / float64(textLen)
+}
+
+// cleanContent removes the link lists and empty blocks that are left inside the blocks of the main content.
+func cleanContent(content *goquery.Selection) {
+	content.Children().Find("ul, ol, div, section, table").Each(func(index int, item *goquery.Selection) {
+		text := normalizeSpace(item.Text())
+		if text == "" && item.Find("img, picture, video").Length() == 0 {
+			item.Remove()
+			return
+		}
+		if linkDensity(item) > 0.5 && len(text) < 200*(1+strings.Count(text, ",")) {
+			item.Remove()
+		}
+	})
+}
+
+// mainContentTitle returns the title of the article: the og:title, the only h1 of the content or the page title
+// without the site name.
+func (h *HTMLExtractor) mainContentTitle(content *goquery.Selection) string {
+	if title := normalizeSpace(h.goQueryDoc.Find(`meta[property="og:title"]`).AttrOr("content", "")); title != "" {
+		return title
+	}
+
+	if h1 := content.Find("h1"); h1.Length() == 1 {
+		if title := normalizeSpace(h1.Text()); title != "" {
+			return title
+		}
+	}
+
+	title := normalizeSpace(h.Title())
+	for _, separator := range []string{" | ", " - ", " – ", " — ", " :: "} {
+		if index := strings.LastIndex(title, separator); index > 0 && len(strings.Fields(title[:index])) >= 3 {
+			return title[:index]
+		}
+	}
+
+	return title
+}
+
+// byline returns the author of the article, taken from the markup of the body left after removing the boilerplate
+// or from the author meta tag.
+func (h *HTMLExtractor) byline(body *goquery.Selection) string {
+	var byline string
+
+	body.Find(`[rel~="author"], [itemprop~="author"], [class], [id]`).EachWithBreak(func(index int, item *goquery.Selection) bool {
+		if !item.Is(`[rel~="author"], [itemprop~="author"]`) && !bylineRegex.MatchString(item.AttrOr("class", "")+" "+item.AttrOr("id", "")) {
+			return true
+		}
+
+		text := normalizeSpace(item.Text())
+		if text == "" || len(text) > maxBylineLen {
+			return true
+		}
+
+		byline = text
+		return false
+	})
+	if byline != "" {
+		return byline
+	}
+
+	return normalizeSpace(h.goQueryDoc.Find(`meta[name="author"]`).AttrOr("content", ""))
+}
+
+// leadImage returns the og:image of the page or the first image of the main content.
+func (h *HTMLExtractor) leadImage(content *goquery.Selection) string {
+	if image := strings.TrimSpace(h.goQueryDoc.Find(`meta[property="og:image"]`).AttrOr("content", "")); image != "" {
+		return image
+	}
+
+	var image string
+	content.Find("img[src]").EachWithBreak(func(index int, item *goquery.Selection) bool {
+		if role := item.AttrOr("role", ""); isPixel(item) || role == "presentation" || role == "none" {
+			return true
+		}
+
+		image = strings.TrimSpace(item.AttrOr("src", ""))
+		return image == ""
+	})
+
+	return image
+}
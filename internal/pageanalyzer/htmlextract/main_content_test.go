@@ -0,0 +1,174 @@
+package htmlextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMainContent(t *testing.T) {
+	htmlContent := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<title>How to brew better coffee at home | The Coffee Blog</title>
+			<meta name="author" content="Meta Author">
+		</head>
+		<body>
+			<header><a href="/">The Coffee Blog</a></header>
+			<nav><ul><li><a href="/">Home</a></li><li><a href="/about">About</a></li></ul></nav>
+			<div class="cookie-banner">We use cookies to improve your experience, please accept them.</div>
+			<div id="content">
+				<article class="post">
+					<header>
+						<h1>How to brew better coffee</h1>
+						<p class="byline">By Jane Doe</p>
+					</header>
+					<p>Good coffee starts with fresh beans, roasted within the last few weeks, and ground right before brewing.</p>
+					<img src="/images/beans.jpg" alt="Coffee beans">
+					<p>Use filtered water, heated to just below boiling, and weigh both the coffee and the water for consistent results.</p>
+					<ul class="related"><li><a href="/tea">Brewing tea</a></li><li><a href="/milk">Frothing milk</a></li></ul>
+					<p>Finally, clean your equipment regularly, because old oils make the coffee taste bitter and stale.</p>
+				</article>
+				<aside><p>Subscribe to our newsletter for weekly tips, recipes, and exclusive offers from our partners.</p></aside>
+			</div>
+			<footer><p>Copyright, all rights reserved, The Coffee Blog, 2024, made with love in Berlin.</p></footer>
+		</body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	mainContent := extractor.MainContent()
+	if !assert.NotNil(t, mainContent) {
+		return
+	}
+
+	assert.Equal(t, "How to brew better coffee", mainContent.Title)
+	assert.Equal(t, "By Jane Doe", mainContent.Byline)
+	assert.Equal(t, "/images/beans.jpg", mainContent.LeadImage)
+	assert.Equal(t, "How to brew better coffee\n\n"+
+		"By Jane Doe\n\n"+
+		"Good coffee starts with fresh beans, roasted within the last few weeks, and ground right before brewing.\n\n"+
+		"Use filtered water, heated to just below boiling, and weigh both the coffee and the water for consistent results.\n\n"+
+		"Finally, clean your equipment regularly, because old oils make the coffee taste bitter and stale.",
+		mainContent.Text)
+	assert.Contains(t, mainContent.HTML, `<img src="/images/beans.jpg" alt="Coffee beans"/>`)
+	assert.NotContains(t, mainContent.HTML, "cookies")
+	assert.NotContains(t, mainContent.HTML, "newsletter")
+
+	// The extractor of the main content sees only the article
+	contentExtractor, err := New([]byte(mainContent.HTML))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+	assert.Equal(t, []Heading{{Level: 1, Text: "How to brew better coffee"}}, contentExtractor.Headings())
+}
+
+func TestMainContent_TitleAndLeadImageFallbacks(t *testing.T) {
+	htmlContent := `
+		<html>
+		<head>
+			<title>Ten tips for remote work - Example News</title>
+			<meta property="og:image" content="https://example.com/lead.jpg">
+		</head>
+		<body>
+			<div class="main">
+				<p>Working from home, for many of us, has become the norm rather than the exception in recent years.</p>
+				<p>Set up a dedicated workspace, keep regular hours, and take breaks to stay focused and productive.</p>
+			</div>
+		</body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	mainContent := extractor.MainContent()
+	if !assert.NotNil(t, mainContent) {
+		return
+	}
+
+	assert.Equal(t, "Ten tips for remote work", mainContent.Title)
+	assert.Equal(t, "", mainContent.Byline)
+	assert.Equal(t, "https://example.com/lead.jpg", mainContent.LeadImage)
+}
+
+func TestMainContent_NoContent(t *testing.T) {
+	extractor, err := New([]byte(`<html><body><nav><a href="/">Home</a></nav><p>Short.</p></body></html>`))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	assert.Nil(t, extractor.MainContent())
+}
+
+func TestMainContent_Forms(t *testing.T) {
+	htmlContent := `
+		<html>
+		<body>
+			<form method="post" action="./article.aspx" id="form1">
+				<input type="hidden" name="__VIEWSTATE" value="dDwtMTA4MzE0MjEwNTs7Pg==">
+				<div class="header"><input type="text" name="q"><input type="submit" value="Search"></div>
+				<div class="article">
+					<p>Good coffee starts with fresh beans, roasted within the last few weeks, and ground right before brewing.</p>
+					<p>Use filtered water, heated to just below boiling, and weigh both the coffee and the water for consistent results.</p>
+				</div>
+			</form>
+			<form action="/login">
+				<label>Username <input type="text" name="username"></label>
+				<label>Password <input type="password" name="password"></label>
+				<p>Log in to save your favourite recipes, brewing notes and equipment lists.</p>
+			</form>
+		</body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	mainContent := extractor.MainContent()
+	if !assert.NotNil(t, mainContent) {
+		return
+	}
+
+	assert.Equal(t, "Good coffee starts with fresh beans, roasted within the last few weeks, and ground right before brewing.\n\n"+
+		"Use filtered water, heated to just below boiling, and weigh both the coffee and the water for consistent results.",
+		mainContent.Text)
+	assert.NotContains(t, mainContent.HTML, "Log in")
+}
+
+func TestMainContent_BylineOutsideBoilerplate(t *testing.T) {
+	htmlContent := `
+		<html>
+		<head><meta name="author" content="Meta Author"></head>
+		<body>
+			<aside><a rel="author" href="/authors/john">John Smith</a></aside>
+			<div class="sidebar"><span class="author">Guest Author</span></div>
+			<article>
+				<p>Good coffee starts with fresh beans, roasted within the last few weeks, and ground right before brewing.</p>
+				<p>Use filtered water, heated to just below boiling, and weigh both the coffee and the water for consistent results.</p>
+			</article>
+		</body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	mainContent := extractor.MainContent()
+	if !assert.NotNil(t, mainContent) {
+		return
+	}
+
+	assert.Equal(t, "Meta Author", mainContent.Byline)
+}
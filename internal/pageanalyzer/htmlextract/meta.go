@@ -0,0 +1,23 @@
+package htmlextract
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// MetaTags returns the content of the named meta tags, keyed by the lowercase name, in document order.
+func (h *HTMLExtractor) MetaTags() map[string][]string {
+	metaTags := make(map[string][]string)
+
+	h.goQueryDoc.Find("meta[name][content]").Each(func(index int, item *goquery.Selection) {
+		name := strings.ToLower(strings.TrimSpace(item.AttrOr("name", "")))
+		if name == "" {
+			return
+		}
+
+		metaTags[name] = append(metaTags[name], strings.TrimSpace(item.AttrOr("content", "")))
+	})
+
+	return metaTags
+}
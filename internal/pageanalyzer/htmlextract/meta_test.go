@@ -0,0 +1,36 @@
+package htmlextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetaTags(t *testing.T) {
+	htmlContent := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<meta charset="utf-8">
+			<meta name="Generator" content=" WordPress 6.4.2 ">
+			<meta name="generator" content="WooCommerce 8.5">
+			<meta name="description" content="A test page">
+			<meta property="og:title" content="Not named">
+			<meta name="empty">
+		</head>
+		<body></body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	expected := map[string][]string{
+		"generator":   {"WordPress 6.4.2", "WooCommerce 8.5"},
+		"description": {"A test page"},
+	}
+
+	assert.Equal(t, expected, extractor.MetaTags())
+}
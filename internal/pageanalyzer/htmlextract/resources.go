@@ -0,0 +1,208 @@
+package htmlextract
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/exp/slices"
+)
+
+// Resource types
+const (
+	ResourceTypeImage      = "image"
+	ResourceTypeScript     = "script"
+	ResourceTypeStylesheet = "stylesheet"
+	ResourceTypeIFrame     = "iframe"
+	ResourceTypeMedia      = "media"
+	ResourceTypeEmbed      = "embed"
+	ResourceTypeIcon       = "icon"
+	ResourceTypePreload    = "preload"
+	ResourceTypeFormAction = "form action"
+	ResourceTypeArea       = "area"
+)
+
+// ResourceTypes is the order resource types are reported in.
+var ResourceTypes = []string{
+	ResourceTypeImage, ResourceTypeScript, ResourceTypeStylesheet, ResourceTypeIFrame, ResourceTypeMedia,
+	ResourceTypeEmbed, ResourceTypeIcon, ResourceTypePreload, ResourceTypeFormAction, ResourceTypeArea,
+}
+
+// Resource is a URL referenced by an element of the page, other than <a href>.
+type Resource struct {
+	Type string
+	URL  string
+	// Element and Attr tell where the URL was found, e.g. "img" and "srcset".
+	Element string
+	Attr    string
+	// Pixel is set for images of at most 1x1 pixels, which are usually tracking pixels.
+	Pixel bool
+}
+
+// resourceSource describes an element attribute that references a resource.
+type resourceSource struct {
+	selector     string
+	attr         string
+	resourceType string
+	isSrcset     bool
+}
+
+var resourceSources = []resourceSource{
+	{selector: "img[src]", attr: "src", resourceType: ResourceTypeImage},
+	{selector: "img[srcset]", attr: "srcset", resourceType: ResourceTypeImage, isSrcset: true},
+	{selector: "picture source[srcset]", attr: "srcset", resourceType: ResourceTypeImage, isSrcset: true},
+	{selector: "input[type='image'][src]", attr: "src", resourceType: ResourceTypeImage},
+	{selector: "video[poster]", attr: "poster", resourceType: ResourceTypeImage},
+	{selector: "script[src]", attr: "src", resourceType: ResourceTypeScript},
+	{selector: "iframe[src], frame[src]", attr: "src", resourceType: ResourceTypeIFrame},
+	{selector: "video[src], audio[src], video source[src], audio source[src], track[src]", attr: "src", resourceType: ResourceTypeMedia},
+	{selector: "embed[src]", attr: "src", resourceType: ResourceTypeEmbed},
+	{selector: "object[data]", attr: "data", resourceType: ResourceTypeEmbed},
+	{selector: "form[action]", attr: "action", resourceType: ResourceTypeFormAction},
+	{selector: "area[href]", attr: "href", resourceType: ResourceTypeArea},
+}
+
+// preloadRels are the <link> types that make the browser fetch the resource ahead of time.
+var preloadRels = []string{"preload", "modulepreload", "prefetch"}
+
+// Resources returns the resources referenced by the page, in the order of ResourceTypes.
+func (h *HTMLExtractor) Resources() []Resource {
+	resources := resourcesIn(h.goQueryDoc.Selection)
+
+	// The parser keeps the content of <noscript> as text, but browsers without scripts load its resources,
+	// e.g. the fallback pixels of analytics snippets
+	h.goQueryDoc.Find("noscript").Each(func(index int, item *goquery.Selection) {
+		noscriptDoc, err := goquery.NewDocumentFromReader(strings.NewReader(item.Text()))
+		if err != nil {
+			return
+		}
+		resources = append(resources, resourcesIn(noscriptDoc.Selection)...)
+	})
+
+	// Keep the resources grouped by type
+	slices.SortStableFunc(resources, func(a, b Resource) int {
+		return slices.Index(ResourceTypes, a.Type) - slices.Index(ResourceTypes, b.Type)
+	})
+
+	return resources
+}
+
+func resourcesIn(root *goquery.Selection) []Resource {
+	var resources []Resource
+
+	add := func(resourceType string, item *goquery.Selection, attr, url string) {
+		url = strings.TrimSpace(url)
+		if url == "" {
+			return
+		}
+		resources = append(resources, Resource{
+			Type:    resourceType,
+			URL:     url,
+			Element: goquery.NodeName(item),
+			Attr:    attr,
+			Pixel:   goquery.NodeName(item) == "img" && isPixel(item),
+		})
+	}
+
+	for _, source := range resourceSources {
+		root.Find(source.selector).Each(func(index int, item *goquery.Selection) {
+			value := item.AttrOr(source.attr, "")
+			if !source.isSrcset {
+				add(source.resourceType, item, source.attr, value)
+				return
+			}
+			for _, candidate := range srcsetURLs(value) {
+				add(source.resourceType, item, source.attr, candidate)
+			}
+		})
+	}
+
+	root.Find("link[href], link[imagesrcset]").Each(func(index int, item *goquery.Selection) {
+		rels := strings.Fields(strings.ToLower(item.AttrOr("rel", "")))
+		href := item.AttrOr("href", "")
+
+		switch {
+		case slices.Contains(rels, "stylesheet"):
+			add(ResourceTypeStylesheet, item, "href", href)
+		case slices.Contains(rels, "icon") || slices.Contains(rels, "apple-touch-icon"):
+			add(ResourceTypeIcon, item, "href", href)
+		case slices.ContainsFunc(rels, func(rel string) bool { return slices.Contains(preloadRels, rel) }):
+			add(ResourceTypePreload, item, "href", href)
+		}
+
+		// Responsive preloads list their candidates in imagesrcset
+		if srcset, ok := item.Attr("imagesrcset"); ok {
+			for _, candidate := range srcsetURLs(srcset) {
+				add(ResourceTypePreload, item, "imagesrcset", candidate)
+			}
+		}
+	})
+
+	return resources
+}
+
+// isPixel reports whether the image is sized to at most 1x1 pixels.
+func isPixel(img *goquery.Selection) bool {
+	for _, attr := range []string{"width", "height"} {
+		size, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(img.AttrOr(attr, "")), "px"))
+		if err != nil || size > 1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// srcsetURLs returns the candidate URLs of a srcset attribute, e.g. "a.png 1x, b.png 2x".
+func srcsetURLs(srcset string) []string {
+	var urls []string
+	for _, candidate := range parseSrcset(srcset) {
+		urls = append(urls, candidate.url)
+	}
+
+	return urls
+}
+
+// srcsetCandidate is an image candidate of a srcset attribute.
+type srcsetCandidate struct {
+	url string
+	// descriptor is the width or pixel density descriptor, e.g. "800w" or "2x", empty if omitted
+	descriptor string
+}
+
+// parseSrcset returns the candidates of a srcset attribute, e.g. "a.png 1x, b.png 2x".
+func parseSrcset(srcset string) []srcsetCandidate {
+	var candidates []srcsetCandidate
+
+	for rest := srcset; ; {
+		// Skip separators between candidates
+		rest = strings.TrimLeftFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == ',' })
+		if rest == "" {
+			return candidates
+		}
+
+		// The URL runs until whitespace, a trailing comma ends the candidate without descriptors
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end == -1 {
+			end = len(rest)
+		}
+		url := rest[:end]
+		rest = rest[end:]
+
+		var descriptor string
+		if strings.HasSuffix(url, ",") {
+			url = strings.TrimRight(url, ",")
+		} else if comma := strings.IndexByte(rest, ','); comma != -1 {
+			descriptor = rest[:comma]
+			rest = rest[comma:]
+		} else {
+			descriptor = rest
+			rest = ""
+		}
+
+		if url != "" {
+			candidates = append(candidates, srcsetCandidate{url: url, descriptor: strings.TrimSpace(descriptor)})
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package htmlextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResources(t *testing.T) {
+	htmlContent := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<title>Test Page</title>
+			<link rel="stylesheet" href="/style.css">
+			<link rel="icon" href="/favicon.ico">
+			<link rel="preload" href="/font.woff2" as="font">
+			<link rel="preload" as="image" imagesrcset="/hero-1x.png 1x, /hero-2x.png 2x">
+			<link rel="canonical" href="https://example.com/">
+			<script src="/app.js"></script>
+			<script>inline()</script>
+		</head>
+		<body>
+			<img src="/logo.png" srcset="/logo-1x.png 1x,/logo-2x.png 2x">
+			<picture>
+				<source srcset="/photo.webp" type="image/webp">
+				<img src="/photo.jpg">
+			</picture>
+			<iframe src="https://video.example.com/embed"></iframe>
+			<video src="/movie.mp4" poster="/poster.png"><track src="/subs.vtt"></video>
+			<form action="/search"></form>
+			<map><area href="/region" alt="Region"></map>
+			<img src="">
+			<noscript><img height="1" width="1" src="https://tracker.example.org/tr?id=1"></noscript>
+		</body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	expectedResources := []Resource{
+		{Type: ResourceTypeImage, URL: "/logo.png", Element: "img", Attr: "src"},
+		{Type: ResourceTypeImage, URL: "/photo.jpg", Element: "img", Attr: "src"},
+		{Type: ResourceTypeImage, URL: "/logo-1x.png", Element: "img", Attr: "srcset"},
+		{Type: ResourceTypeImage, URL: "/logo-2x.png", Element: "img", Attr: "srcset"},
+		{Type: ResourceTypeImage, URL: "/photo.webp", Element: "source", Attr: "srcset"},
+		{Type: ResourceTypeImage, URL: "/poster.png", Element: "video", Attr: "poster"},
+		{Type: ResourceTypeImage, URL: "https://tracker.example.org/tr?id=1", Element: "img", Attr: "src", Pixel: true},
+		{Type: ResourceTypeScript, URL: "/app.js", Element: "script", Attr: "src"},
+		{Type: ResourceTypeStylesheet, URL: "/style.css", Element: "link", Attr: "href"},
+		{Type: ResourceTypeIFrame, URL: "https://video.example.com/embed", Element: "iframe", Attr: "src"},
+		{Type: ResourceTypeMedia, URL: "/movie.mp4", Element: "video", Attr: "src"},
+		{Type: ResourceTypeMedia, URL: "/subs.vtt", Element: "track", Attr: "src"},
+		{Type: ResourceTypeIcon, URL: "/favicon.ico", Element: "link", Attr: "href"},
+		{Type: ResourceTypePreload, URL: "/font.woff2", Element: "link", Attr: "href"},
+		{Type: ResourceTypePreload, URL: "/hero-1x.png", Element: "link", Attr: "imagesrcset"},
+		{Type: ResourceTypePreload, URL: "/hero-2x.png", Element: "link", Attr: "imagesrcset"},
+		{Type: ResourceTypeFormAction, URL: "/search", Element: "form", Attr: "action"},
+		{Type: ResourceTypeArea, URL: "/region", Element: "area", Attr: "href"},
+	}
+
+	assert.Equal(t, expectedResources, extractor.Resources())
+}
+
+func TestSrcsetURLs(t *testing.T) {
+	tests := []struct {
+		name     string
+		srcset   string
+		expected []string
+	}{
+		{
+			name:     "Density descriptors",
+			srcset:   "a.png 1x, b.png 2x",
+			expected: []string{"a.png", "b.png"},
+		},
+		{
+			name:     "Width descriptors without spaces",
+			srcset:   " a.png 480w,b.png   800w ",
+			expected: []string{"a.png", "b.png"},
+		},
+		{
+			name:     "No descriptors",
+			srcset:   "a.png,b.png",
+			expected: []string{"a.png,b.png"},
+		},
+		{
+			name:     "Trailing comma",
+			srcset:   "a.png, b.png",
+			expected: []string{"a.png", "b.png"},
+		},
+		{
+			name:     "Data URL",
+			srcset:   "data:image/png;base64,AAAA 1x, b.png 2x",
+			expected: []string{"data:image/png;base64,AAAA", "b.png"},
+		},
+		{
+			name:     "Empty",
+			srcset:   "  ",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, srcsetURLs(tt.srcset))
+		})
+	}
+}
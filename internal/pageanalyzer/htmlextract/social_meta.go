@@ -0,0 +1,48 @@
+package htmlextract
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SocialMeta holds the OpenGraph (og:*) and Twitter Card (twitter:*) properties declared in the page's meta tags.
+// Only the first occurrence of every property is kept.
+type SocialMeta struct {
+	OpenGraph map[string]string
+	Twitter   map[string]string
+}
+
+func (h *HTMLExtractor) SocialMeta() SocialMeta {
+	socialMeta := SocialMeta{
+		OpenGraph: make(map[string]string),
+		Twitter:   make(map[string]string),
+	}
+
+	h.goQueryDoc.Find("meta[content]").Each(func(index int, item *goquery.Selection) {
+		content, _ := item.Attr("content")
+		content = strings.TrimSpace(content)
+
+		// OpenGraph uses the property attribute, but Twitter Cards are declared with either name or property.
+		for _, attr := range []string{"property", "name"} {
+			key, exists := item.Attr(attr)
+			if !exists {
+				continue
+			}
+			key = strings.ToLower(strings.TrimSpace(key))
+
+			switch {
+			case strings.HasPrefix(key, "og:"):
+				if _, ok := socialMeta.OpenGraph[key]; !ok {
+					socialMeta.OpenGraph[key] = content
+				}
+			case strings.HasPrefix(key, "twitter:"):
+				if _, ok := socialMeta.Twitter[key]; !ok {
+					socialMeta.Twitter[key] = content
+				}
+			}
+		}
+	})
+
+	return socialMeta
+}
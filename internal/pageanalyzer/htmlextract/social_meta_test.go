@@ -0,0 +1,41 @@
+package htmlextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSocialMeta(t *testing.T) {
+	htmlContent := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<title>Test Page</title>
+			<meta property="og:title" content="OG Title">
+			<meta property="og:title" content="Second OG Title">
+			<meta property="og:image" content=" https://example.com/image.png ">
+			<meta name="twitter:card" content="summary_large_image">
+			<meta property="twitter:site" content="@example">
+			<meta name="description" content="Not a social property">
+		</head>
+		<body></body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	socialMeta := extractor.SocialMeta()
+
+	assert.Equal(t, map[string]string{
+		"og:title": "OG Title",
+		"og:image": "https://example.com/image.png",
+	}, socialMeta.OpenGraph)
+	assert.Equal(t, map[string]string{
+		"twitter:card": "summary_large_image",
+		"twitter:site": "@example",
+	}, socialMeta.Twitter)
+}
@@ -0,0 +1,297 @@
+package htmlextract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Structured data formats
+const (
+	FormatJSONLD    = "JSON-LD"
+	FormatMicrodata = "Microdata"
+	FormatRDFa      = "RDFa"
+)
+
+// StructuredDataItem is a typed entity declared in the page, normalized from any of the supported formats.
+type StructuredDataItem struct {
+	Format string
+	// Types holds the item types with the schema.org prefix stripped, e.g. "Article".
+	Types      []string
+	ID         string
+	Properties map[string][]StructuredDataValue
+}
+
+// StructuredDataValue is a property value, either plain text or a nested item.
+type StructuredDataValue struct {
+	Text string
+	Item *StructuredDataItem
+}
+
+// StructuredData holds all items found in the page and the JSON-LD blocks that failed to parse.
+type StructuredData struct {
+	Items  []*StructuredDataItem
+	Errors []string
+}
+
+// HasType reports whether the item is of the given type.
+func (i *StructuredDataItem) HasType(itemType string) bool {
+	for _, t := range i.Types {
+		if t == itemType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *HTMLExtractor) StructuredData() StructuredData {
+	var structuredData StructuredData
+
+	// JSON-LD
+	h.goQueryDoc.Find(`script[type="application/ld+json"]`).Each(func(index int, item *goquery.Selection) {
+		items, err := parseJSONLD(item.Text())
+		if err != nil {
+			structuredData.Errors = append(structuredData.Errors, fmt.Sprintf("JSON-LD block %d: %v", index+1, err))
+			return
+		}
+		structuredData.Items = append(structuredData.Items, items...)
+	})
+
+	// Microdata, only top level items. Nested items are collected as property values.
+	h.goQueryDoc.Find("[itemscope]").Not("[itemprop]").Each(func(index int, item *goquery.Selection) {
+		structuredData.Items = append(structuredData.Items, parseMicrodataItem(item))
+	})
+
+	// RDFa, only top level items. Nested items are collected as property values.
+	h.goQueryDoc.Find("[typeof]").Not("[property]").Each(func(index int, item *goquery.Selection) {
+		structuredData.Items = append(structuredData.Items, parseRDFaItem(item))
+	})
+
+	return structuredData
+}
+
+func parseJSONLD(content string) ([]*StructuredDataItem, error) {
+	var data any
+	if err := json.Unmarshal([]byte(content), &data); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("syntax error at offset %d: %v", syntaxErr.Offset, syntaxErr)
+		}
+
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	var items []*StructuredDataItem
+
+	var collect func(data any)
+	collect = func(data any) {
+		switch data := data.(type) {
+		case []any:
+			for _, element := range data {
+				collect(element)
+			}
+		case map[string]any:
+			// A @graph holds a list of top level items sharing the same context
+			if graph, ok := data["@graph"]; ok {
+				collect(graph)
+				return
+			}
+			items = append(items, jsonLDItem(data))
+		}
+	}
+	collect(data)
+
+	return items, nil
+}
+
+func jsonLDItem(data map[string]any) *StructuredDataItem {
+	item := &StructuredDataItem{
+		Format:     FormatJSONLD,
+		Properties: make(map[string][]StructuredDataValue),
+	}
+
+	for key, value := range data {
+		switch key {
+		case "@type":
+			for _, value := range jsonLDValues(value) {
+				if value.Text != "" {
+					item.Types = append(item.Types, normalizeSchemaType(value.Text))
+				}
+			}
+		case "@id":
+			item.ID, _ = value.(string)
+		default:
+			// Skip other keywords such as @context
+			if strings.HasPrefix(key, "@") {
+				continue
+			}
+			item.Properties[key] = jsonLDValues(value)
+		}
+	}
+
+	return item
+}
+
+func jsonLDValues(value any) []StructuredDataValue {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		var values []StructuredDataValue
+		for _, element := range value {
+			values = append(values, jsonLDValues(element)...)
+		}
+		return values
+	case map[string]any:
+		// A value object carries its literal in @value
+		if literal, ok := value["@value"]; ok {
+			return jsonLDValues(literal)
+		}
+		return []StructuredDataValue{{Item: jsonLDItem(value)}}
+	case string:
+		return []StructuredDataValue{{Text: value}}
+	default:
+		return []StructuredDataValue{{Text: fmt.Sprint(value)}}
+	}
+}
+
+func parseMicrodataItem(selection *goquery.Selection) *StructuredDataItem {
+	item := &StructuredDataItem{
+		Format:     FormatMicrodata,
+		Types:      normalizeSchemaTypes(selection.AttrOr("itemtype", "")),
+		ID:         selection.AttrOr("itemid", ""),
+		Properties: make(map[string][]StructuredDataValue),
+	}
+
+	var walk func(children *goquery.Selection)
+	walk = func(children *goquery.Selection) {
+		children.Each(func(index int, child *goquery.Selection) {
+			_, isScope := child.Attr("itemscope")
+			itemProp, isProp := child.Attr("itemprop")
+
+			if isProp {
+				value := StructuredDataValue{Text: elementValue(child)}
+				if isScope {
+					value = StructuredDataValue{Item: parseMicrodataItem(child)}
+				}
+				for _, name := range strings.Fields(itemProp) {
+					item.Properties[name] = append(item.Properties[name], value)
+				}
+			}
+
+			// Properties of a nested item belong to that item
+			if !isScope {
+				walk(child.Children())
+			}
+		})
+	}
+	walk(selection.Children())
+
+	return item
+}
+
+func parseRDFaItem(selection *goquery.Selection) *StructuredDataItem {
+	item := &StructuredDataItem{
+		Format:     FormatRDFa,
+		Types:      normalizeSchemaTypes(selection.AttrOr("typeof", "")),
+		ID:         firstNonEmptyAttr(selection, "resource", "about"),
+		Properties: make(map[string][]StructuredDataValue),
+	}
+
+	var walk func(children *goquery.Selection)
+	walk = func(children *goquery.Selection) {
+		children.Each(func(index int, child *goquery.Selection) {
+			_, isScope := child.Attr("typeof")
+			property, isProp := child.Attr("property")
+
+			if isProp {
+				value := StructuredDataValue{Text: elementValue(child)}
+				if isScope {
+					value = StructuredDataValue{Item: parseRDFaItem(child)}
+				}
+				for _, name := range strings.Fields(property) {
+					name = normalizeSchemaType(name)
+					item.Properties[name] = append(item.Properties[name], value)
+				}
+			}
+
+			// Properties of a nested item belong to that item
+			if !isScope {
+				walk(child.Children())
+			}
+		})
+	}
+	walk(selection.Children())
+
+	return item
+}
+
+// elementValue returns the property value of an element the way Microdata and RDFa define it.
+func elementValue(selection *goquery.Selection) string {
+	if content, ok := selection.Attr("content"); ok {
+		return strings.TrimSpace(content)
+	}
+
+	switch goquery.NodeName(selection) {
+	case "a", "area", "link":
+		if value := firstNonEmptyAttr(selection, "href", "resource"); value != "" {
+			return value
+		}
+	case "img", "audio", "video", "source", "iframe", "embed", "track":
+		if value := firstNonEmptyAttr(selection, "src", "resource"); value != "" {
+			return value
+		}
+	case "object":
+		if value := firstNonEmptyAttr(selection, "data", "resource"); value != "" {
+			return value
+		}
+	case "time":
+		if value := selection.AttrOr("datetime", ""); value != "" {
+			return value
+		}
+	case "data", "meter":
+		if value := selection.AttrOr("value", ""); value != "" {
+			return value
+		}
+	}
+
+	if resource := selection.AttrOr("resource", ""); resource != "" {
+		return resource
+	}
+
+	return normalizeSpace(selection.Text())
+}
+
+func firstNonEmptyAttr(selection *goquery.Selection, attrs ...string) string {
+	for _, attr := range attrs {
+		if value := strings.TrimSpace(selection.AttrOr(attr, "")); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func normalizeSchemaTypes(types string) []string {
+	var normalized []string
+	for _, t := range strings.Fields(types) {
+		normalized = append(normalized, normalizeSchemaType(t))
+	}
+	sort.Strings(normalized)
+
+	return normalized
+}
+
+// normalizeSchemaType strips the schema.org vocabulary from a type or property name.
+func normalizeSchemaType(name string) string {
+	for _, prefix := range []string{"http://schema.org/", "https://schema.org/", "schema:"} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+
+	return name
+}
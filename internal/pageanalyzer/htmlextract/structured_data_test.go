@@ -0,0 +1,81 @@
+package htmlextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredData(t *testing.T) {
+	htmlContent := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<title>Test Page</title>
+			<script type="application/ld+json">
+			{
+				"@context": "https://schema.org",
+				"@graph": [
+					{"@type": "Article", "@id": "#article", "headline": "Headline", "author": {"@type": "Person", "name": "Jane"}},
+					{"@type": ["BreadcrumbList"], "itemListElement": []}
+				]
+			}
+			</script>
+			<script type="application/ld+json">{"@type": "Product",}</script>
+		</head>
+		<body>
+			<div itemscope itemtype="https://schema.org/Product">
+				<span itemprop="name">Phone</span>
+				<img itemprop="image" src="/phone.png">
+				<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+					<meta itemprop="price" content="100">
+				</div>
+			</div>
+			<div vocab="https://schema.org/" typeof="Person">
+				<span property="name">John</span>
+				<a property="url" href="https://example.com/john">Profile</a>
+			</div>
+		</body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	structuredData := extractor.StructuredData()
+
+	require.Len(t, structuredData.Items, 4)
+	assert.Len(t, structuredData.Errors, 1)
+	assert.Contains(t, structuredData.Errors[0], "JSON-LD block 2: syntax error at offset")
+
+	article := structuredData.Items[0]
+	assert.Equal(t, FormatJSONLD, article.Format)
+	assert.Equal(t, []string{"Article"}, article.Types)
+	assert.Equal(t, "#article", article.ID)
+	assert.Equal(t, []StructuredDataValue{{Text: "Headline"}}, article.Properties["headline"])
+	require.Len(t, article.Properties["author"], 1)
+	assert.True(t, article.Properties["author"][0].Item.HasType("Person"))
+
+	breadcrumbs := structuredData.Items[1]
+	assert.True(t, breadcrumbs.HasType("BreadcrumbList"))
+	assert.Empty(t, breadcrumbs.Properties["itemListElement"])
+
+	product := structuredData.Items[2]
+	assert.Equal(t, FormatMicrodata, product.Format)
+	assert.Equal(t, []string{"Product"}, product.Types)
+	assert.Equal(t, []StructuredDataValue{{Text: "Phone"}}, product.Properties["name"])
+	assert.Equal(t, []StructuredDataValue{{Text: "/phone.png"}}, product.Properties["image"])
+	require.Len(t, product.Properties["offers"], 1)
+	offer := product.Properties["offers"][0].Item
+	assert.Equal(t, []string{"Offer"}, offer.Types)
+	assert.Equal(t, []StructuredDataValue{{Text: "100"}}, offer.Properties["price"])
+
+	person := structuredData.Items[3]
+	assert.Equal(t, FormatRDFa, person.Format)
+	assert.Equal(t, []string{"Person"}, person.Types)
+	assert.Equal(t, []StructuredDataValue{{Text: "John"}}, person.Properties["name"])
+	assert.Equal(t, []StructuredDataValue{{Text: "https://example.com/john"}}, person.Properties["url"])
+}
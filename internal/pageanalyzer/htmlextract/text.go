@@ -0,0 +1,96 @@
+package htmlextract
+
+import (
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/html"
+)
+
+// textBlockElements start a new block of text, so their text is not run together with the surrounding text.
+var textBlockElements = []string{
+	"address", "article", "aside", "blockquote", "br", "caption", "dd", "details", "div", "dl", "dt", "fieldset",
+	"figcaption", "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr", "li", "main",
+	"nav", "ol", "p", "pre", "section", "summary", "table", "td", "th", "tr", "ul",
+}
+
+// invisibleTextElements hold text that is not rendered as content.
+var invisibleTextElements = []string{
+	"script", "style", "noscript", "template", "svg", "math", "iframe", "object", "canvas", "select", "textarea",
+}
+
+// Lang returns the lang attribute of the <html> element.
+func (h *HTMLExtractor) Lang() string {
+	return strings.TrimSpace(h.goQueryDoc.Find("html").AttrOr("lang", ""))
+}
+
+// TextBlocks returns the visible text of the body, split at block elements such as paragraphs, headings and list items.
+// Whitespace is collapsed and empty blocks are dropped.
+func (h *HTMLExtractor) TextBlocks() []string {
+	return textBlocks(h.goQueryDoc.Find("body").Nodes)
+}
+
+// textBlocks returns the visible text of the nodes, split at block elements.
+func textBlocks(nodes []*html.Node) []string {
+	var (
+		blocks  []string
+		current strings.Builder
+	)
+
+	flush := func() {
+		if text := normalizeSpace(current.String()); text != "" {
+			blocks = append(blocks, text)
+		}
+		current.Reset()
+	}
+
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		switch node.Type {
+		case html.TextNode:
+			current.WriteString(node.Data)
+			return
+		case html.ElementNode:
+			if slices.Contains(invisibleTextElements, node.Data) || isHiddenNode(node) {
+				return
+			}
+		}
+
+		isBlock := node.Type == html.ElementNode && slices.Contains(textBlockElements, node.Data)
+		if isBlock {
+			flush()
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+
+		if isBlock {
+			flush()
+		}
+	}
+
+	for _, node := range nodes {
+		walk(node)
+	}
+	flush()
+
+	return blocks
+}
+
+// isHiddenNode reports whether the element is hidden with the hidden attribute or an inline style.
+func isHiddenNode(node *html.Node) bool {
+	for _, attr := range node.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "hidden":
+			return true
+		case "style":
+			style := strings.ReplaceAll(strings.ToLower(attr.Val), " ", "")
+			if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,48 @@
+package htmlextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextBlocks(t *testing.T) {
+	htmlContent := `
+		<!DOCTYPE html>
+		<html lang=" en-GB ">
+		<head>
+			<title>Not body text</title>
+			<style>body { color: red; }</style>
+		</head>
+		<body>
+			<h1>Main   heading</h1>
+			<p>First <strong>paragraph</strong> with <a href="/">a link</a>.</p>
+			<ul><li>One</li><li>Two</li></ul>
+			<script>var hidden = "script";</script>
+			<div hidden>Hidden attribute</div>
+			<div style="display: none">Hidden style</div>
+			<div>Line one<br>Line two</div>
+			<noscript>Enable JavaScript</noscript>
+			Trailing text
+		</body>
+		</html>
+	`
+
+	extractor, err := New([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+
+	expected := []string{
+		"Main heading",
+		"First paragraph with a link.",
+		"One",
+		"Two",
+		"Line one",
+		"Line two",
+		"Trailing text",
+	}
+
+	assert.Equal(t, expected, extractor.TextBlocks())
+	assert.Equal(t, "en-GB", extractor.Lang())
+}
@@ -0,0 +1,573 @@
+package pageanalyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder for image.DecodeConfig
+	_ "image/jpeg" // register JPEG decoder for image.DecodeConfig
+	_ "image/png"  // register PNG decoder for image.DecodeConfig
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+const (
+	// maxImageBytes caps how much of an image is downloaded for the audit.
+	maxImageBytes = 10 << 20
+
+	// largeImageBytes is the size above which an image is reported as heavy.
+	largeImageBytes = 200 << 10
+
+	// modernFormatMinBytes is the size below which converting an image to WebP or AVIF is not worth it.
+	modernFormatMinBytes = 10 << 10
+
+	// aboveFoldImages is the number of images assumed to be visible without scrolling. The layout is not
+	// rendered, so the first images in document order are treated as above the fold.
+	aboveFoldImages = 2
+
+	// highDensityFactor allows images up to twice their rendered size for high density displays.
+	highDensityFactor = 2
+
+	// maxAspectRatioDeviation is how far the declared aspect ratio may deviate before the image is distorted.
+	maxAspectRatioDeviation = 0.05
+)
+
+// Image formats
+const (
+	ImageFormatJPEG = "JPEG"
+	ImageFormatPNG  = "PNG"
+	ImageFormatGIF  = "GIF"
+	ImageFormatWebP = "WebP"
+	ImageFormatAVIF = "AVIF"
+	ImageFormatSVG  = "SVG"
+	ImageFormatICO  = "ICO"
+	ImageFormatBMP  = "BMP"
+)
+
+// legacyImageFormats are the raster formats WebP and AVIF compress better.
+var legacyImageFormats = []string{ImageFormatJPEG, ImageFormatPNG, ImageFormatGIF, ImageFormatBMP}
+
+// ImageFile is a downloaded image source.
+type ImageFile struct {
+	URL string
+	// Element and Attr tell where the URL was found, e.g. "source" and "srcset".
+	Element    string
+	Attr       string
+	Descriptor string
+	Format     string
+	Bytes      int64
+	// Truncated is set if the image is larger than the download cap and its size is unknown, Bytes is then a
+	// lower bound
+	Truncated bool
+	// Width and Height are the decoded pixel dimensions, 0 if unknown, e.g. for SVG
+	Width  int
+	Height int
+	Error  string
+	Issues []string
+}
+
+// ImageAudit is the audit of an <img> with its sources or an element with a CSS background image.
+type ImageAudit struct {
+	Element string
+	CSSPath string
+	// Width and Height are the rendered dimensions declared by the attributes, 0 if missing
+	Width     int
+	Height    int
+	Loading   string
+	AboveFold bool
+	Files     []*ImageFile
+	Issues    []string
+}
+
+// ImageReport is the inventory of the images of the page.
+type ImageReport struct {
+	Images []*ImageAudit
+	// TotalBytes is the size of the distinct image files
+	TotalBytes int64
+	IssuesNum  int
+	// downloaded are the image files by URL, reused to measure the page weight
+	downloaded map[string]*imageData
+}
+
+// Size returns the formatted size of the image file.
+func (f *ImageFile) Size() string {
+	if f.Truncated {
+		return "more than " + formatBytes(f.Bytes)
+	}
+
+	return formatBytes(f.Bytes)
+}
+
+// TotalSize returns the formatted size of the distinct image files.
+func (r *ImageReport) TotalSize() string {
+	return formatBytes(r.TotalBytes)
+}
+
+// imageData is the format, size and dimensions of a downloaded image, with the response metadata.
+type imageData struct {
+	format    string
+	bytes     int64
+	truncated bool
+	width     int
+	height    int
+	err       error
+
+	statusCode      int
+	header          http.Header
+	contentEncoding string
+	requested       time.Time
+	duration        time.Duration
+}
+
+// imageReport downloads the images of the page and audits their size, dimensions, format and loading.
+// Tracking pixels and inline data: URLs are skipped.
+func (w *WebpageAnalyzer) imageReport(ctx context.Context, images []htmlextract.Image, pageURL string) *ImageReport {
+	report := &ImageReport{}
+
+	var imageURLs []string
+	for _, img := range images {
+		if img.Pixel {
+			continue
+		}
+
+		audit := &ImageAudit{
+			Element:   img.Element,
+			CSSPath:   img.CSSPath,
+			Width:     parseDimension(img.Width),
+			Height:    parseDimension(img.Height),
+			Loading:   img.Loading,
+			AboveFold: len(report.Images) < aboveFoldImages,
+		}
+
+		for _, source := range img.Sources {
+			resolvedURL, ok := resolveResourceURL(source.URL, pageURL)
+			if !ok {
+				continue
+			}
+
+			audit.Files = append(audit.Files, &ImageFile{
+				URL:        resolvedURL,
+				Element:    source.Element,
+				Attr:       source.Attr,
+				Descriptor: source.Descriptor,
+				Format:     imageFormatFromType(source.Type),
+			})
+			imageURLs = append(imageURLs, resolvedURL)
+		}
+
+		if len(audit.Files) > 0 {
+			report.Images = append(report.Images, audit)
+		}
+	}
+
+	downloaded := w.downloadImages(ctx, imageURLs)
+	report.downloaded = downloaded
+	for imageURL, data := range downloaded {
+		if data.err == nil {
+			report.TotalBytes += data.bytes
+		} else {
+			logrus.WithError(data.err).WithField("url", imageURL).Debug("download image failed")
+		}
+	}
+
+	for _, audit := range report.Images {
+		for _, file := range audit.Files {
+			data := downloaded[file.URL]
+			if data.err != nil {
+				file.Error = data.err.Error()
+				continue
+			}
+
+			file.Format = data.format
+			file.Bytes = data.bytes
+			file.Truncated = data.truncated
+			file.Width = data.width
+			file.Height = data.height
+			file.Issues = imageFileIssues(audit, file)
+		}
+
+		audit.Issues = imageAuditIssues(audit)
+
+		report.IssuesNum += len(audit.Issues)
+		for _, file := range audit.Files {
+			report.IssuesNum += len(file.Issues)
+			if file.Error != "" {
+				report.IssuesNum++
+			}
+		}
+	}
+
+	return report
+}
+
+// imageAuditIssues checks the attributes and formats of an image element.
+func imageAuditIssues(audit *ImageAudit) []string {
+	var issues []string
+
+	if audit.Element == "img" {
+		switch {
+		case audit.Width == 0 && audit.Height == 0:
+			issues = append(issues, "missing width and height attributes, the layout shifts when the image loads")
+		case audit.Width == 0:
+			issues = append(issues, "missing width attribute, the layout shifts when the image loads")
+		case audit.Height == 0:
+			issues = append(issues, "missing height attribute, the layout shifts when the image loads")
+		}
+
+		switch {
+		case !audit.AboveFold && audit.Loading != "lazy":
+			issues = append(issues, `below the fold without loading="lazy"`)
+		case audit.AboveFold && audit.Loading == "lazy":
+			issues = append(issues, `likely above the fold but loading="lazy" delays it`)
+		}
+	}
+
+	// Suggest a modern format only if no source offers one and the image is worth converting
+	var hasModernFormat, hasLargeLegacyFormat bool
+	for _, file := range audit.Files {
+		switch {
+		case file.Format == ImageFormatWebP || file.Format == ImageFormatAVIF:
+			hasModernFormat = true
+		case slices.Contains(legacyImageFormats, file.Format) && file.Bytes >= modernFormatMinBytes:
+			hasLargeLegacyFormat = true
+		}
+	}
+	if hasLargeLegacyFormat && !hasModernFormat {
+		issues = append(issues, "no WebP or AVIF version, serving one would reduce the size")
+	}
+
+	return issues
+}
+
+// imageFileIssues checks the size and dimensions of a downloaded image against the element attributes.
+func imageFileIssues(audit *ImageAudit, file *ImageFile) []string {
+	var issues []string
+
+	switch {
+	case file.Truncated:
+		issues = append(issues, fmt.Sprintf("large image of more than %s", formatBytes(file.Bytes)))
+	case file.Bytes > largeImageBytes:
+		issues = append(issues, fmt.Sprintf("large image of %s", formatBytes(file.Bytes)))
+	}
+
+	if file.Width == 0 || file.Height == 0 {
+		return issues
+	}
+
+	// A width descriptor declares the width of the image file
+	if strings.HasSuffix(file.Descriptor, "w") {
+		if declared, err := strconv.Atoi(strings.TrimSuffix(file.Descriptor, "w")); err == nil && declared != file.Width {
+			issues = append(issues, fmt.Sprintf("srcset declares %s but the image is %d pixels wide", file.Descriptor, file.Width))
+		}
+		return issues
+	}
+
+	if audit.Element != "img" || audit.Width == 0 {
+		return issues
+	}
+
+	// A density descriptor, e.g. "2x", scales the rendered size
+	density := 1.0
+	if strings.HasSuffix(file.Descriptor, "x") {
+		if parsed, err := strconv.ParseFloat(strings.TrimSuffix(file.Descriptor, "x"), 64); err == nil && parsed > 0 {
+			density = parsed
+		}
+	}
+
+	rendered := fmt.Sprintf("%dx%d", audit.Width, audit.Height)
+	if audit.Height == 0 {
+		rendered = fmt.Sprintf("%d pixels wide", audit.Width)
+	}
+
+	switch {
+	case float64(file.Width) > float64(audit.Width)*math.Max(density, highDensityFactor):
+		issues = append(issues, fmt.Sprintf("image is %dx%d but rendered at %s, resize it", file.Width, file.Height, rendered))
+	case float64(file.Width) < float64(audit.Width)*density:
+		issues = append(issues, fmt.Sprintf("image is %dx%d but rendered at %s, it looks blurry", file.Width, file.Height, rendered))
+	}
+
+	if audit.Height != 0 {
+		declaredRatio := float64(audit.Width) / float64(audit.Height)
+		actualRatio := float64(file.Width) / float64(file.Height)
+		if math.Abs(declaredRatio-actualRatio)/actualRatio > maxAspectRatioDeviation {
+			issues = append(issues, fmt.Sprintf(
+				"aspect ratio of the image (%dx%d) differs from the width and height attributes (%s), it is distorted",
+				file.Width, file.Height, rendered,
+			))
+		}
+	}
+
+	return issues
+}
+
+// downloadImages downloads each distinct image concurrently.
+func (w *WebpageAnalyzer) downloadImages(ctx context.Context, imageURLs []string) map[string]*imageData {
+	var (
+		downloaded = make(map[string]*imageData)
+		distinct   = make(map[string]bool)
+		lock       sync.Mutex
+		semaphore  = make(chan struct{}, linkCheckConcurrency)
+		wg         sync.WaitGroup
+	)
+
+	for _, imageURL := range imageURLs {
+		// The workers write to downloaded, so deduplicate with a set only this loop uses
+		if distinct[imageURL] {
+			continue
+		}
+		distinct[imageURL] = true
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(imageURL string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+
+			data := w.downloadImage(ctx, imageURL)
+
+			lock.Lock()
+			downloaded[imageURL] = data
+			lock.Unlock()
+		}(imageURL)
+	}
+
+	wg.Wait()
+
+	return downloaded
+}
+
+// downloadImage downloads up to maxImageBytes of the image and decodes its format and dimensions.
+func (w *WebpageAnalyzer) downloadImage(ctx context.Context, imageURL string) *imageData {
+	data := &imageData{requested: time.Now()}
+	defer func() {
+		data.duration = time.Since(data.requested)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil /* body */)
+	if err != nil {
+		data.err = fmt.Errorf("new request with context failed: %v", err)
+		return data
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		data.err = fmt.Errorf("send request failed: %v", err)
+		return data
+	}
+	defer resp.Body.Close()
+
+	data.statusCode = resp.StatusCode
+	data.header = resp.Header
+	data.contentEncoding = strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if resp.Uncompressed {
+		// The client removed the encoding it decoded transparently
+		data.contentEncoding = "gzip"
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		data.err = fmt.Errorf("request failed with status %d", resp.StatusCode)
+		return data
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		data.err = fmt.Errorf("read response body failed: %v", err)
+		return data
+	}
+
+	data.bytes = int64(len(content))
+	if len(content) > maxImageBytes {
+		content = content[:maxImageBytes]
+		data.bytes = maxImageBytes
+		data.truncated = true
+	}
+	if data.truncated && resp.ContentLength > data.bytes {
+		data.bytes = resp.ContentLength
+		data.truncated = false
+	}
+
+	data.format = sniffImageFormat(content, resp.Header.Get("Content-Type"))
+	data.width, data.height = imageDimensions(data.format, content)
+
+	return data
+}
+
+// sniffImageFormat detects the image format from its magic bytes, falling back to the Content-Type.
+func sniffImageFormat(content []byte, contentType string) string {
+	switch {
+	case bytes.HasPrefix(content, []byte("\xff\xd8\xff")):
+		return ImageFormatJPEG
+	case bytes.HasPrefix(content, []byte("\x89PNG\r\n\x1a\n")):
+		return ImageFormatPNG
+	case bytes.HasPrefix(content, []byte("GIF87a")), bytes.HasPrefix(content, []byte("GIF89a")):
+		return ImageFormatGIF
+	case len(content) >= 12 && string(content[:4]) == "RIFF" && string(content[8:12]) == "WEBP":
+		return ImageFormatWebP
+	case len(content) >= 12 && string(content[4:8]) == "ftyp" &&
+		(string(content[8:12]) == "avif" || string(content[8:12]) == "avis"):
+		return ImageFormatAVIF
+	case bytes.HasPrefix(content, []byte("\x00\x00\x01\x00")):
+		return ImageFormatICO
+	case bytes.HasPrefix(content, []byte("BM")):
+		return ImageFormatBMP
+	}
+
+	head := content
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	if bytes.Contains(bytes.ToLower(head), []byte("<svg")) {
+		return ImageFormatSVG
+	}
+
+	return imageFormatFromType(contentType)
+}
+
+// imageFormatFromType returns the format of an image MIME type, e.g. "image/webp".
+func imageFormatFromType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	switch mediaType {
+	case "image/jpeg", "image/jpg":
+		return ImageFormatJPEG
+	case "image/png":
+		return ImageFormatPNG
+	case "image/gif":
+		return ImageFormatGIF
+	case "image/webp":
+		return ImageFormatWebP
+	case "image/avif":
+		return ImageFormatAVIF
+	case "image/svg+xml":
+		return ImageFormatSVG
+	case "image/x-icon", "image/vnd.microsoft.icon":
+		return ImageFormatICO
+	case "image/bmp":
+		return ImageFormatBMP
+	}
+
+	return strings.TrimPrefix(mediaType, "image/")
+}
+
+// imageDimensions decodes the pixel dimensions of a raster image, it returns zeros if they are unknown.
+func imageDimensions(format string, content []byte) (int, int) {
+	switch format {
+	case ImageFormatJPEG, ImageFormatPNG, ImageFormatGIF:
+		config, _, err := image.DecodeConfig(bytes.NewReader(content))
+		if err != nil {
+			return 0, 0
+		}
+		return config.Width, config.Height
+	case ImageFormatWebP:
+		return webpDimensions(content)
+	case ImageFormatAVIF:
+		return avifDimensions(content)
+	}
+
+	return 0, 0
+}
+
+// webpDimensions reads the canvas size from the header of a lossy, lossless or extended WebP image.
+func webpDimensions(content []byte) (int, int) {
+	if len(content) < 30 {
+		return 0, 0
+	}
+
+	chunk := content[20:]
+	switch string(content[12:16]) {
+	case "VP8 ":
+		// Frame tag and start code, then 14-bit width and height
+		if chunk[3] != 0x9d || chunk[4] != 0x01 || chunk[5] != 0x2a {
+			return 0, 0
+		}
+		return int(binary.LittleEndian.Uint16(chunk[6:8]) & 0x3fff), int(binary.LittleEndian.Uint16(chunk[8:10]) & 0x3fff)
+	case "VP8L":
+		// Signature, then 14-bit width and height minus one
+		if chunk[0] != 0x2f {
+			return 0, 0
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:5])
+		return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1
+	case "VP8X":
+		// Flags, then 24-bit canvas width and height minus one
+		width := int(chunk[4]) | int(chunk[5])<<8 | int(chunk[6])<<16
+		height := int(chunk[7]) | int(chunk[8])<<8 | int(chunk[9])<<16
+		return width + 1, height + 1
+	}
+
+	return 0, 0
+}
+
+// avifDimensions reads the size of the first image spatial extents (ispe) property of an AVIF image.
+func avifDimensions(content []byte) (int, int) {
+	index := bytes.Index(content, []byte("ispe"))
+	if index == -1 || index+16 > len(content) {
+		return 0, 0
+	}
+
+	// The box type is followed by the version and flags, then 32-bit width and height
+	return int(binary.BigEndian.Uint32(content[index+8 : index+12])), int(binary.BigEndian.Uint32(content[index+12 : index+16]))
+}
+
+// resolveResourceURL resolves the resource URL against the base URL, inline data: and other non-HTTP URLs are
+// skipped.
+func resolveResourceURL(resourceURL, baseURL string) (string, bool) {
+	parsedURL, err := url.Parse(resourceURL)
+	if err != nil {
+		return "", false
+	}
+
+	if scheme := strings.ToLower(parsedURL.Scheme); scheme != "" && scheme != "http" && scheme != "https" {
+		return "", false
+	}
+
+	resolvedURL, err := resolveLink(resourceURL, baseURL)
+	if err != nil {
+		return "", false
+	}
+
+	return stripFragment(resolvedURL), true
+}
+
+// parseDimension parses a width or height attribute, it returns 0 if the value is missing or not a number.
+func parseDimension(value string) int {
+	dimension, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "px"))
+	if err != nil || dimension < 0 {
+		return 0
+	}
+
+	return dimension
+}
+
+// formatBytes formats a size in bytes, KB or MB.
+func formatBytes(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
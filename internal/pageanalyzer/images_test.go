@@ -0,0 +1,218 @@
+package pageanalyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+// webpImage returns the header of a lossless WebP image, padded with empty image data.
+func webpImage(width, height int) []byte {
+	content := []byte("RIFF\x00\x00\x00\x00WEBPVP8L\x00\x00\x00\x00\x2f")
+	content = binary.LittleEndian.AppendUint32(content, uint32(width-1)|uint32(height-1)<<14)
+	return append(content, make([]byte, 16)...)
+}
+
+// avifImage returns the start of an AVIF image with its image spatial extents property.
+func avifImage(width, height int) []byte {
+	content := []byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00avifmif1miaf\x00\x00\x00\x14ispe\x00\x00\x00\x00")
+	content = binary.BigEndian.AppendUint32(content, uint32(width))
+	return binary.BigEndian.AppendUint32(content, uint32(height))
+}
+
+func pngImage(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSniffImageFormat(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        []byte
+		contentType    string
+		expectedFormat string
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{name: "PNG", content: pngImage(t, 30, 20), expectedFormat: ImageFormatPNG, expectedWidth: 30, expectedHeight: 20},
+		{name: "WebP", content: webpImage(800, 600), expectedFormat: ImageFormatWebP, expectedWidth: 800, expectedHeight: 600},
+		{name: "AVIF", content: avifImage(1920, 1080), expectedFormat: ImageFormatAVIF, expectedWidth: 1920, expectedHeight: 1080},
+		{name: "SVG", content: []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`), expectedFormat: ImageFormatSVG},
+		{name: "Content-Type fallback", content: []byte("unknown"), contentType: "image/jpeg; charset=binary", expectedFormat: ImageFormatJPEG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := sniffImageFormat(tt.content, tt.contentType)
+			width, height := imageDimensions(format, tt.content)
+
+			assert.Equal(t, tt.expectedFormat, format)
+			assert.Equal(t, tt.expectedWidth, width)
+			assert.Equal(t, tt.expectedHeight, height)
+		})
+	}
+}
+
+func TestImageReport(t *testing.T) {
+	largePNG := pngImage(t, 1200, 800)
+	// Pad the image to make it worth converting, decoders ignore trailing bytes
+	largePNG = append(largePNG, make([]byte, modernFormatMinBytes)...)
+
+	files := map[string][]byte{
+		"/logo.png":        pngImage(t, 120, 40),
+		"/photo.png":       largePNG,
+		"/photo-800.webp":  webpImage(800, 533),
+		"/photo-1600.webp": webpImage(1600, 1066),
+		"/hero.avif":       avifImage(1920, 1080),
+		"/small.png":       pngImage(t, 100, 100),
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer testServer.Close()
+
+	analyzer := New(&Config{}, testServer.Client())
+
+	images := []htmlextract.Image{
+		{
+			Element: "img", CSSPath: "#logo", Width: "120", Height: "40",
+			Sources: []htmlextract.ImageSource{{URL: "/logo.png", Element: "img", Attr: "src"}},
+		},
+		{
+			Element: "div", CSSPath: "#hero",
+			Sources: []htmlextract.ImageSource{{URL: "/hero.avif", Element: "div", Attr: "style"}},
+		},
+		{
+			Element: "img", CSSPath: "#photo", Width: "600", Height: "300", Loading: "lazy",
+			Sources: []htmlextract.ImageSource{
+				{URL: "/photo-800.webp", Element: "source", Attr: "srcset", Descriptor: "800w", Type: "image/webp"},
+				{URL: "/photo-1600.webp", Element: "source", Attr: "srcset", Descriptor: "1500w", Type: "image/webp"},
+				{URL: "/photo.png", Element: "img", Attr: "src"},
+			},
+		},
+		{
+			Element: "img", CSSPath: "#small", Width: "200",
+			Sources: []htmlextract.ImageSource{{URL: "/small.png", Element: "img", Attr: "src"}},
+		},
+		{
+			Element: "img", CSSPath: "#legacy", Width: "1200", Height: "800", Loading: "lazy",
+			Sources: []htmlextract.ImageSource{{URL: "/photo.png", Element: "img", Attr: "src"}},
+		},
+		{
+			Element: "img", CSSPath: "#missing", Width: "10", Height: "10", Loading: "lazy",
+			Sources: []htmlextract.ImageSource{{URL: "/missing.png", Element: "img", Attr: "src"}},
+		},
+		{
+			Element: "img", CSSPath: "#inline",
+			Sources: []htmlextract.ImageSource{{URL: "data:image/png;base64,iVBORw0KGgo=", Element: "img", Attr: "src"}},
+		},
+		{
+			Element: "img", CSSPath: "#pixel", Width: "1", Height: "1", Pixel: true,
+			Sources: []htmlextract.ImageSource{{URL: "/pixel.gif", Element: "img", Attr: "src"}},
+		},
+	}
+
+	report := analyzer.imageReport(context.Background(), images, testServer.URL+"/")
+
+	if !assert.Len(t, report.Images, 6) {
+		return
+	}
+
+	logo := report.Images[0]
+	assert.True(t, logo.AboveFold)
+	assert.Empty(t, logo.Issues)
+	assert.Equal(t, ImageFormatPNG, logo.Files[0].Format)
+	assert.Equal(t, 120, logo.Files[0].Width)
+	assert.Empty(t, logo.Files[0].Issues)
+
+	hero := report.Images[1]
+	assert.True(t, hero.AboveFold)
+	assert.Empty(t, hero.Issues)
+	assert.Equal(t, ImageFormatAVIF, hero.Files[0].Format)
+
+	photo := report.Images[2]
+	assert.False(t, photo.AboveFold)
+	assert.Empty(t, photo.Issues)
+	assert.Empty(t, photo.Files[0].Issues)
+	assert.Equal(t, []string{"srcset declares 1500w but the image is 1600 pixels wide"}, photo.Files[1].Issues)
+	assert.Equal(t, []string{
+		"aspect ratio of the image (1200x800) differs from the width and height attributes (600x300), it is distorted",
+	}, photo.Files[2].Issues)
+
+	small := report.Images[3]
+	assert.Equal(t, []string{
+		"missing height attribute, the layout shifts when the image loads",
+		`below the fold without loading="lazy"`,
+	}, small.Issues)
+	assert.Equal(t, []string{"image is 100x100 but rendered at 200 pixels wide, it looks blurry"}, small.Files[0].Issues)
+
+	legacy := report.Images[4]
+	assert.Equal(t, []string{"no WebP or AVIF version, serving one would reduce the size"}, legacy.Issues)
+
+	missing := report.Images[5]
+	assert.Equal(t, "request failed with status 404", missing.Files[0].Error)
+
+	expectedBytes := int64(len(files["/logo.png"]) + len(largePNG) + len(files["/photo-800.webp"]) +
+		len(files["/photo-1600.webp"]) + len(files["/hero.avif"]) + len(files["/small.png"]))
+	assert.Equal(t, expectedBytes, report.TotalBytes)
+	assert.Equal(t, 7, report.IssuesNum)
+}
+
+func TestDownloadImages_Concurrent(t *testing.T) {
+	content := pngImage(t, 10, 10)
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content)
+	}))
+	defer testServer.Close()
+
+	analyzer := New(&Config{}, testServer.Client())
+
+	// Repeat every URL, so duplicates are skipped while earlier downloads are still running
+	var imageURLs []string
+	for i := 0; i < 200; i++ {
+		imageURLs = append(imageURLs, fmt.Sprintf("%s/image-%d.png", testServer.URL, i%100))
+	}
+
+	downloaded := analyzer.downloadImages(context.Background(), imageURLs)
+
+	assert.Len(t, downloaded, 100)
+	for _, imageURL := range imageURLs {
+		if assert.NotNil(t, downloaded[imageURL]) {
+			assert.Equal(t, ImageFormatPNG, downloaded[imageURL].format)
+		}
+	}
+}
+
+func TestImageFileIssues_Oversized(t *testing.T) {
+	audit := &ImageAudit{Element: "img", Width: 300, Height: 200}
+
+	assert.Equal(t,
+		[]string{"image is 1200x800 but rendered at 300x200, resize it"},
+		imageFileIssues(audit, &ImageFile{Width: 1200, Height: 800, Bytes: 1000}),
+	)
+	assert.Empty(t, imageFileIssues(audit, &ImageFile{Width: 600, Height: 400, Descriptor: "2x", Bytes: 1000}))
+	assert.Equal(t,
+		[]string{"large image of 300.0 KB"},
+		imageFileIssues(audit, &ImageFile{Width: 300, Height: 200, Bytes: 300 << 10}),
+	)
+}
@@ -0,0 +1,118 @@
+package pageanalyzer
+
+import (
+	"math"
+	"strings"
+)
+
+// reportedRels are the link types counted in the link attribute report.
+var reportedRels = []string{"nofollow", "sponsored", "ugc", "noopener", "noreferrer"}
+
+// LinkAttributeReport summarizes the rel and target attributes of the web links of the page.
+type LinkAttributeReport struct {
+	RelCounts []RelCount
+
+	InternalNum         int
+	InternalNofollowNum int
+	ExternalNum         int
+	ExternalNofollowNum int
+
+	// UnsafeBlankTargetLinks open in a new tab without noopener, giving the new page access to window.opener
+	UnsafeBlankTargetLinks []UnsafeLink
+
+	// Links are the web links of the page with their attributes, in document order
+	Links []LinkDetail
+}
+
+// RelCount is the number of web links with a link type in their rel attribute.
+type RelCount struct {
+	Rel   string
+	Count int
+}
+
+// LinkDetail is a web link of the page with the attributes of its element.
+type LinkDetail struct {
+	URL      string
+	Text     string
+	Rel      []string
+	Target   string
+	Title    string
+	Internal bool
+	// HasImage is set if the link wraps an image
+	HasImage bool
+}
+
+// UnsafeLink is a link flagged by the link attribute report.
+type UnsafeLink struct {
+	URL  string
+	Text string
+}
+
+// InternalNofollowPercent returns the share of internal links with rel=nofollow.
+func (r *LinkAttributeReport) InternalNofollowPercent() int {
+	return percent(r.InternalNofollowNum, r.InternalNum)
+}
+
+// ExternalNofollowPercent returns the share of external links with rel=nofollow.
+func (r *LinkAttributeReport) ExternalNofollowPercent() int {
+	return percent(r.ExternalNofollowNum, r.ExternalNum)
+}
+
+func linkAttributeReport(links []classifiedLink, pageURL string, scope Scope) *LinkAttributeReport {
+	report := &LinkAttributeReport{}
+
+	relToCount := make(map[string]int)
+
+	for _, link := range links {
+		if !link.isCheckable() {
+			continue
+		}
+
+		for _, rel := range link.Rel {
+			relToCount[rel]++
+		}
+
+		internal := scope.IsInternal(link.URL, pageURL)
+		report.Links = append(report.Links, LinkDetail{
+			URL:      link.URL,
+			Text:     link.Text,
+			Rel:      link.Rel,
+			Target:   link.Target,
+			Title:    link.Title,
+			Internal: internal,
+			HasImage: link.HasImage,
+		})
+
+		nofollow := link.HasRel("nofollow")
+		if internal {
+			report.InternalNum++
+			if nofollow {
+				report.InternalNofollowNum++
+			}
+		} else {
+			report.ExternalNum++
+			if nofollow {
+				report.ExternalNofollowNum++
+			}
+		}
+
+		// noreferrer implies noopener
+		if strings.EqualFold(link.Target, "_blank") && !link.HasRel("noopener") && !link.HasRel("noreferrer") {
+			report.UnsafeBlankTargetLinks = append(report.UnsafeBlankTargetLinks, UnsafeLink{URL: link.URL, Text: link.Text})
+		}
+	}
+
+	for _, rel := range reportedRels {
+		report.RelCounts = append(report.RelCounts, RelCount{Rel: rel, Count: relToCount[rel]})
+	}
+
+	return report
+}
+
+func percent(part, total int) int {
+	if total == 0 {
+		return 0
+	}
+
+	return int(math.Round(float64(part) * 100 / float64(total)))
+}
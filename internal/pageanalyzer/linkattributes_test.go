@@ -0,0 +1,67 @@
+package pageanalyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestLinkAttributeReport(t *testing.T) {
+	const pageURL = "https://example.com/"
+
+	links := []classifiedLink{
+		{
+			Link: htmlextract.Link{Rel: []string{"nofollow"}},
+			Kind: LinkKindNavigational,
+			URL:  "https://example.com/login",
+		},
+		{
+			Link: htmlextract.Link{},
+			Kind: LinkKindNavigational,
+			URL:  "https://example.com/about",
+		},
+		{
+			Link: htmlextract.Link{Text: "Partner", Rel: []string{"nofollow", "sponsored"}, Target: "_blank", Title: "Our partner", HasImage: true},
+			Kind: LinkKindNavigational,
+			URL:  "https://partner.com/",
+		},
+		{
+			Link: htmlextract.Link{Target: "_blank", Rel: []string{"noreferrer"}},
+			Kind: LinkKindDownload,
+			URL:  "https://cdn.com/report.pdf",
+		},
+		{
+			Link: htmlextract.Link{Target: "_blank", Rel: []string{"nofollow"}},
+			Kind: LinkKindMailto,
+			URL:  "mailto:info@example.com",
+		},
+	}
+
+	report := linkAttributeReport(links, pageURL, Scope{Mode: ScopeHostWithWWW})
+
+	assert.Equal(t, []RelCount{
+		{Rel: "nofollow", Count: 2},
+		{Rel: "sponsored", Count: 1},
+		{Rel: "ugc", Count: 0},
+		{Rel: "noopener", Count: 0},
+		{Rel: "noreferrer", Count: 1},
+	}, report.RelCounts)
+	assert.Equal(t, 2, report.InternalNum)
+	assert.Equal(t, 1, report.InternalNofollowNum)
+	assert.Equal(t, 50, report.InternalNofollowPercent())
+	assert.Equal(t, 2, report.ExternalNum)
+	assert.Equal(t, 1, report.ExternalNofollowNum)
+	assert.Equal(t, 50, report.ExternalNofollowPercent())
+	assert.Equal(t, []UnsafeLink{{URL: "https://partner.com/", Text: "Partner"}}, report.UnsafeBlankTargetLinks)
+
+	// The mailto link is not a web link
+	if assert.Len(t, report.Links, 4) {
+		assert.Equal(t, LinkDetail{URL: "https://example.com/login", Rel: []string{"nofollow"}, Internal: true}, report.Links[0])
+		assert.Equal(t, LinkDetail{
+			URL: "https://partner.com/", Text: "Partner", Rel: []string{"nofollow", "sponsored"}, Target: "_blank",
+			Title: "Our partner", HasImage: true,
+		}, report.Links[2])
+	}
+}
@@ -0,0 +1,135 @@
+package pageanalyzer
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+// Link kinds
+const (
+	LinkKindNavigational = "navigational"
+	LinkKindAnchor       = "same-page anchor"
+	LinkKindMailto       = "mailto"
+	LinkKindTel          = "tel"
+	LinkKindJavaScript   = "javascript"
+	LinkKindDownload     = "download"
+	LinkKindOther        = "other"
+)
+
+// linkKinds is the order link kinds are reported in.
+var linkKinds = []string{
+	LinkKindNavigational, LinkKindAnchor, LinkKindDownload, LinkKindMailto, LinkKindTel, LinkKindJavaScript, LinkKindOther,
+}
+
+// downloadExtensions are file extensions that browsers download instead of navigating to.
+var downloadExtensions = []string{
+	".pdf", ".zip", ".rar", ".7z", ".tar", ".gz", ".tgz", ".exe", ".msi", ".dmg", ".pkg", ".deb", ".rpm", ".apk",
+	".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx", ".odt", ".ods", ".csv", ".epub", ".iso", ".mp3", ".mp4",
+}
+
+// LinkKindCount is the number of links of a kind.
+type LinkKindCount struct {
+	Kind  string
+	Count int
+}
+
+// classifiedLink is a link of the page with its kind. URL is absolute for web links and the raw href otherwise.
+type classifiedLink struct {
+	htmlextract.Link
+	Kind string
+	URL  string
+}
+
+// isCheckable reports whether the link points to a web resource that can be requested.
+func (l classifiedLink) isCheckable() bool {
+	return l.Kind == LinkKindNavigational || l.Kind == LinkKindDownload
+}
+
+func classifyLinks(links []htmlextract.Link, pageURL string) []classifiedLink {
+	var classifiedLinks []classifiedLink
+
+	for _, link := range links {
+		classifiedLinks = append(classifiedLinks, classifyLink(link, pageURL))
+	}
+
+	return classifiedLinks
+}
+
+func classifyLink(link htmlextract.Link, pageURL string) classifiedLink {
+	href := link.Href
+
+	parsedLink, err := url.Parse(href)
+	if err != nil {
+		return classifiedLink{Link: link, Kind: LinkKindOther, URL: href}
+	}
+
+	switch strings.ToLower(parsedLink.Scheme) {
+	case "mailto":
+		return classifiedLink{Link: link, Kind: LinkKindMailto, URL: href}
+	case "tel":
+		return classifiedLink{Link: link, Kind: LinkKindTel, URL: href}
+	case "javascript":
+		return classifiedLink{Link: link, Kind: LinkKindJavaScript, URL: href}
+	case "", "http", "https":
+	default:
+		// data:, ftp:, app specific schemes and so on can't be checked
+		return classifiedLink{Link: link, Kind: LinkKindOther, URL: href}
+	}
+
+	resolvedLink, err := resolveLink(href, pageURL)
+	if err != nil {
+		return classifiedLink{Link: link, Kind: LinkKindOther, URL: href}
+	}
+
+	if isSamePageAnchor(resolvedLink, pageURL) {
+		return classifiedLink{Link: link, Kind: LinkKindAnchor, URL: resolvedLink}
+	}
+
+	if link.Download || slices.Contains(downloadExtensions, strings.ToLower(path.Ext(parsedLink.Path))) {
+		return classifiedLink{Link: link, Kind: LinkKindDownload, URL: resolvedLink}
+	}
+
+	return classifiedLink{Link: link, Kind: LinkKindNavigational, URL: resolvedLink}
+}
+
+// isSamePageAnchor reports whether the link only adds a fragment to the page URL.
+func isSamePageAnchor(link, pageURL string) bool {
+	parsedLink, err := url.Parse(link)
+	if err != nil || parsedLink.Fragment == "" {
+		return false
+	}
+
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return false
+	}
+
+	parsedLink.Fragment, parsedLink.RawFragment = "", ""
+	page.Fragment, page.RawFragment = "", ""
+
+	return parsedLink.String() == page.String()
+}
+
+func countLinkKinds(links []classifiedLink) []LinkKindCount {
+	var linkKindCounts []LinkKindCount
+
+	for _, kind := range linkKinds {
+		count := 0
+		for _, link := range links {
+			if link.Kind == kind {
+				count++
+			}
+		}
+
+		if count > 0 {
+			linkKindCounts = append(linkKindCounts, LinkKindCount{Kind: kind, Count: count})
+		}
+	}
+
+	return linkKindCounts
+}
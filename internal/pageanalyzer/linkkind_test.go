@@ -0,0 +1,106 @@
+package pageanalyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestClassifyLink(t *testing.T) {
+	const pageURL = "https://example.com/docs?page=1"
+
+	tests := []struct {
+		name         string
+		link         htmlextract.Link
+		expectedKind string
+		expectedURL  string
+	}{
+		{
+			name:         "Relative",
+			link:         htmlextract.Link{Href: "/about"},
+			expectedKind: LinkKindNavigational,
+			expectedURL:  "https://example.com/about",
+		},
+		{
+			name:         "Fragment only",
+			link:         htmlextract.Link{Href: "#install"},
+			expectedKind: LinkKindAnchor,
+			expectedURL:  "https://example.com/docs?page=1#install",
+		},
+		{
+			name:         "Fragment on the same page URL",
+			link:         htmlextract.Link{Href: "https://example.com/docs?page=1#usage"},
+			expectedKind: LinkKindAnchor,
+			expectedURL:  "https://example.com/docs?page=1#usage",
+		},
+		{
+			name:         "Fragment on another page",
+			link:         htmlextract.Link{Href: "/guide#install"},
+			expectedKind: LinkKindNavigational,
+			expectedURL:  "https://example.com/guide#install",
+		},
+		{
+			name:         "Mailto",
+			link:         htmlextract.Link{Href: "mailto:info@example.com"},
+			expectedKind: LinkKindMailto,
+			expectedURL:  "mailto:info@example.com",
+		},
+		{
+			name:         "Tel",
+			link:         htmlextract.Link{Href: "tel:+123456"},
+			expectedKind: LinkKindTel,
+			expectedURL:  "tel:+123456",
+		},
+		{
+			name:         "JavaScript",
+			link:         htmlextract.Link{Href: "JavaScript:void(0)"},
+			expectedKind: LinkKindJavaScript,
+			expectedURL:  "JavaScript:void(0)",
+		},
+		{
+			name:         "Download attribute",
+			link:         htmlextract.Link{Href: "/export", Download: true},
+			expectedKind: LinkKindDownload,
+			expectedURL:  "https://example.com/export",
+		},
+		{
+			name:         "Download extension",
+			link:         htmlextract.Link{Href: "https://cdn.example.org/report.PDF"},
+			expectedKind: LinkKindDownload,
+			expectedURL:  "https://cdn.example.org/report.PDF",
+		},
+		{
+			name:         "Data",
+			link:         htmlextract.Link{Href: "data:text/plain,hello"},
+			expectedKind: LinkKindOther,
+			expectedURL:  "data:text/plain,hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := classifyLink(tt.link, pageURL)
+			assert.Equal(t, tt.expectedKind, link.Kind)
+			assert.Equal(t, tt.expectedURL, link.URL)
+		})
+	}
+}
+
+func TestCountLinkKinds(t *testing.T) {
+	links := []classifiedLink{
+		{Kind: LinkKindMailto},
+		{Kind: LinkKindNavigational},
+		{Kind: LinkKindNavigational},
+		{Kind: LinkKindAnchor},
+	}
+
+	expected := []LinkKindCount{
+		{Kind: LinkKindNavigational, Count: 2},
+		{Kind: LinkKindAnchor, Count: 1},
+		{Kind: LinkKindMailto, Count: 1},
+	}
+
+	assert.Equal(t, expected, countLinkKinds(links))
+}
@@ -0,0 +1,40 @@
+package pageanalyzer
+
+import (
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+// MainContent is the article body of the page, isolated from navigation, footers, cookie banners and other
+// boilerplate.
+type MainContent struct {
+	Title     string
+	Byline    string
+	LeadImage string
+	Text      string
+	HTML      string
+	WordCount int
+}
+
+// mainContent resolves the lead image of the extracted main content and counts its words.
+func mainContent(extracted *htmlextract.MainContent, pageURL string) *MainContent {
+	if extracted == nil {
+		return nil
+	}
+
+	content := &MainContent{
+		Title:     extracted.Title,
+		Byline:    extracted.Byline,
+		LeadImage: extracted.LeadImage,
+		Text:      extracted.Text,
+		HTML:      extracted.HTML,
+		WordCount: len(splitWords(extracted.Text)),
+	}
+
+	if content.LeadImage != "" {
+		if resolvedURL, err := resolveLink(content.LeadImage, pageURL); err == nil {
+			content.LeadImage = resolvedURL
+		}
+	}
+
+	return content
+}
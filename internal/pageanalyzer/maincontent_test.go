@@ -0,0 +1,36 @@
+package pageanalyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestMainContent(t *testing.T) {
+	t.Run("Resolves the lead image and counts words", func(t *testing.T) {
+		extracted := &htmlextract.MainContent{
+			Title:     "Brewing coffee",
+			Byline:    "By Jane Doe",
+			LeadImage: "../images/beans.jpg",
+			Text:      "Good coffee starts with fresh beans.\n\nDon't forget the water.",
+			HTML:      "<p>Good coffee starts with fresh beans.</p><p>Don't forget the water.</p>",
+		}
+
+		expected := &MainContent{
+			Title:     "Brewing coffee",
+			Byline:    "By Jane Doe",
+			LeadImage: "https://example.com/images/beans.jpg",
+			Text:      extracted.Text,
+			HTML:      extracted.HTML,
+			WordCount: 10,
+		}
+
+		assert.Equal(t, expected, mainContent(extracted, "https://example.com/blog/coffee"))
+	})
+
+	t.Run("No main content", func(t *testing.T) {
+		assert.Nil(t, mainContent(nil, "https://example.com/"))
+	})
+}
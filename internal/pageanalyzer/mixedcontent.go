@@ -0,0 +1,98 @@
+package pageanalyzer
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+// passiveSources are the element attributes whose resources browsers still load over HTTP on an HTTPS page, with a
+// warning: plain images, audio, video and icons. Every other resource can change the page or its behaviour, so browsers
+// block it, including responsive images selected from a srcset or a <picture> source.
+var passiveSources = []string{"img src", "video poster", "video src", "audio src", "source src", "link href"}
+
+// passiveResourceTypes are the resource types whose passive sources are loaded with a warning. A <link href> is
+// passive only as an icon.
+var passiveResourceTypes = []string{htmlextract.ResourceTypeImage, htmlextract.ResourceTypeMedia, htmlextract.ResourceTypeIcon}
+
+// mixedContentExemptTypes are resources that are navigated to rather than loaded by the page. Form actions are
+// reported apart, they are only requested when the form is submitted.
+var mixedContentExemptTypes = []string{htmlextract.ResourceTypeArea}
+
+// MixedResource is an HTTP resource of an HTTPS page.
+type MixedResource struct {
+	*PageResource
+	HTTPSURL string
+	// HTTPSAvailable tells whether the resource can be loaded over HTTPS, which is the usual fix.
+	HTTPSAvailable bool
+}
+
+// MixedContentReport lists the HTTP resources of an HTTPS page.
+type MixedContentReport struct {
+	// Active resources, such as scripts, stylesheets and iframes, are blocked by browsers.
+	Active []*MixedResource
+	// Passive resources, such as images and media, are loaded with a warning.
+	Passive []*MixedResource
+	// Forms are submitted over HTTP, browsers warn before sending the data.
+	Forms []*MixedResource
+}
+
+// mixedContent reports the HTTP resources of the page and whether they are also served over HTTPS.
+// It returns nil if the page itself is not served over HTTPS.
+func (w *WebpageAnalyzer) mixedContent(ctx context.Context, pageURL string, resources []*PageResource) *MixedContentReport {
+	page, err := url.Parse(pageURL)
+	if err != nil || !strings.EqualFold(page.Scheme, "https") {
+		return nil
+	}
+
+	var (
+		mixedResources []*MixedResource
+		httpsURLs      []string
+	)
+	for _, resource := range resources {
+		if slices.Contains(mixedContentExemptTypes, resource.Type) {
+			continue
+		}
+
+		resourceURL, err := url.Parse(resource.URL)
+		if err != nil || !strings.EqualFold(resourceURL.Scheme, "http") {
+			continue
+		}
+
+		resourceURL.Scheme = "https"
+		mixedResources = append(mixedResources, &MixedResource{PageResource: resource, HTTPSURL: resourceURL.String()})
+		httpsURLs = append(httpsURLs, resourceURL.String())
+	}
+
+	report := &MixedContentReport{}
+	if len(mixedResources) == 0 {
+		return report
+	}
+
+	inaccessibleHTTPSURLs := w.inaccessibleLinks(ctx, httpsURLs)
+
+	for _, resource := range mixedResources {
+		resource.HTTPSAvailable = !inaccessibleHTTPSURLs[resource.HTTPSURL]
+
+		switch {
+		case resource.Type == htmlextract.ResourceTypeFormAction:
+			report.Forms = append(report.Forms, resource)
+		case isPassiveMixedContent(resource.PageResource):
+			report.Passive = append(report.Passive, resource)
+		default:
+			report.Active = append(report.Active, resource)
+		}
+	}
+
+	return report
+}
+
+// isPassiveMixedContent reports whether browsers load the HTTP resource with a warning instead of blocking it.
+func isPassiveMixedContent(resource *PageResource) bool {
+	return slices.Contains(passiveResourceTypes, resource.Type) &&
+		slices.Contains(passiveSources, resource.Element+" "+resource.Attr)
+}
@@ -0,0 +1,60 @@
+package pageanalyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestMixedContent(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer testServer.Close()
+
+	analyzer := New(&Config{}, testServer.Client())
+	httpURL := strings.Replace(testServer.URL, "https://", "http://", 1)
+
+	resources := []*PageResource{
+		{Type: htmlextract.ResourceTypeScript, URL: httpURL + "/app.js", Element: "script", Attr: "src"},
+		{Type: htmlextract.ResourceTypeImage, URL: httpURL + "/missing.png", Element: "img", Attr: "src"},
+		{Type: htmlextract.ResourceTypeImage, URL: httpURL + "/large.png", Element: "img", Attr: "srcset"},
+		{Type: htmlextract.ResourceTypeImage, URL: httpURL + "/wide.png", Element: "source", Attr: "srcset"},
+		{Type: htmlextract.ResourceTypeImage, URL: testServer.URL + "/secure.png", Element: "img", Attr: "src"},
+		{Type: htmlextract.ResourceTypeMedia, URL: httpURL + "/intro.mp4", Element: "source", Attr: "src"},
+		{Type: htmlextract.ResourceTypeFormAction, URL: httpURL + "/search", Element: "form", Attr: "action"},
+		{Type: htmlextract.ResourceTypeArea, URL: httpURL + "/region", Element: "area", Attr: "href"},
+	}
+
+	t.Run("HTTPS page", func(t *testing.T) {
+		report := analyzer.mixedContent(context.Background(), testServer.URL, resources)
+
+		// Responsive images are blockable, browsers only load plain images with a warning
+		require.Len(t, report.Active, 3)
+		assert.Equal(t, testServer.URL+"/app.js", report.Active[0].HTTPSURL)
+		assert.True(t, report.Active[0].HTTPSAvailable)
+		assert.Equal(t, httpURL+"/large.png", report.Active[1].URL)
+		assert.Equal(t, httpURL+"/wide.png", report.Active[2].URL)
+
+		require.Len(t, report.Passive, 2)
+		assert.Equal(t, httpURL+"/missing.png", report.Passive[0].URL)
+		assert.False(t, report.Passive[0].HTTPSAvailable)
+		assert.Equal(t, httpURL+"/intro.mp4", report.Passive[1].URL)
+
+		require.Len(t, report.Forms, 1)
+		assert.Equal(t, httpURL+"/search", report.Forms[0].URL)
+	})
+
+	t.Run("HTTP page", func(t *testing.T) {
+		assert.Nil(t, analyzer.mixedContent(context.Background(), httpURL, resources))
+	})
+}
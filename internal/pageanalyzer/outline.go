@@ -0,0 +1,79 @@
+package pageanalyzer
+
+import (
+	"fmt"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+// OutlineNode is a heading in the document outline, nested under the closest preceding heading of a higher level.
+type OutlineNode struct {
+	Level    int
+	Text     string
+	Children []*OutlineNode
+}
+
+// Outline is the heading tree of the page in document order.
+type Outline struct {
+	Roots  []*OutlineNode
+	Issues []string
+}
+
+func buildOutline(headings []htmlextract.Heading) *Outline {
+	outline := &Outline{}
+
+	var (
+		// stack holds the chain of open headings from the root to the last heading
+		stack     []*OutlineNode
+		h1Num     int
+		prevLevel int
+		prevLabel string
+	)
+
+	for _, heading := range headings {
+		node := &OutlineNode{Level: heading.Level, Text: heading.Text}
+		label := fmt.Sprintf("h%d %q", heading.Level, heading.Text)
+
+		if heading.Level == 1 {
+			h1Num++
+		}
+
+		if heading.Text == "" {
+			outline.Issues = append(outline.Issues, fmt.Sprintf("empty h%d heading", heading.Level))
+		}
+
+		if heading.Level > prevLevel+1 {
+			if prevLevel == 0 {
+				outline.Issues = append(outline.Issues, fmt.Sprintf("first heading %s is not h1", label))
+			} else {
+				outline.Issues = append(outline.Issues, fmt.Sprintf(
+					"%s follows %s, skipping h%d", label, prevLabel, prevLevel+1,
+				))
+			}
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			outline.Roots = append(outline.Roots, node)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+		}
+		stack = append(stack, node)
+
+		prevLevel = heading.Level
+		prevLabel = label
+	}
+
+	switch {
+	case h1Num == 0:
+		outline.Issues = append(outline.Issues, "missing h1 heading")
+	case h1Num > 1:
+		outline.Issues = append(outline.Issues, fmt.Sprintf("multiple h1 headings (%d)", h1Num))
+	}
+
+	return outline
+}
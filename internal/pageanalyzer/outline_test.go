@@ -0,0 +1,79 @@
+package pageanalyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestBuildOutline(t *testing.T) {
+	tests := []struct {
+		name           string
+		headings       []htmlextract.Heading
+		expectedRoots  []*OutlineNode
+		expectedIssues []string
+	}{
+		{
+			name: "Well formed",
+			headings: []htmlextract.Heading{
+				{Level: 1, Text: "Title"},
+				{Level: 2, Text: "Section 1"},
+				{Level: 3, Text: "Section 1.1"},
+				{Level: 2, Text: "Section 2"},
+			},
+			expectedRoots: []*OutlineNode{
+				{Level: 1, Text: "Title", Children: []*OutlineNode{
+					{Level: 2, Text: "Section 1", Children: []*OutlineNode{
+						{Level: 3, Text: "Section 1.1"},
+					}},
+					{Level: 2, Text: "Section 2"},
+				}},
+			},
+		},
+		{
+			name: "Skipped levels, empty and multiple h1",
+			headings: []htmlextract.Heading{
+				{Level: 1, Text: "Title"},
+				{Level: 2, Text: "Section"},
+				{Level: 4, Text: ""},
+				{Level: 1, Text: "Another Title"},
+			},
+			expectedRoots: []*OutlineNode{
+				{Level: 1, Text: "Title", Children: []*OutlineNode{
+					{Level: 2, Text: "Section", Children: []*OutlineNode{
+						{Level: 4, Text: ""},
+					}},
+				}},
+				{Level: 1, Text: "Another Title"},
+			},
+			expectedIssues: []string{
+				"empty h4 heading",
+				`h4 "" follows h2 "Section", skipping h3`,
+				"multiple h1 headings (2)",
+			},
+		},
+		{
+			name: "Missing h1",
+			headings: []htmlextract.Heading{
+				{Level: 2, Text: "Section"},
+			},
+			expectedRoots: []*OutlineNode{
+				{Level: 2, Text: "Section"},
+			},
+			expectedIssues: []string{
+				`first heading h2 "Section" is not h1`,
+				"missing h1 heading",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outline := buildOutline(tt.headings)
+			assert.Equal(t, tt.expectedRoots, outline.Roots)
+			assert.Equal(t, tt.expectedIssues, outline.Issues)
+		})
+	}
+}
@@ -1,12 +1,11 @@
 package pageanalyzer
 
 import (
-	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"net/url"
-	"strings"
 	"sync"
 	"time"
 
@@ -17,126 +16,452 @@ import (
 	"github.com/Rezab98/web-analyzer/pkg/slicetools"
 )
 
+// linkCheckConcurrency caps the number of links checked at the same time.
+const linkCheckConcurrency = 20
+
+type Config struct {
+	// MaxCrawlPages caps the number of internal pages downloaded in crawl mode
+	MaxCrawlPages int
+	// DefaultScopeMode is the link scope mode used when the options don't set one, ScopeHostWithWWW if it is empty
+	DefaultScopeMode string
+	// CertExpiryWarningDays is how many days before expiry a certificate is reported
+	CertExpiryWarningDays int
+	// VendorSignatures identify third-party vendors, the bundled database is used if it is nil
+	VendorSignatures []VendorSignature
+	// TechRules fingerprint the technologies of the page, the bundled rules are used if it is nil
+	TechRules []*TechRule
+}
+
 type WebpageAnalyzer struct {
+	cfg        *Config
 	httpClient *http.Client
+	tlsCache   *tlsCache
+
+	vendorSignatures []VendorSignature
+	techRules        []*TechRule
+}
+
+func New(cfg *Config, httpClient *http.Client) *WebpageAnalyzer {
+	if cfg.DefaultScopeMode == "" {
+		// Copy the config, so the caller's one is left untouched
+		defaultedCfg := *cfg
+		defaultedCfg.DefaultScopeMode = ScopeHostWithWWW
+		cfg = &defaultedCfg
+	}
+
+	vendorSignatures := cfg.VendorSignatures
+	if vendorSignatures == nil {
+		var err error
+		vendorSignatures, err = LoadVendorSignatures("")
+		if err != nil {
+			logrus.WithError(err).Error("load bundled vendor signatures failed")
+		}
+	}
+
+	techRules := cfg.TechRules
+	if techRules == nil {
+		var err error
+		techRules, err = LoadTechRules("")
+		if err != nil {
+			logrus.WithError(err).Error("load bundled technology rules failed")
+		}
+	}
+
+	return &WebpageAnalyzer{
+		cfg:              cfg,
+		httpClient:       httpClient,
+		tlsCache:         &tlsCache{},
+		vendorSignatures: vendorSignatures,
+		techRules:        techRules,
+	}
 }
 
-func New(httpClient *http.Client) *WebpageAnalyzer {
-	return &WebpageAnalyzer{httpClient: httpClient}
+// Options tune a single analysis.
+type Options struct {
+	// Crawl follows the internal links of the page to analyze the pages they point to
+	Crawl bool
+	// Scope decides which links are internal, the configured default mode is used if the mode is empty
+	Scope Scope
+	// InspectExternalTLS inspects the TLS connection of the hosts external links point to
+	InspectExternalTLS bool
+	// MainContentOnly limits the headings, outline and content metrics to the main content of the page
+	MainContentOnly bool
+}
+
+// Response is the HTTP response metadata of the analyzed page.
+type Response struct {
+	// FinalURL is the URL the page was served from after following redirects, the analyzed URL if it is empty
+	FinalURL string
+	// TransferredBytes is the size of the body before decoding its Content-Encoding, 0 if unknown
+	TransferredBytes int64
+	Header           http.Header
+	// TLS is nil if the page is not served over HTTPS
+	TLS *tls.ConnectionState
 }
 
 type Result struct {
 	HTMLVersion          string
 	Title                string
 	HeadingTagToTexts    map[string][]string
+	Outline              *Outline
 	HasLoginForm         bool
+	Forms                []*FormReport
 	InternalLinks        []string
 	ExternalLinks        []string
 	InaccessibleLinksNum int
+	LinkKindCounts       []LinkKindCount
+	LinkAttributes       *LinkAttributeReport
+	BrokenFragmentLinks  []string
+	Resources            *ResourceReport
+	MixedContent         *MixedContentReport
+	ThirdParties         *ThirdPartyReport
+	Technologies         []*Technology
+	Images               *ImageReport
+	PageWeight           *PageWeightReport
+	Compression          *CompressionReport
+	Caching              *CachingReport
+	CrawledPagesNum      int
+	Duplicates           *DuplicateReport
+	RedirectCanonical    *RedirectCanonicalReport
+	Hreflang             *HreflangReport
+	SocialPreview        *SocialPreview
+	StructuredData       *StructuredDataReport
+	Feeds                []*FeedReport
+	Content              *ContentMetrics
+	Conformance          *ConformanceReport
+	Accessibility        *AccessibilityReport
+	SecurityHeaders      *SecurityHeaderReport
+	TLS                  *TLSReport
+	ExternalTLS          []*TLSReport
+	MainContent          *MainContent
+	// SkippedChecks are the checks that download resources and were not started because the deadline passed
+	SkippedChecks []string
 }
 
-func (w *WebpageAnalyzer) Analyze(ctx context.Context, pageURL string, pageContent []byte) (*Result, error) {
+// Analyze analyzes the page content. The response is optional, checks based on it are skipped if it is nil.
+func (w *WebpageAnalyzer) Analyze(ctx context.Context, pageURL string, pageContent []byte, resp *Response, opts Options) (*Result, error) {
+	// The configured default is validated once at startup
+	if opts.Scope.Mode == "" {
+		opts.Scope.Mode = w.cfg.DefaultScopeMode
+	} else if err := opts.Scope.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid options: %v", err)
+	}
+
+	// Relative URLs of a redirected page resolve against the URL it was served from
+	baseURL := pageURL
+	if resp != nil && resp.FinalURL != "" {
+		baseURL = resp.FinalURL
+	}
+
 	// Initialize html extractor
 	htmlExtractor, err := htmlextract.New(pageContent)
 	if err != nil {
 		return nil, fmt.Errorf("initialize html extractor failed: %v", err)
 	}
 
+	// Isolate the article body from navigation, footers and banners
+	extractedMainContent := htmlExtractor.MainContent()
+	mainContentResult := mainContent(extractedMainContent, baseURL)
+
+	// Text based checks run on the main content only if requested and found
+	contentExtractor := htmlExtractor
+	if opts.MainContentOnly && extractedMainContent != nil {
+		contentExtractor, err = htmlextract.New([]byte(extractedMainContent.HTML))
+		if err != nil {
+			return nil, fmt.Errorf("initialize main content html extractor failed: %v", err)
+		}
+	}
+
 	// Extracts information from the HTML document
-	htmlVersion := HTMLVersion(pageContent)
+	doctype := parseDoctype(pageContent)
+	htmlVersion := doctypeVersion(doctype)
 	title := htmlExtractor.Title()
-	headingTagToTexts := htmlExtractor.HeadingTagToTexts()
+	headingTagToTexts := contentExtractor.HeadingTagToTexts()
+	outline := buildOutline(contentExtractor.Headings())
 	hasLoginForm := htmlExtractor.HasLoginForm()
-	allLinks, err := resolveRelativeLinks(htmlExtractor.Links(), pageURL)
-	if err != nil {
-		logrus.WithError(err).Error("resolveRelativeLinks failed")
+	forms := formReports(htmlExtractor.Forms(), baseURL)
+
+	// Classify links by scheme and kind, only web links can be checked and split to internal and external
+	classifiedLinks := classifyLinks(htmlExtractor.LinkElements(), baseURL)
+	linkKindCounts := countLinkKinds(classifiedLinks)
+	linkAttributes := linkAttributeReport(classifiedLinks, pageURL, opts.Scope)
+
+	// The web links are already resolved by the classification
+	var allLinks []string
+	for _, link := range classifiedLinks {
+		if link.isCheckable() {
+			allLinks = append(allLinks, link.URL)
+		}
+	}
+
+	// Collect the resources referenced by images, scripts, stylesheets and other elements
+	pageResources := resolveResources(htmlExtractor.Resources(), baseURL)
+
+	// Check links and resources together, so URLs referenced by both are requested once
+	checkedLinks := append([]string{}, allLinks...)
+	for _, resource := range pageResources {
+		checkedLinks = append(checkedLinks, resource.URL)
+	}
+	inaccessibleLinks := w.inaccessibleLinks(ctx, checkedLinks)
+
+	// Checks that download resources are skipped once the deadline passed, so they do not report every request of
+	// the page as failed
+	var skippedChecks []string
+	canStart := func(check string) bool {
+		if ctx.Err() != nil {
+			skippedChecks = append(skippedChecks, check)
+			return false
+		}
+		return true
 	}
 
 	// Get inaccessible links number
-	inaccessibleLinksNum := w.CountInaccessibleLinks(ctx, allLinks)
+	inaccessibleLinksNum := countInaccessible(allLinks, inaccessibleLinks)
+	resources := resourceReport(pageResources, inaccessibleLinks)
+
+	// Find the HTTP resources of an HTTPS page
+	var mixedContent *MixedContentReport
+	if canStart("Mixed content") {
+		mixedContent = w.mixedContent(ctx, baseURL, pageResources)
+	}
+
+	// Download the images to audit their size, dimensions, format and lazy-loading
+	pageImages := htmlExtractor.Images()
+	var (
+		images           *ImageReport
+		downloadedImages map[string]*imageData
+	)
+	if canStart("Images") {
+		images = w.imageReport(ctx, pageImages, baseURL)
+		downloadedImages = images.downloaded
+	}
+
+	// Download the resources of the page to measure the page weight and build the waterfall
+	var (
+		pageWeight  *PageWeightReport
+		compression *CompressionReport
+		caching     *CachingReport
+	)
+	if canStart("Page weight") {
+		pageWeight = w.pageWeight(ctx, baseURL, pageContent, resp, pageResources, pageImages, downloadedImages)
+		caching = cachingReport(pageWeight.Resources, time.Now())
+
+		// Find the content encodings the server supports
+		if canStart("Compression") {
+			compression = w.compressionReport(ctx, pageWeight.Resources)
+		}
+	}
+
+	// Group the third-party resources by domain and vendor
+	thirdParties := thirdPartyReport(pageResources, baseURL, w.vendorSignatures)
+
+	// Fingerprint the CMS, frameworks, server and CDN
+	techInput := techInput{metaTags: htmlExtractor.MetaTags(), html: pageContent}
+	if resp != nil {
+		techInput.header = resp.Header
+	}
+	for _, resource := range pageResources {
+		if resource.Type == htmlextract.ResourceTypeScript {
+			techInput.scriptSrcs = append(techInput.scriptSrcs, resource.URL)
+		}
+	}
+	technologies := detectTechnologies(w.techRules, techInput)
+
+	// Site-wide checks compare the analyzed page with the crawled pages
+	analyzedPage := &crawledPage{
+		URL:        normalizeURL(pageURL),
+		StatusCode: http.StatusOK,
+		FinalURL:   normalizeURL(baseURL),
+		NoIndex:    pageNoIndex(htmlExtractor.MetaTags()),
+		BodyHash:   bodyHash(pageContent),
+		Links:      navigationalLinks(classifiedLinks),
+		extractor:  htmlExtractor,
+	}
+	if resp != nil {
+		analyzedPage.NoIndex = analyzedPage.NoIndex || hasNoIndex(resp.Header.Values("X-Robots-Tag"))
+	}
+
+	// Download the internal pages in crawl mode
+	var crawledPages map[string]*crawledPage
+	if opts.Crawl && canStart("Crawl") {
+		crawledPages = w.crawl(ctx, analyzedPage, allLinks, opts.Scope)
+	}
+
+	comparedPages := []*crawledPage{analyzedPage}
+	for _, page := range crawledPages {
+		comparedPages = append(comparedPages, page)
+	}
+
+	var (
+		duplicates        *DuplicateReport
+		redirectCanonical *RedirectCanonicalReport
+	)
+	if opts.Crawl {
+		// Group the pages by duplicate content, titles, meta descriptions and H1s
+		duplicates = duplicateReport(comparedPages)
+
+		// Find links to redirects, redirect chains and inconsistent canonicals
+		if canStart("Redirects and canonicals") {
+			redirectCanonical = w.redirectCanonicalReport(ctx, comparedPages)
+		}
+	}
+
+	// Validate the hreflang sets, of the crawled pages too in crawl mode
+	var hreflang *HreflangReport
+	if canStart("Hreflang") {
+		hreflang = w.hreflangReport(ctx, comparedPages)
+	}
+
+	// Check link fragments against the anchors of the target pages
+	fragmentLinks := brokenFragmentLinks(classifiedLinks, htmlExtractor.Anchors(), crawledPages)
+
+	// Build the share preview from OpenGraph and Twitter Card metadata
+	socialPreview := w.socialPreview(ctx, htmlExtractor.SocialMeta(), baseURL, title)
+
+	// Validate JSON-LD, Microdata and RDFa items
+	structuredData := validateStructuredData(htmlExtractor.StructuredData())
+
+	// Download and validate the RSS, Atom and JSON feeds announced by the page
+	var feeds []*FeedReport
+	if pageFeeds := htmlExtractor.Feeds(); len(pageFeeds) > 0 && canStart("Feeds") {
+		feeds = w.feedReports(ctx, pageFeeds, baseURL)
+	}
+
+	// Measure the visible text, its readability, language and keywords
+	content := contentMetrics(contentExtractor.TextBlocks(), htmlExtractor.Lang(), len(pageContent))
+
+	// Check the doctype and markup for conformance issues
+	contentType := ""
+	if resp != nil {
+		contentType = resp.Header.Get("Content-Type")
+	}
+	conformance := checkConformance(pageContent, doctype, contentType)
+
+	// Run the accessibility audit
+	accessibility := accessibilityReport(htmlExtractor.AccessibilityFindings())
+
+	// Grade the security headers and cookies of the response
+	var securityHeaders *SecurityHeaderReport
+	if resp != nil {
+		securityHeaders = securityHeaderReport(resp.Header, baseURL)
+	}
+
+	// Inspect the TLS connection of the page
+	var tlsReport *TLSReport
+	if resp != nil && resp.TLS != nil {
+		tlsReport = inspectTLS(resp.TLS, tlsServerName(resp.TLS, baseURL), w.rootCAs(), time.Now(), w.cfg.CertExpiryWarningDays)
+	}
 
 	// Extract internal links
 	internalLinks := slicetools.Filter(
 		allLinks, func(link string) bool {
-			return isInternalLink(link, pageURL)
+			return opts.Scope.IsInternal(link, pageURL)
 		},
 	)
 
 	// Extract external links
 	externalLinks := slicetools.Filter(
 		allLinks, func(link string) bool {
-			return !isInternalLink(link, pageURL)
+			return !opts.Scope.IsInternal(link, pageURL)
 		},
 	)
 
+	// Optionally inspect the TLS connection of the external link hosts
+	var externalTLS []*TLSReport
+	if opts.InspectExternalTLS && canStart("External TLS") {
+		externalTLS = w.inspectExternalTLS(ctx, externalLinks)
+	}
+
 	return &Result{
 		HTMLVersion:          htmlVersion,
 		Title:                title,
 		HeadingTagToTexts:    headingTagToTexts,
+		Outline:              outline,
 		InternalLinks:        internalLinks,
 		ExternalLinks:        externalLinks,
 		InaccessibleLinksNum: inaccessibleLinksNum,
+		LinkKindCounts:       linkKindCounts,
+		LinkAttributes:       linkAttributes,
+		BrokenFragmentLinks:  fragmentLinks,
+		Resources:            resources,
+		MixedContent:         mixedContent,
+		ThirdParties:         thirdParties,
+		Technologies:         technologies,
+		Images:               images,
+		PageWeight:           pageWeight,
+		Compression:          compression,
+		Caching:              caching,
+		CrawledPagesNum:      len(crawledPages),
+		Duplicates:           duplicates,
+		RedirectCanonical:    redirectCanonical,
+		Hreflang:             hreflang,
 		HasLoginForm:         hasLoginForm,
+		Forms:                forms,
+		SocialPreview:        socialPreview,
+		StructuredData:       structuredData,
+		Feeds:                feeds,
+		Content:              content,
+		Conformance:          conformance,
+		Accessibility:        accessibility,
+		SecurityHeaders:      securityHeaders,
+		TLS:                  tlsReport,
+		ExternalTLS:          externalTLS,
+		MainContent:          mainContentResult,
+		SkippedChecks:        skippedChecks,
 	}, nil
 }
 
+// HTMLVersion returns the HTML version declared by the doctype of the page.
 func HTMLVersion(pageContent []byte) string {
-	// Read only the first 1024 bytes to identify the doctype and root elements
-
-	// Helper function to get the minimum of two integers
-	min := func(a, b int) int {
-		if a < b {
-			return a
-		}
-		return b
-	}
-
-	initialContent := bytes.ToLower(pageContent[:min(len(pageContent), 1024)])
-
-	// Convert the relevant portion to a string for easier processing
-	initialContentStr := string(initialContent)
-
-	// Check for HTML5 doctype
-	if strings.Contains(initialContentStr, "<!doctype html>") {
-		return "HTML5"
-	}
-
-	// Check for XHTML doctypes
-	if strings.Contains(initialContentStr, `<!doctype html public "-//w3c//dtd xhtml 1.0`) {
-		return "XHTML 1.0"
-	}
-	if strings.Contains(initialContentStr, `<!doctype html public "-//w3c//dtd xhtml 1.1`) {
-		return "XHTML 1.1"
-	}
+	return doctypeVersion(parseDoctype(pageContent))
+}
 
-	// Check for older HTML versions
-	if strings.Contains(initialContentStr, "<html>") || strings.Contains(initialContentStr, "<html ") {
+func doctypeVersion(doctype *Doctype) string {
+	switch {
+	case doctype.Found:
+		return doctype.Version
+	case doctype.HasHTMLElement:
+		// Pages without a doctype predate HTML5
 		return "HTML4 or Earlier"
+	default:
+		return "Unknown"
 	}
-
-	return "Unknown"
 }
 
 // CountInaccessibleLinks sends concurrent HEAD requests to each link and returns the count of inaccessible links.
 func (w *WebpageAnalyzer) CountInaccessibleLinks(ctx context.Context, links []string) int {
+	return countInaccessible(links, w.inaccessibleLinks(ctx, links))
+}
+
+// inaccessibleLinks sends concurrent HEAD requests to each distinct link and returns the set of inaccessible links.
+func (w *WebpageAnalyzer) inaccessibleLinks(ctx context.Context, links []string) map[string]bool {
 	var (
-		inaccessibleLinkNum int
-		lock                sync.Mutex
+		inaccessibleLinks = make(map[string]bool)
+		checkedLinks      = make(map[string]bool)
+		lock              sync.Mutex
+		semaphore         = make(chan struct{}, linkCheckConcurrency)
 	)
 
 	var wg sync.WaitGroup
 	for _, link := range links {
+		if checkedLinks[link] {
+			continue
+		}
+		checkedLinks[link] = true
+
 		wg.Add(1)
+		semaphore <- struct{}{}
 
 		go func(link string) {
 			defer wg.Done()
+			defer func() { <-semaphore }()
 
 			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 			defer cancel()
 
 			if !w.isLinkAccessible(ctx, link) {
 				lock.Lock()
-				inaccessibleLinkNum++
+				inaccessibleLinks[link] = true
 				lock.Unlock()
 			}
 		}(link)
@@ -144,6 +469,17 @@ func (w *WebpageAnalyzer) CountInaccessibleLinks(ctx context.Context, links []st
 
 	wg.Wait()
 
+	return inaccessibleLinks
+}
+
+func countInaccessible(links []string, inaccessibleLinks map[string]bool) int {
+	var inaccessibleLinkNum int
+	for _, link := range links {
+		if inaccessibleLinks[link] {
+			inaccessibleLinkNum++
+		}
+	}
+
 	return inaccessibleLinkNum
 }
 
@@ -176,24 +512,9 @@ func (w *WebpageAnalyzer) isLinkAccessible(ctx context.Context, link string) boo
 	return !slices.Contains(inaccessibleStatusCodes, resp.StatusCode)
 }
 
+// isInternalLink compares the hosts of the link and the base URL, ignoring the www prefix.
 func isInternalLink(href, baseURL string) bool {
-	base, err := url.Parse(baseURL)
-	if err != nil {
-		logrus.Errorf("Error parsing baseURL: %v", err)
-		return false
-	}
-
-	link, err := url.Parse(href)
-	if err != nil {
-		logrus.Errorf("Error parsing baseURL: %v", err)
-		return false
-	}
-
-	// Remove www prefix from the href's host for comparison
-	linkHost := strings.TrimPrefix(link.Host, "www.")
-
-	// Compare the host of the base URL and the resolved URL
-	return base.Host == linkHost
+	return Scope{Mode: ScopeHostWithWWW}.IsInternal(href, baseURL)
 }
 
 // resolveRelativeLinks converts all relative links to absolute links based on the base URL's host.
@@ -221,3 +542,18 @@ func resolveRelativeLinks(links []string, baseURL string) ([]string, error) {
 
 	return resolvedLinks, nil
 }
+
+// resolveLink converts a single relative link to an absolute link based on the base URL.
+func resolveLink(link, baseURL string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("error parsing baseURL: %v", err)
+	}
+
+	parsedLink, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("error parsing link: %v", err)
+	}
+
+	return base.ResolveReference(parsedLink).String(), nil
+}
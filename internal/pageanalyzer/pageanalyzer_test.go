@@ -1,6 +1,9 @@
 package pageanalyzer
 
 import (
+	"context"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -63,6 +66,31 @@ func TestHTMLVersion(t *testing.T) {
 			`),
 			expected: "HTML4 or Earlier",
 		},
+		{
+			name:        "HTML5 with extra whitespace",
+			htmlContent: []byte("<!DOCTYPE   html  >\n<html></html>"),
+			expected:    "HTML5",
+		},
+		{
+			name:        "HTML 4.01 Strict",
+			htmlContent: []byte(`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd"><html></html>`),
+			expected:    "HTML 4.01",
+		},
+		{
+			name:        "Doctype after a long comment",
+			htmlContent: []byte("<!-- " + strings.Repeat("license ", 200) + "-->\n<!doctype html><html></html>"),
+			expected:    "HTML5",
+		},
+		{
+			name:        "Doctype after a byte order mark",
+			htmlContent: []byte("\uFEFF<!DOCTYPE html>\n<html></html>"),
+			expected:    "HTML5",
+		},
+		{
+			name:        "No markup",
+			htmlContent: []byte("plain text"),
+			expected:    "Unknown",
+		},
 	}
 
 	for _, tt := range tests {
@@ -129,3 +157,43 @@ func TestResolveRelativeLinks(t *testing.T) {
 
 	assert.Equal(t, expected, resolvedLinks)
 }
+
+func TestAnalyze_SkipsChecksAfterDeadline(t *testing.T) {
+	// A zero config falls back to the default link scope
+	analyzer := New(&Config{}, http.DefaultClient)
+	assert.Equal(t, ScopeHostWithWWW, analyzer.cfg.DefaultScopeMode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	content := []byte(`<!DOCTYPE html><html><head><title>Test</title></head><body><img src="/logo.png"></body></html>`)
+	result, err := analyzer.Analyze(ctx, "https://example.com/", content, nil, Options{})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	assert.Equal(t, []string{"Mixed content", "Images", "Page weight", "Hreflang"}, result.SkippedChecks)
+	assert.Nil(t, result.Images)
+	assert.Nil(t, result.PageWeight)
+	assert.Equal(t, "Test", result.Title)
+}
+
+func TestAnalyze_ResolvesAgainstFinalURL(t *testing.T) {
+	analyzer := New(&Config{}, http.DefaultClient)
+
+	// The checks that download resources are skipped, only the resolution is tested
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	content := []byte(`<!DOCTYPE html><html><body><a href="intro">Intro</a><img src="logo.png"></body></html>`)
+	resp := &Response{FinalURL: "https://example.com/docs/", Header: http.Header{}}
+	result, err := analyzer.Analyze(ctx, "https://example.com/docs", content, resp, Options{})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	assert.Equal(t, []string{"https://example.com/docs/intro"}, result.InternalLinks)
+	if assert.Len(t, result.Resources.Types, 1) {
+		assert.Equal(t, "https://example.com/docs/logo.png", result.Resources.Types[0].Resources[0].URL)
+	}
+}
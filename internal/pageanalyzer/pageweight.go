@@ -0,0 +1,583 @@
+package pageanalyzer
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptrace"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+const (
+	// pageWeightConcurrency limits the parallel resource downloads, like the per-host connection limit of browsers.
+	pageWeightConcurrency = 6
+
+	// maxResourceBytes caps how much of a resource is downloaded to measure its weight.
+	maxResourceBytes = 20 << 20
+
+	// maxStylesheetDepth limits how deep @import chains of stylesheets are followed.
+	maxStylesheetDepth = 3
+
+	// pageWeightAcceptEncoding are the encodings that can be decoded to measure the uncompressed size.
+	pageWeightAcceptEncoding = "gzip, deflate"
+)
+
+// Page weight types
+const (
+	WeightTypeHTML  = "HTML"
+	WeightTypeJS    = "JS"
+	WeightTypeCSS   = "CSS"
+	WeightTypeImage = "Image"
+	WeightTypeFont  = "Font"
+	WeightTypeOther = "Other"
+)
+
+// WeightTypes is the order page weight types are reported in.
+var WeightTypes = []string{WeightTypeHTML, WeightTypeJS, WeightTypeCSS, WeightTypeImage, WeightTypeFont, WeightTypeOther}
+
+// resourceWeightTypes maps the resource types a browser downloads while loading the page to their weight type.
+// Media, embeds and form actions are not downloaded up front, images are taken from the image inventory, so only
+// the source the browser picks is counted.
+var resourceWeightTypes = map[string]string{
+	htmlextract.ResourceTypeScript:     WeightTypeJS,
+	htmlextract.ResourceTypeStylesheet: WeightTypeCSS,
+	htmlextract.ResourceTypeIFrame:     WeightTypeHTML,
+	htmlextract.ResourceTypeIcon:       WeightTypeImage,
+	htmlextract.ResourceTypePreload:    "",
+}
+
+// fontExtensions identify fonts served with a generic Content-Type.
+var fontExtensions = []string{".woff", ".woff2", ".ttf", ".otf", ".eot"}
+
+var (
+	cssImportRegex = regexp.MustCompile(`@import\s+(?:url\(\s*)?["']?([^"')\s;]+)`)
+	cssURLRegex    = regexp.MustCompile(`url\(\s*["']?([^"')]+?)["']?\s*\)`)
+)
+
+// ResourceTiming is the timeline of a resource download. DNS, Connect and TLS are zero for reused connections.
+type ResourceTiming struct {
+	// Start is the time since the request of the page started
+	Start    time.Duration
+	DNS      time.Duration
+	Connect  time.Duration
+	TLS      time.Duration
+	TTFB     time.Duration
+	Download time.Duration
+	Total    time.Duration
+}
+
+// WeightedResource is a downloaded page resource with its size, timing and cache headers.
+type WeightedResource struct {
+	URL  string
+	Type string
+	// Initiator is the URL of the stylesheet that references the resource, empty for resources of the page
+	Initiator       string
+	StatusCode      int
+	ContentType     string
+	ContentEncoding string
+	// CompressedBytes is the size of the body as transferred, UncompressedBytes after decoding it
+	CompressedBytes   int64
+	UncompressedBytes int64
+	CacheControl      string
+	Expires           string
+	ETag              string
+	LastModified      string
+	Vary              string
+	Timing            ResourceTiming
+	Error             string
+}
+
+// WeightTypeTotal is the weight of the resources of a single type.
+type WeightTypeTotal struct {
+	Type              string
+	Count             int
+	CompressedBytes   int64
+	UncompressedBytes int64
+}
+
+// PageWeightReport is the weight of the page and its resources, with the resources ordered by start time.
+type PageWeightReport struct {
+	Resources         []*WeightedResource
+	Types             []*WeightTypeTotal
+	CompressedBytes   int64
+	UncompressedBytes int64
+	// Duration is the time until the last resource finished downloading
+	Duration time.Duration
+}
+
+// weightRequest is a resource to download for the page weight.
+type weightRequest struct {
+	url          string
+	expectedType string
+	initiator    string
+}
+
+// pageWeight measures the weight and timing of the page and its resources, the way a browser loads them. The page
+// is taken from the response and the images from the image report, the other resources are downloaded. The
+// resources referenced by stylesheets, such as fonts, background images and imports, are downloaded once their
+// stylesheet finished. The page is downloaded again if the response is unknown.
+func (w *WebpageAnalyzer) pageWeight(ctx context.Context, pageURL string, pageContent []byte, resp *Response, resources []*PageResource, images []htmlextract.Image, downloadedImages map[string]*imageData) *PageWeightReport {
+	report := &PageWeightReport{}
+
+	// The timeline starts with the first image the image report downloaded
+	start := time.Now()
+	for _, data := range downloadedImages {
+		if data.requested.Before(start) {
+			start = data.requested
+		}
+	}
+
+	if resp != nil {
+		report.Resources = append(report.Resources, responseDocument(pageURL, pageContent, resp))
+	} else {
+		document, _ := w.downloadWeightedResource(ctx, weightRequest{url: pageURL, expectedType: WeightTypeHTML}, start)
+		report.Resources = append(report.Resources, document)
+	}
+
+	seen := map[string]bool{pageURL: true}
+	var requests []weightRequest
+	add := func(request weightRequest) {
+		if seen[request.url] {
+			return
+		}
+		seen[request.url] = true
+		requests = append(requests, request)
+	}
+
+	for _, resource := range resources {
+		if weightType, ok := resourceWeightTypes[resource.Type]; ok {
+			add(weightRequest{url: resource.URL, expectedType: weightType})
+		}
+	}
+
+	// The browser loads one source per image, take the first one it considers
+	for _, img := range images {
+		for _, source := range img.Sources {
+			imageURL, ok := resolveResourceURL(source.URL, pageURL)
+			if !ok {
+				continue
+			}
+
+			if data, downloaded := downloadedImages[imageURL]; downloaded && !seen[imageURL] {
+				seen[imageURL] = true
+				report.Resources = append(report.Resources, downloadedImage(imageURL, data, start))
+			}
+			add(weightRequest{url: imageURL, expectedType: WeightTypeImage})
+			break
+		}
+	}
+
+	for depth := 0; len(requests) > 0 && depth <= maxStylesheetDepth; depth++ {
+		var stylesheets map[string][]byte
+		report.Resources, stylesheets = w.downloadWeightedResources(ctx, requests, start, report.Resources)
+
+		requests = nil
+		for stylesheetURL, css := range stylesheets {
+			for _, reference := range cssReferences(css) {
+				resolvedURL, ok := resolveResourceURL(reference.url, stylesheetURL)
+				if !ok {
+					continue
+				}
+				add(weightRequest{url: resolvedURL, expectedType: reference.expectedType, initiator: stylesheetURL})
+			}
+		}
+
+		// Keep the order stable, the stylesheets are collected in a map
+		slices.SortFunc(requests, func(a, b weightRequest) int {
+			return strings.Compare(a.initiator+" "+a.url, b.initiator+" "+b.url)
+		})
+	}
+
+	slices.SortStableFunc(report.Resources, func(a, b *WeightedResource) int {
+		switch {
+		case a.Timing.Start < b.Timing.Start:
+			return -1
+		case a.Timing.Start > b.Timing.Start:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	typeToTotal := make(map[string]*WeightTypeTotal)
+	for _, resource := range report.Resources {
+		if end := resource.Timing.Start + resource.Timing.Total; end > report.Duration {
+			report.Duration = end
+		}
+		if resource.Error != "" {
+			continue
+		}
+
+		total, ok := typeToTotal[resource.Type]
+		if !ok {
+			total = &WeightTypeTotal{Type: resource.Type}
+			typeToTotal[resource.Type] = total
+		}
+		total.Count++
+		total.CompressedBytes += resource.CompressedBytes
+		total.UncompressedBytes += resource.UncompressedBytes
+
+		report.CompressedBytes += resource.CompressedBytes
+		report.UncompressedBytes += resource.UncompressedBytes
+	}
+
+	for _, weightType := range WeightTypes {
+		if total, ok := typeToTotal[weightType]; ok {
+			report.Types = append(report.Types, total)
+		}
+	}
+
+	return report
+}
+
+// downloadWeightedResources downloads the resources concurrently and appends them to downloaded.
+// It returns the content of the downloaded stylesheets by URL, to find the resources they reference.
+func (w *WebpageAnalyzer) downloadWeightedResources(ctx context.Context, requests []weightRequest, start time.Time, downloaded []*WeightedResource) ([]*WeightedResource, map[string][]byte) {
+	var (
+		stylesheets = make(map[string][]byte)
+		lock        sync.Mutex
+		semaphore   = make(chan struct{}, pageWeightConcurrency)
+		wg          sync.WaitGroup
+	)
+
+	for _, request := range requests {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(request weightRequest) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			resource, content := w.downloadWeightedResource(ctx, request, start)
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			downloaded = append(downloaded, resource)
+			if resource.Type == WeightTypeCSS && content != nil {
+				stylesheets[resource.URL] = content
+			}
+		}(request)
+	}
+
+	wg.Wait()
+
+	return downloaded, stylesheets
+}
+
+// downloadWeightedResource downloads the resource and measures its size and timing.
+// The decoded content is returned for stylesheets only.
+func (w *WebpageAnalyzer) downloadWeightedResource(ctx context.Context, request weightRequest, start time.Time) (*WeightedResource, []byte) {
+	resource := &WeightedResource{
+		URL:       request.url,
+		Type:      request.expectedType,
+		Initiator: request.initiator,
+	}
+	if resource.Type == "" {
+		resource.Type = WeightTypeOther
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var (
+		requestStart                                        = time.Now()
+		dnsStart, connectStart, tlsStart, firstByte, finish time.Time
+		// The connection callbacks may run on the goroutine that dials
+		timingLock sync.Mutex
+	)
+	resource.Timing.Start = requestStart.Sub(start)
+
+	measure := func(timing *time.Duration, since *time.Time) {
+		timingLock.Lock()
+		defer timingLock.Unlock()
+		*timing = time.Since(*since)
+	}
+	mark := func(at *time.Time) {
+		timingLock.Lock()
+		defer timingLock.Unlock()
+		*at = time.Now()
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { mark(&dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { measure(&resource.Timing.DNS, &dnsStart) },
+		ConnectStart:         func(string, string) { mark(&connectStart) },
+		ConnectDone:          func(string, string, error) { measure(&resource.Timing.Connect, &connectStart) },
+		TLSHandshakeStart:    func() { mark(&tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { measure(&resource.Timing.TLS, &tlsStart) },
+		GotFirstResponseByte: func() { mark(&firstByte) },
+	}
+
+	defer func() {
+		timingLock.Lock()
+		defer timingLock.Unlock()
+
+		if finish.IsZero() {
+			finish = time.Now()
+		}
+		resource.Timing.Total = finish.Sub(requestStart)
+		if !firstByte.IsZero() {
+			resource.Timing.TTFB = firstByte.Sub(requestStart)
+			resource.Timing.Download = finish.Sub(firstByte)
+		}
+	}()
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, request.url, nil /* body */)
+	if err != nil {
+		resource.Error = fmt.Sprintf("new request with context failed: %v", err)
+		return resource, nil
+	}
+
+	// Setting the header disables the transparent decompression of the client, so the transferred size is known
+	req.Header.Set("Accept-Encoding", pageWeightAcceptEncoding)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		resource.Error = fmt.Sprintf("send request failed: %v", err)
+		return resource, nil
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxResourceBytes))
+	finish = time.Now()
+	if err != nil {
+		resource.Error = fmt.Sprintf("read response body failed: %v", err)
+		return resource, nil
+	}
+
+	resource.StatusCode = resp.StatusCode
+	setResponseHeaders(resource, resp.Header)
+	resource.CompressedBytes = int64(len(content))
+	resource.Type = weightType(resource.ContentType, request.url, resource.Type)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		resource.Error = fmt.Sprintf("request failed with status %d", resp.StatusCode)
+		return resource, nil
+	}
+
+	decoded, err := decodeContent(content, resource.ContentEncoding)
+	if err != nil {
+		// The size after decoding is unknown, count the transferred size
+		resource.UncompressedBytes = resource.CompressedBytes
+		return resource, nil
+	}
+	resource.UncompressedBytes = int64(len(decoded))
+
+	if resource.Type != WeightTypeCSS {
+		return resource, nil
+	}
+
+	return resource, decoded
+}
+
+// responseDocument is the weight of the page from the response it was analyzed from. Its timing is unknown, the
+// page was downloaded before the analysis.
+func responseDocument(pageURL string, pageContent []byte, resp *Response) *WeightedResource {
+	document := &WeightedResource{
+		URL:               pageURL,
+		Type:              WeightTypeHTML,
+		StatusCode:        http.StatusOK,
+		CompressedBytes:   resp.TransferredBytes,
+		UncompressedBytes: int64(len(pageContent)),
+	}
+	setResponseHeaders(document, resp.Header)
+
+	if document.CompressedBytes == 0 {
+		document.CompressedBytes = document.UncompressedBytes
+	}
+
+	return document
+}
+
+// downloadedImage is the weight of an image downloaded by the image report. Images are stored compressed, so the
+// transferred and decoded sizes are the same.
+func downloadedImage(imageURL string, data *imageData, start time.Time) *WeightedResource {
+	resource := &WeightedResource{
+		URL:               imageURL,
+		Type:              WeightTypeImage,
+		StatusCode:        data.statusCode,
+		CompressedBytes:   data.bytes,
+		UncompressedBytes: data.bytes,
+		Timing:            ResourceTiming{Start: data.requested.Sub(start), Total: data.duration},
+	}
+	if data.header != nil {
+		setResponseHeaders(resource, data.header)
+		resource.ContentEncoding = data.contentEncoding
+		resource.Type = weightType(resource.ContentType, imageURL, WeightTypeImage)
+	}
+	if data.err != nil {
+		resource.Error = data.err.Error()
+	}
+
+	return resource
+}
+
+// setResponseHeaders copies the content and cache headers of the response to the resource.
+func setResponseHeaders(resource *WeightedResource, header http.Header) {
+	resource.ContentType = header.Get("Content-Type")
+	resource.ContentEncoding = strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding")))
+	resource.CacheControl = header.Get("Cache-Control")
+	resource.Expires = header.Get("Expires")
+	resource.ETag = header.Get("ETag")
+	resource.LastModified = header.Get("Last-Modified")
+	resource.Vary = strings.Join(header.Values("Vary"), ", ")
+}
+
+// decodeContent decodes a body with the given Content-Encoding.
+func decodeContent(content []byte, contentEncoding string) ([]byte, error) {
+	var reader io.Reader
+	switch contentEncoding {
+	case "", "identity":
+		return content, nil
+	case "gzip", "x-gzip":
+		gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("new gzip reader failed: %v", err)
+		}
+		reader = gzipReader
+	case "deflate":
+		// Deflate is meant to be zlib wrapped, but some servers send raw deflate
+		zlibReader, err := zlib.NewReader(bytes.NewReader(content))
+		if err != nil {
+			reader = flate.NewReader(bytes.NewReader(content))
+		} else {
+			reader = zlibReader
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", contentEncoding)
+	}
+
+	decoded, err := io.ReadAll(io.LimitReader(reader, maxResourceBytes))
+	if err != nil {
+		return nil, fmt.Errorf("decode %s content failed: %v", contentEncoding, err)
+	}
+
+	return decoded, nil
+}
+
+// weightType returns the weight type of a resource from its Content-Type, falling back to the URL extension and
+// then to the type expected from the element that references it.
+func weightType(contentType, resourceURL, expectedType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return WeightTypeHTML
+	case strings.Contains(mediaType, "javascript") || strings.Contains(mediaType, "ecmascript"):
+		return WeightTypeJS
+	case mediaType == "text/css":
+		return WeightTypeCSS
+	case strings.HasPrefix(mediaType, "image/"):
+		return WeightTypeImage
+	case strings.HasPrefix(mediaType, "font/") || strings.Contains(mediaType, "font") ||
+		mediaType == "application/vnd.ms-fontobject":
+		return WeightTypeFont
+	}
+
+	if resourcePath, _, _ := strings.Cut(resourceURL, "?"); slices.Contains(fontExtensions, strings.ToLower(path.Ext(resourcePath))) {
+		return WeightTypeFont
+	}
+
+	if expectedType != "" {
+		return expectedType
+	}
+
+	return WeightTypeOther
+}
+
+// cssReference is a URL referenced by a stylesheet.
+type cssReference struct {
+	url          string
+	expectedType string
+}
+
+// cssReferences returns the imported stylesheets and the url() values of a stylesheet, such as fonts and
+// background images.
+func cssReferences(css []byte) []cssReference {
+	var references []cssReference
+
+	imports := make(map[string]bool)
+	for _, match := range cssImportRegex.FindAllSubmatch(css, -1) {
+		imports[string(match[1])] = true
+		references = append(references, cssReference{url: string(match[1]), expectedType: WeightTypeCSS})
+	}
+
+	for _, match := range cssURLRegex.FindAllSubmatch(css, -1) {
+		reference := strings.TrimSpace(string(match[1]))
+		if imports[reference] || strings.HasPrefix(reference, "#") {
+			continue
+		}
+
+		expectedType := WeightTypeImage
+		if resourcePath, _, _ := strings.Cut(reference, "?"); slices.Contains(fontExtensions, strings.ToLower(path.Ext(resourcePath))) {
+			expectedType = WeightTypeFont
+		}
+		references = append(references, cssReference{url: reference, expectedType: expectedType})
+	}
+
+	return references
+}
+
+// OffsetPercent returns the start of the resource as a percentage of the page load duration, for the waterfall.
+func (r *PageWeightReport) OffsetPercent(resource *WeightedResource) float64 {
+	if r.Duration == 0 {
+		return 0
+	}
+
+	return float64(resource.Timing.Start) / float64(r.Duration) * 100
+}
+
+// WidthPercent returns the duration of the resource as a percentage of the page load duration, for the waterfall.
+func (r *PageWeightReport) WidthPercent(resource *WeightedResource) float64 {
+	if r.Duration == 0 {
+		return 0
+	}
+
+	return float64(resource.Timing.Total) / float64(r.Duration) * 100
+}
+
+// CompressedSize returns the formatted transferred size of the resource.
+func (r *WeightedResource) CompressedSize() string {
+	return formatBytes(r.CompressedBytes)
+}
+
+// UncompressedSize returns the formatted decoded size of the resource.
+func (r *WeightedResource) UncompressedSize() string {
+	return formatBytes(r.UncompressedBytes)
+}
+
+// CompressedSize returns the formatted transferred size of the resources of the type.
+func (t *WeightTypeTotal) CompressedSize() string {
+	return formatBytes(t.CompressedBytes)
+}
+
+// UncompressedSize returns the formatted decoded size of the resources of the type.
+func (t *WeightTypeTotal) UncompressedSize() string {
+	return formatBytes(t.UncompressedBytes)
+}
+
+// CompressedSize returns the formatted transferred size of the page.
+func (r *PageWeightReport) CompressedSize() string {
+	return formatBytes(r.CompressedBytes)
+}
+
+// UncompressedSize returns the formatted decoded size of the page.
+func (r *PageWeightReport) UncompressedSize() string {
+	return formatBytes(r.UncompressedBytes)
+}
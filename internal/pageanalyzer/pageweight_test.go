@@ -0,0 +1,175 @@
+package pageanalyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestPageWeight(t *testing.T) {
+	css := `@import url("/theme.css"); body { background: url(img/bg.png); } @font-face { src: url('/fonts/a.woff2?v=1') format("woff2"), url(data:font/woff2;base64,AAAA); }`
+
+	var gzipped bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	_, _ = gzipWriter.Write([]byte(css))
+	_ = gzipWriter.Close()
+
+	files := map[string]struct {
+		contentType string
+		content     string
+	}{
+		"/":                 {contentType: "text/html; charset=utf-8", content: "<html></html>"},
+		"/app.js":           {contentType: "application/javascript", content: strings.Repeat("x", 100)},
+		"/theme.css":        {contentType: "text/css", content: "h1 { color: red; }"},
+		"/css/img/bg.png":   {contentType: "image/png", content: strings.Repeat("p", 50)},
+		"/fonts/a.woff2":    {contentType: "application/octet-stream", content: strings.Repeat("f", 70)},
+		"/images/photo.jpg": {contentType: "image/jpeg", content: strings.Repeat("j", 200)},
+	}
+
+	var (
+		acceptEncoding string
+		requestsLock   sync.Mutex
+		requests       = make(map[string]int)
+	)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestsLock.Lock()
+		requests[r.URL.Path]++
+		requestsLock.Unlock()
+
+		if r.URL.Path == "/css/site.css" {
+			acceptEncoding = r.Header.Get("Accept-Encoding")
+			w.Header().Set("Content-Type", "text/css")
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Set("Cache-Control", "max-age=3600")
+			_, _ = w.Write(gzipped.Bytes())
+			return
+		}
+
+		file, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", file.contentType)
+		_, _ = w.Write([]byte(file.content))
+	}))
+	defer testServer.Close()
+
+	analyzer := New(&Config{}, testServer.Client())
+
+	pageURL := testServer.URL + "/"
+	resources := []*PageResource{
+		{Type: htmlextract.ResourceTypeStylesheet, URL: testServer.URL + "/css/site.css"},
+		{Type: htmlextract.ResourceTypeScript, URL: testServer.URL + "/app.js"},
+		{Type: htmlextract.ResourceTypeScript, URL: testServer.URL + "/missing.js"},
+		{Type: htmlextract.ResourceTypeMedia, URL: testServer.URL + "/video.mp4"},
+		{Type: htmlextract.ResourceTypeImage, URL: testServer.URL + "/images/photo-2x.jpg"},
+	}
+	images := []htmlextract.Image{
+		{Element: "img", Sources: []htmlextract.ImageSource{
+			{URL: "data:image/png;base64,iVBORw0KGgo="},
+			{URL: "/images/photo.jpg"},
+			{URL: "/images/photo-2x.jpg"},
+		}},
+	}
+
+	// The page and the images downloaded by the image report are reused
+	resp := &Response{Header: http.Header{"Content-Type": {"text/html"}, "Cache-Control": {"no-cache"}}}
+	downloadedImages := analyzer.downloadImages(context.Background(), []string{testServer.URL + "/images/photo.jpg"})
+
+	report := analyzer.pageWeight(context.Background(), pageURL, []byte("<html></html>"), resp, resources, images, downloadedImages)
+
+	assert.Equal(t, pageWeightAcceptEncoding, acceptEncoding)
+	assert.Zero(t, requests["/"])
+	assert.Equal(t, 1, requests["/images/photo.jpg"])
+
+	urlToResource := make(map[string]*WeightedResource)
+	for _, resource := range report.Resources {
+		urlToResource[strings.TrimPrefix(resource.URL, testServer.URL)] = resource
+	}
+	assert.Len(t, urlToResource, 8)
+	assert.NotContains(t, urlToResource, "/video.mp4")
+	assert.NotContains(t, urlToResource, "/images/photo-2x.jpg")
+
+	// The document is requested first
+	assert.Equal(t, pageURL, report.Resources[0].URL)
+	assert.Equal(t, WeightTypeHTML, report.Resources[0].Type)
+	assert.Equal(t, "no-cache", report.Resources[0].CacheControl)
+
+	photo := urlToResource["/images/photo.jpg"]
+	assert.Equal(t, "image/jpeg", photo.ContentType)
+	assert.Equal(t, downloadedImages[photo.URL].duration, photo.Timing.Total)
+
+	stylesheet := urlToResource["/css/site.css"]
+	assert.Equal(t, WeightTypeCSS, stylesheet.Type)
+	assert.Equal(t, "gzip", stylesheet.ContentEncoding)
+	assert.Equal(t, int64(gzipped.Len()), stylesheet.CompressedBytes)
+	assert.Equal(t, int64(len(css)), stylesheet.UncompressedBytes)
+	assert.Equal(t, "max-age=3600", stylesheet.CacheControl)
+
+	// Stylesheet references are downloaded after the stylesheet
+	font := urlToResource["/fonts/a.woff2?v=1"]
+	assert.Equal(t, WeightTypeFont, font.Type)
+	assert.Equal(t, stylesheet.URL, font.Initiator)
+	assert.GreaterOrEqual(t, font.Timing.Start, stylesheet.Timing.Start+stylesheet.Timing.Total)
+	assert.Equal(t, WeightTypeImage, urlToResource["/css/img/bg.png"].Type)
+	assert.Equal(t, WeightTypeCSS, urlToResource["/theme.css"].Type)
+
+	assert.Equal(t, "request failed with status 404", urlToResource["/missing.js"].Error)
+
+	expectedTypes := []*WeightTypeTotal{
+		{Type: WeightTypeHTML, Count: 1, CompressedBytes: 13, UncompressedBytes: 13},
+		{Type: WeightTypeJS, Count: 1, CompressedBytes: 100, UncompressedBytes: 100},
+		{Type: WeightTypeCSS, Count: 2, CompressedBytes: int64(gzipped.Len() + 18), UncompressedBytes: int64(len(css) + 18)},
+		{Type: WeightTypeImage, Count: 2, CompressedBytes: 250, UncompressedBytes: 250},
+		{Type: WeightTypeFont, Count: 1, CompressedBytes: 70, UncompressedBytes: 70},
+	}
+	assert.Equal(t, expectedTypes, report.Types)
+	assert.Equal(t, int64(13+100+gzipped.Len()+18+250+70), report.CompressedBytes)
+	assert.Equal(t, int64(13+100+len(css)+18+250+70), report.UncompressedBytes)
+
+	for _, resource := range report.Resources {
+		assert.LessOrEqual(t, resource.Timing.Start+resource.Timing.Total, report.Duration)
+	}
+
+	t.Run("Downloads the page without a response", func(t *testing.T) {
+		report := analyzer.pageWeight(context.Background(), pageURL, []byte("<html></html>"), nil, nil, nil, nil)
+
+		if assert.Len(t, report.Resources, 1) {
+			assert.Equal(t, http.StatusOK, report.Resources[0].StatusCode)
+		}
+		assert.Equal(t, 1, requests["/"])
+	})
+}
+
+func TestWeightType(t *testing.T) {
+	tests := []struct {
+		contentType  string
+		url          string
+		expectedType string
+		expected     string
+	}{
+		{contentType: "text/javascript; charset=utf-8", expected: WeightTypeJS},
+		{contentType: "application/xhtml+xml", expected: WeightTypeHTML},
+		{contentType: "image/svg+xml", expected: WeightTypeImage},
+		{contentType: "font/woff2", expected: WeightTypeFont},
+		{contentType: "application/octet-stream", url: "https://example.com/font.TTF?v=2", expected: WeightTypeFont},
+		{contentType: "text/plain", expectedType: WeightTypeJS, expected: WeightTypeJS},
+		{contentType: "application/json", expected: WeightTypeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			assert.Equal(t, tt.expected, weightType(tt.contentType, tt.url, tt.expectedType))
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package pageanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// RedirectCanonicalIssue is a redirect or canonical problem of a URL, with the pages that need fixing.
+type RedirectCanonicalIssue struct {
+	URL string
+	// Target is the final URL of a redirect or the canonical URL
+	Target     string
+	StatusCode int
+	// Chain is the redirect hops ending at the final URL, or the pages of a canonical loop
+	Chain   []string
+	Message string
+	// Sources are the pages that link to the URL, or declare the canonical
+	Sources []string
+}
+
+// RedirectCanonicalReport is the redirect and canonical consistency of the analyzed and crawled pages.
+type RedirectCanonicalReport struct {
+	// RedirectedLinks are internal links that point to a redirect instead of the final URL
+	RedirectedLinks []*RedirectCanonicalIssue
+	// RedirectChains are redirects that take more than one hop
+	RedirectChains []*RedirectCanonicalIssue
+	// CanonicalErrors are canonicals that point to a redirect, an error or a noindexed page
+	CanonicalErrors []*RedirectCanonicalIssue
+	CanonicalLoops  []*RedirectCanonicalIssue
+	// CanonicalizedLinks are internal links to pages whose canonical is another URL
+	CanonicalizedLinks []*RedirectCanonicalIssue
+}
+
+// IssuesNum returns the number of issues of the report.
+func (r *RedirectCanonicalReport) IssuesNum() int {
+	return len(r.RedirectedLinks) + len(r.RedirectChains) + len(r.CanonicalErrors) + len(r.CanonicalLoops) +
+		len(r.CanonicalizedLinks)
+}
+
+// redirectCanonicalReport checks the redirects and canonicals of the pages. Canonical targets that were not crawled
+// are downloaded to check their status.
+func (w *WebpageAnalyzer) redirectCanonicalReport(ctx context.Context, pages []*crawledPage) *RedirectCanonicalReport {
+	pages = sortedPages(pages)
+
+	pageByURL := pageIndex(pages)
+
+	// Pages linking to each URL, a redirected page is the page it redirects to
+	linkSources := make(map[string][]string)
+	for _, page := range pages {
+		for _, link := range page.Links {
+			if link != page.URL && link != page.FinalURL && !slices.Contains(linkSources[link], page.FinalURL) {
+				linkSources[link] = append(linkSources[link], page.FinalURL)
+			}
+		}
+	}
+
+	canonicals := pageCanonicals(pages)
+
+	// Download the canonical targets that were not crawled
+	w.fetchMissing(ctx, pageByURL, maps.Values(canonicals))
+
+	report := &RedirectCanonicalReport{}
+
+	for _, page := range pages {
+		if len(page.Redirects) == 0 {
+			continue
+		}
+
+		chain := make([]string, 0, len(page.Redirects)+1)
+		for _, redirect := range page.Redirects {
+			chain = append(chain, redirect.URL)
+		}
+		chain = append(chain, page.FinalURL)
+
+		issue := func(message string) *RedirectCanonicalIssue {
+			return &RedirectCanonicalIssue{
+				URL:        page.URL,
+				Target:     page.FinalURL,
+				StatusCode: page.Redirects[0].StatusCode,
+				Chain:      chain,
+				Message:    message,
+				Sources:    linkSources[page.URL],
+			}
+		}
+
+		if len(linkSources[page.URL]) > 0 {
+			report.RedirectedLinks = append(report.RedirectedLinks, issue(fmt.Sprintf("links point to a %d redirect, link to %s instead",
+				page.Redirects[0].StatusCode, page.FinalURL)))
+		}
+		if len(page.Redirects) > 1 {
+			report.RedirectChains = append(report.RedirectChains, issue(fmt.Sprintf("redirect chain of %d hops, redirect to %s directly",
+				len(page.Redirects), page.FinalURL)))
+		}
+	}
+
+	report.CanonicalErrors = canonicalErrors(canonicals, pageByURL)
+	report.CanonicalLoops = canonicalLoops(canonicals)
+
+	for _, pageURL := range sortedKeys(canonicals) {
+		if len(linkSources[pageURL]) == 0 {
+			continue
+		}
+
+		report.CanonicalizedLinks = append(report.CanonicalizedLinks, &RedirectCanonicalIssue{
+			URL:        pageURL,
+			Target:     canonicals[pageURL],
+			StatusCode: http.StatusOK,
+			Message:    fmt.Sprintf("page is canonicalized to %s but linked internally, link to the canonical instead", canonicals[pageURL]),
+			Sources:    linkSources[pageURL],
+		})
+	}
+
+	return report
+}
+
+// pageCanonicals returns the resolved canonical of the HTML pages that declare another URL as canonical, keyed by
+// the final URL of the page.
+func pageCanonicals(pages []*crawledPage) map[string]string {
+	canonicals := make(map[string]string)
+
+	for _, page := range pages {
+		if page.extractor == nil {
+			continue
+		}
+
+		canonical := page.extractor.Canonical()
+		if canonical == "" {
+			continue
+		}
+
+		resolvedCanonical, err := resolveLink(canonical, page.FinalURL)
+		if err != nil {
+			continue
+		}
+
+		if resolvedCanonical = normalizeURL(resolvedCanonical); resolvedCanonical != page.FinalURL {
+			canonicals[page.FinalURL] = resolvedCanonical
+		}
+	}
+
+	return canonicals
+}
+
+// canonicalErrors groups the pages by canonical target and reports the targets that redirect, fail or are noindexed.
+func canonicalErrors(canonicals map[string]string, pageByURL map[string]*crawledPage) []*RedirectCanonicalIssue {
+	var (
+		issues        []*RedirectCanonicalIssue
+		issueByTarget = make(map[string]*RedirectCanonicalIssue)
+	)
+
+	for _, pageURL := range sortedKeys(canonicals) {
+		canonical := canonicals[pageURL]
+
+		if issue, ok := issueByTarget[canonical]; ok {
+			if issue != nil {
+				issue.Sources = append(issue.Sources, pageURL)
+			}
+			continue
+		}
+
+		target := pageByURL[canonical]
+		issue := &RedirectCanonicalIssue{URL: canonical, Sources: []string{pageURL}}
+		switch {
+		case target == nil || target.StatusCode == 0:
+			issue.Message = "canonical could not be downloaded"
+		case len(target.Redirects) > 0 && target.URL == canonical:
+			issue.Target = target.FinalURL
+			issue.StatusCode = target.Redirects[0].StatusCode
+			issue.Message = fmt.Sprintf("canonical points to a %d redirect to %s", issue.StatusCode, target.FinalURL)
+		case target.StatusCode != http.StatusOK:
+			issue.StatusCode = target.StatusCode
+			issue.Message = fmt.Sprintf("canonical points to a page with status %d", target.StatusCode)
+		case target.NoIndex:
+			issue.StatusCode = target.StatusCode
+			issue.Message = "canonical points to a noindexed page"
+		default:
+			issue = nil
+		}
+
+		issueByTarget[canonical] = issue
+		if issue != nil {
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues
+}
+
+// canonicalLoops follows the canonicals from page to page and reports every cycle once.
+func canonicalLoops(canonicals map[string]string) []*RedirectCanonicalIssue {
+	var (
+		issues   []*RedirectCanonicalIssue
+		reported = make(map[string]bool)
+	)
+
+	for _, pageURL := range sortedKeys(canonicals) {
+		var (
+			chain   []string
+			visited = make(map[string]int)
+		)
+
+		for current := pageURL; current != ""; current = canonicals[current] {
+			start, seen := visited[current]
+			if !seen {
+				visited[current] = len(chain)
+				chain = append(chain, current)
+				continue
+			}
+
+			loop := chain[start:]
+			// Report the loop once, whichever of its pages it was reached from
+			if key := strings.Join(sortedCopy(loop), " "); !reported[key] {
+				reported[key] = true
+				issues = append(issues, &RedirectCanonicalIssue{
+					URL:     loop[0],
+					Target:  canonicals[loop[0]],
+					Chain:   append(append([]string{}, loop...), loop[0]),
+					Message: fmt.Sprintf("canonical loop between %d pages", len(loop)),
+					Sources: loop,
+				})
+			}
+			break
+		}
+	}
+
+	return issues
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := maps.Keys(values)
+	slices.Sort(keys)
+
+	return keys
+}
+
+func sortedCopy(values []string) []string {
+	sorted := append([]string{}, values...)
+	slices.Sort(sorted)
+
+	return sorted
+}
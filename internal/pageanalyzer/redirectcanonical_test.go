@@ -0,0 +1,98 @@
+package pageanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirectCanonicalReport(t *testing.T) {
+	page := func(canonical, head string, links ...string) string {
+		content := `<html><head>` + head
+		if canonical != "" {
+			content += fmt.Sprintf(`<link rel="canonical" href="%s">`, canonical)
+		}
+		content += `</head><body>`
+		for _, link := range links {
+			content += fmt.Sprintf(`<a href="%s">link</a>`, link)
+		}
+		return content + `</body></html>`
+	}
+
+	pages := map[string]string{
+		"/a":       page("/a", "", "/old", "/b", "/d", "/e", "/f"),
+		"/b":       page("/c", ""),
+		"/c":       page("/b", ""),
+		"/d":       page("/gone", ""),
+		"/e":       page("/noindex", ""),
+		"/f":       page("/moved", ""),
+		"/noindex": page("", `<meta name="robots" content="noindex, follow">`),
+	}
+	redirects := map[string]string{"/old": "/mid", "/mid": "/b", "/moved": "/a"}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target, ok := redirects[r.URL.Path]; ok {
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+
+		content, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, content)
+	}))
+	defer testServer.Close()
+
+	analyzer := New(&Config{MaxCrawlPages: 10}, testServer.Client())
+	u := func(path string) string { return testServer.URL + path }
+
+	crawledPages := analyzer.crawlPages(context.Background(), []string{u("/a"), u("/old"), u("/b"), u("/c"), u("/d"), u("/e"), u("/f")})
+	report := analyzer.redirectCanonicalReport(context.Background(), crawledPages)
+
+	assert.Equal(t, []*RedirectCanonicalIssue{{
+		URL:        u("/old"),
+		Target:     u("/b"),
+		StatusCode: http.StatusMovedPermanently,
+		Chain:      []string{u("/old"), u("/mid"), u("/b")},
+		Message:    fmt.Sprintf("links point to a 301 redirect, link to %s instead", u("/b")),
+		Sources:    []string{u("/a")},
+	}}, report.RedirectedLinks)
+
+	if assert.Len(t, report.RedirectChains, 1) {
+		assert.Equal(t, fmt.Sprintf("redirect chain of 2 hops, redirect to %s directly", u("/b")), report.RedirectChains[0].Message)
+	}
+
+	if assert.Len(t, report.CanonicalErrors, 3) {
+		assert.Equal(t, u("/gone"), report.CanonicalErrors[0].URL)
+		assert.Equal(t, http.StatusNotFound, report.CanonicalErrors[0].StatusCode)
+		assert.Equal(t, []string{u("/d")}, report.CanonicalErrors[0].Sources)
+
+		assert.Equal(t, u("/noindex"), report.CanonicalErrors[1].URL)
+		assert.Equal(t, "canonical points to a noindexed page", report.CanonicalErrors[1].Message)
+
+		assert.Equal(t, u("/moved"), report.CanonicalErrors[2].URL)
+		assert.Equal(t, u("/a"), report.CanonicalErrors[2].Target)
+		assert.Equal(t, []string{u("/f")}, report.CanonicalErrors[2].Sources)
+	}
+
+	if assert.Len(t, report.CanonicalLoops, 1) {
+		assert.Equal(t, []string{u("/b"), u("/c"), u("/b")}, report.CanonicalLoops[0].Chain)
+		assert.Equal(t, []string{u("/b"), u("/c")}, report.CanonicalLoops[0].Sources)
+	}
+
+	var canonicalized []string
+	for _, issue := range report.CanonicalizedLinks {
+		canonicalized = append(canonicalized, issue.URL)
+		assert.Equal(t, []string{u("/a")}, issue.Sources)
+	}
+	assert.Equal(t, []string{u("/b"), u("/d"), u("/e"), u("/f")}, canonicalized)
+
+	assert.Equal(t, 10, report.IssuesNum())
+}
@@ -0,0 +1,102 @@
+package pageanalyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+// PageResource is a resource of the page with its absolute URL.
+type PageResource struct {
+	Type    string
+	URL     string
+	Element string
+	Attr    string
+	Pixel   bool
+	Broken  bool
+}
+
+// ResourceReport is the inventory of the page resources by type.
+type ResourceReport struct {
+	Types     []*ResourceTypeReport
+	BrokenNum int
+}
+
+// ResourceTypeReport holds the resources of a single type.
+type ResourceTypeReport struct {
+	Type      string
+	Resources []*PageResource
+	BrokenNum int
+}
+
+// resolveResources returns the resources that can be requested, with absolute URLs and without duplicates.
+func resolveResources(resources []htmlextract.Resource, pageURL string) []*PageResource {
+	var (
+		pageResources []*PageResource
+		seen          = make(map[string]bool)
+	)
+
+	for _, resource := range resources {
+		parsedURL, err := url.Parse(resource.URL)
+		if err != nil {
+			continue
+		}
+
+		// data:, blob:, javascript: and other inline URLs are not requested from a server
+		if scheme := strings.ToLower(parsedURL.Scheme); scheme != "" && scheme != "http" && scheme != "https" {
+			continue
+		}
+
+		resolvedURL, err := resolveLink(resource.URL, pageURL)
+		if err != nil {
+			continue
+		}
+		resolvedURL = stripFragment(resolvedURL)
+
+		key := resource.Type + " " + resolvedURL
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		pageResources = append(pageResources, &PageResource{
+			Type:    resource.Type,
+			URL:     resolvedURL,
+			Element: resource.Element,
+			Attr:    resource.Attr,
+			Pixel:   resource.Pixel,
+		})
+	}
+
+	return pageResources
+}
+
+func resourceReport(resources []*PageResource, inaccessibleLinks map[string]bool) *ResourceReport {
+	report := &ResourceReport{}
+
+	typeToReport := make(map[string]*ResourceTypeReport)
+	for _, resource := range resources {
+		resource.Broken = inaccessibleLinks[resource.URL]
+
+		typeReport, ok := typeToReport[resource.Type]
+		if !ok {
+			typeReport = &ResourceTypeReport{Type: resource.Type}
+			typeToReport[resource.Type] = typeReport
+		}
+
+		typeReport.Resources = append(typeReport.Resources, resource)
+		if resource.Broken {
+			typeReport.BrokenNum++
+			report.BrokenNum++
+		}
+	}
+
+	for _, resourceType := range htmlextract.ResourceTypes {
+		if typeReport, ok := typeToReport[resourceType]; ok {
+			report.Types = append(report.Types, typeReport)
+		}
+	}
+
+	return report
+}
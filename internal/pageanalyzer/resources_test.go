@@ -0,0 +1,62 @@
+package pageanalyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestResolveResources(t *testing.T) {
+	resources := []htmlextract.Resource{
+		{Type: htmlextract.ResourceTypeImage, URL: "/logo.png", Element: "img", Attr: "src"},
+		{Type: htmlextract.ResourceTypeImage, URL: "logo.png#v2", Element: "img", Attr: "srcset"},
+		{Type: htmlextract.ResourceTypeImage, URL: "data:image/png;base64,AAAA", Element: "img", Attr: "src"},
+		{Type: htmlextract.ResourceTypeScript, URL: "//cdn.example.org/app.js", Element: "script", Attr: "src"},
+		{Type: htmlextract.ResourceTypeFormAction, URL: "javascript:void(0)", Element: "form", Attr: "action"},
+	}
+
+	pageResources := resolveResources(resources, "https://example.com/index.html")
+
+	assert.Equal(t, []*PageResource{
+		{Type: htmlextract.ResourceTypeImage, URL: "https://example.com/logo.png", Element: "img", Attr: "src"},
+		{Type: htmlextract.ResourceTypeScript, URL: "https://cdn.example.org/app.js", Element: "script", Attr: "src"},
+	}, pageResources)
+}
+
+func TestResourceReport(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.js" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer testServer.Close()
+
+	analyzer := New(&Config{}, testServer.Client())
+
+	pageResources := resolveResources([]htmlextract.Resource{
+		{Type: htmlextract.ResourceTypeImage, URL: "/logo.png", Element: "img", Attr: "src"},
+		{Type: htmlextract.ResourceTypeScript, URL: "/app.js", Element: "script", Attr: "src"},
+		{Type: htmlextract.ResourceTypeScript, URL: "/missing.js", Element: "script", Attr: "src"},
+	}, testServer.URL)
+
+	var urls []string
+	for _, resource := range pageResources {
+		urls = append(urls, resource.URL)
+	}
+
+	report := resourceReport(pageResources, analyzer.inaccessibleLinks(context.Background(), urls))
+
+	assert.Equal(t, 1, report.BrokenNum)
+	require.Len(t, report.Types, 2)
+	assert.Equal(t, htmlextract.ResourceTypeImage, report.Types[0].Type)
+	assert.Equal(t, 0, report.Types[0].BrokenNum)
+	assert.Equal(t, htmlextract.ResourceTypeScript, report.Types[1].Type)
+	assert.Equal(t, 1, report.Types[1].BrokenNum)
+	assert.True(t, report.Types[1].Resources[1].Broken)
+}
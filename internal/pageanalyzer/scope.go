@@ -0,0 +1,113 @@
+package pageanalyzer
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Link scope modes decide which links are internal to the analyzed page.
+const (
+	// ScopeExactHost treats only links to the very same host as internal.
+	ScopeExactHost = "exact-host"
+	// ScopeHostWithWWW treats links to the same host, with or without the www prefix, as internal.
+	ScopeHostWithWWW = "host-www"
+	// ScopeRegistrableDomain treats links to any host under the same registrable domain (eTLD+1) as internal,
+	// e.g. docs.example.co.uk for www.example.co.uk.
+	ScopeRegistrableDomain = "registrable-domain"
+	// ScopeList treats links matching one of the configured domains and path prefixes as internal.
+	ScopeList = "list"
+)
+
+// ScopeModes lists the supported link scope modes.
+var ScopeModes = []string{ScopeExactHost, ScopeHostWithWWW, ScopeRegistrableDomain, ScopeList}
+
+// Scope defines which links are internal to the analyzed page.
+type Scope struct {
+	Mode string
+	// Entries lists the domains for ScopeList, optionally with a path prefix, e.g. "example.com/blog".
+	// A domain also matches its subdomains.
+	Entries []string
+}
+
+// Validate checks the mode is supported and a list scope has entries.
+func (s Scope) Validate() error {
+	if !slices.Contains(ScopeModes, s.Mode) {
+		return fmt.Errorf("unknown link scope mode %q", s.Mode)
+	}
+
+	if s.Mode == ScopeList && len(s.Entries) == 0 {
+		return fmt.Errorf("link scope mode %q needs at least one domain", ScopeList)
+	}
+
+	return nil
+}
+
+// IsInternal reports whether the link is internal to the page according to the scope.
+func (s Scope) IsInternal(link, pageURL string) bool {
+	parsedLink, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return false
+	}
+
+	linkHost := strings.ToLower(parsedLink.Host)
+	pageHost := strings.ToLower(page.Host)
+
+	switch s.Mode {
+	case ScopeExactHost:
+		return linkHost == pageHost
+	case ScopeRegistrableDomain:
+		linkDomain, linkErr := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(parsedLink.Hostname()))
+		pageDomain, pageErr := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(page.Hostname()))
+		// IPs, localhost and bare suffixes have no registrable domain
+		if linkErr != nil || pageErr != nil {
+			return linkHost == pageHost
+		}
+		return linkDomain == pageDomain
+	case ScopeList:
+		for _, entry := range s.Entries {
+			if matchesScopeEntry(parsedLink, entry) {
+				return true
+			}
+		}
+		return false
+	default:
+		return strings.TrimPrefix(linkHost, "www.") == strings.TrimPrefix(pageHost, "www.")
+	}
+}
+
+// matchesScopeEntry reports whether the link is on the entry's domain, or a subdomain of it, and under its path prefix.
+// The prefix matches whole path segments, "example.com/blog" matches /blog and /blog/post but not /blog-old.
+func matchesScopeEntry(link *url.URL, entry string) bool {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	for _, scheme := range []string{"https://", "http://"} {
+		entry = strings.TrimPrefix(entry, scheme)
+	}
+
+	domain, pathPrefix, _ := strings.Cut(entry, "/")
+	if domain == "" {
+		return false
+	}
+
+	host := strings.ToLower(link.Hostname())
+	if host != domain && !strings.HasSuffix(host, "."+domain) {
+		return false
+	}
+
+	path := link.Path
+	if path == "" {
+		path = "/"
+	}
+
+	path, pathPrefix = strings.ToLower(path), "/"+pathPrefix
+
+	return path == pathPrefix || strings.HasPrefix(path, strings.TrimSuffix(pathPrefix, "/")+"/")
+}
@@ -0,0 +1,115 @@
+package pageanalyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopeIsInternal(t *testing.T) {
+	tests := []struct {
+		name     string
+		scope    Scope
+		link     string
+		pageURL  string
+		expected bool
+	}{
+		{
+			name:     "Exact host",
+			scope:    Scope{Mode: ScopeExactHost},
+			link:     "https://example.com/about",
+			pageURL:  "https://example.com",
+			expected: true,
+		},
+		{
+			name:     "Exact host with www",
+			scope:    Scope{Mode: ScopeExactHost},
+			link:     "https://www.example.com/about",
+			pageURL:  "https://example.com",
+			expected: false,
+		},
+		{
+			name:     "Host with www on the page side",
+			scope:    Scope{Mode: ScopeHostWithWWW},
+			link:     "https://example.com/about",
+			pageURL:  "https://www.example.com",
+			expected: true,
+		},
+		{
+			name:     "Host with www and subdomain",
+			scope:    Scope{Mode: ScopeHostWithWWW},
+			link:     "https://docs.example.com/about",
+			pageURL:  "https://www.example.com",
+			expected: false,
+		},
+		{
+			name:     "Registrable domain with subdomain",
+			scope:    Scope{Mode: ScopeRegistrableDomain},
+			link:     "https://docs.example.co.uk/about",
+			pageURL:  "https://www.example.co.uk",
+			expected: true,
+		},
+		{
+			name:     "Registrable domain on shared suffix",
+			scope:    Scope{Mode: ScopeRegistrableDomain},
+			link:     "https://other.github.io/",
+			pageURL:  "https://example.github.io/",
+			expected: false,
+		},
+		{
+			name:     "Registrable domain on IP",
+			scope:    Scope{Mode: ScopeRegistrableDomain},
+			link:     "http://127.0.0.1:8080/about",
+			pageURL:  "http://127.0.0.1:8080/",
+			expected: true,
+		},
+		{
+			name:     "List with path prefix",
+			scope:    Scope{Mode: ScopeList, Entries: []string{"example.org", "https://example.com/blog"}},
+			link:     "https://www.example.com/blog/post",
+			pageURL:  "https://example.com",
+			expected: true,
+		},
+		{
+			name:     "List outside path prefix",
+			scope:    Scope{Mode: ScopeList, Entries: []string{"example.com/blog"}},
+			link:     "https://example.com/shop",
+			pageURL:  "https://example.com",
+			expected: false,
+		},
+		{
+			name:     "List with path prefix of another segment",
+			scope:    Scope{Mode: ScopeList, Entries: []string{"example.com/blog"}},
+			link:     "https://example.com/blog-old/post",
+			pageURL:  "https://example.com",
+			expected: false,
+		},
+		{
+			name:     "List with path prefix itself",
+			scope:    Scope{Mode: ScopeList, Entries: []string{"example.com/blog/"}},
+			link:     "https://example.com/blog/",
+			pageURL:  "https://example.com",
+			expected: true,
+		},
+		{
+			name:     "List with other domain",
+			scope:    Scope{Mode: ScopeList, Entries: []string{"example.org"}},
+			link:     "https://cdn.example.org/image.png",
+			pageURL:  "https://example.com",
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.scope.IsInternal(tt.link, tt.pageURL))
+		})
+	}
+}
+
+func TestScopeValidate(t *testing.T) {
+	assert.NoError(t, Scope{Mode: ScopeRegistrableDomain}.Validate())
+	assert.NoError(t, Scope{Mode: ScopeList, Entries: []string{"example.com"}}.Validate())
+	assert.EqualError(t, Scope{Mode: ScopeList}.Validate(), `link scope mode "list" needs at least one domain`)
+	assert.EqualError(t, Scope{Mode: "unknown"}.Validate(), `unknown link scope mode "unknown"`)
+}
@@ -0,0 +1,543 @@
+package pageanalyzer
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Security header check names
+const (
+	SecurityCheckCSP                = "Content-Security-Policy"
+	SecurityCheckHSTS               = "Strict-Transport-Security"
+	SecurityCheckContentTypeOptions = "X-Content-Type-Options"
+	SecurityCheckFraming            = "Framing (frame-ancestors / X-Frame-Options)"
+	SecurityCheckReferrerPolicy     = "Referrer-Policy"
+	SecurityCheckPermissionsPolicy  = "Permissions-Policy"
+	SecurityCheckCOOP               = "Cross-Origin-Opener-Policy"
+	SecurityCheckCOEP               = "Cross-Origin-Embedder-Policy"
+)
+
+const (
+	// hstsMinMaxAge is the shortest HSTS max-age considered long enough, 180 days.
+	hstsMinMaxAge = 180 * 24 * 60 * 60
+	// hstsPreloadMaxAge is the max-age required by the HSTS preload list, one year.
+	hstsPreloadMaxAge = 365 * 24 * 60 * 60
+)
+
+// securityGrades maps the lowest score percent of each grade.
+var securityGrades = []struct {
+	Grade      string
+	MinPercent int
+}{
+	{Grade: "A", MinPercent: 90},
+	{Grade: "B", MinPercent: 75},
+	{Grade: "C", MinPercent: 60},
+	{Grade: "D", MinPercent: 40},
+	{Grade: "F", MinPercent: 0},
+}
+
+// safeReferrerPolicies don't send the full URL to other origins.
+var safeReferrerPolicies = []string{"no-referrer", "same-origin", "strict-origin", "strict-origin-when-cross-origin"}
+
+// referrerPolicies lists every valid Referrer-Policy value.
+var referrerPolicies = append([]string{"no-referrer-when-downgrade", "origin", "origin-when-cross-origin", "unsafe-url"}, safeReferrerPolicies...)
+
+// SecurityFinding is a problem found in a security header or cookie, with a hint on how to fix it.
+type SecurityFinding struct {
+	Message     string
+	Remediation string
+}
+
+// SecurityHeaderCheck is the result of checking one security header.
+type SecurityHeaderCheck struct {
+	Name     string
+	Value    string
+	Score    int
+	MaxScore int
+	Findings []SecurityFinding
+}
+
+func (c *SecurityHeaderCheck) addFinding(message, remediation string) {
+	c.Findings = append(c.Findings, SecurityFinding{Message: message, Remediation: remediation})
+}
+
+// CookieCheck is the result of checking the flags of a cookie set by the page.
+type CookieCheck struct {
+	Name     string
+	Secure   bool
+	HttpOnly bool
+	SameSite string
+	Findings []SecurityFinding
+}
+
+func (c *CookieCheck) addFinding(message, remediation string) {
+	c.Findings = append(c.Findings, SecurityFinding{Message: message, Remediation: remediation})
+}
+
+// SecurityHeaderReport grades the security headers of the page response.
+type SecurityHeaderReport struct {
+	Checks   []*SecurityHeaderCheck
+	Cookies  []*CookieCheck
+	Score    int
+	MaxScore int
+	// Grade is based on the header checks, from A to F. Cookie findings are reported but not graded.
+	Grade string
+}
+
+// securityHeaderReport checks the security headers and cookies of the page response.
+func securityHeaderReport(header http.Header, pageURL string) *SecurityHeaderReport {
+	isHTTPS := false
+	if page, err := url.Parse(pageURL); err == nil {
+		isHTTPS = strings.EqualFold(page.Scheme, "https")
+	}
+
+	csp := parseCSP(header.Values("Content-Security-Policy"))
+
+	report := &SecurityHeaderReport{
+		Checks: []*SecurityHeaderCheck{
+			checkCSP(header, csp),
+			checkHSTS(header, isHTTPS),
+			checkContentTypeOptions(header),
+			checkFraming(header, csp),
+			checkReferrerPolicy(header),
+			checkPermissionsPolicy(header),
+			checkCOOP(header),
+			checkCOEP(header),
+		},
+		Cookies: checkCookies(header, isHTTPS),
+	}
+
+	for _, check := range report.Checks {
+		report.Score += check.Score
+		report.MaxScore += check.MaxScore
+	}
+
+	scorePercent := percent(report.Score, report.MaxScore)
+	for _, grade := range securityGrades {
+		if scorePercent >= grade.MinPercent {
+			report.Grade = grade.Grade
+			break
+		}
+	}
+
+	return report
+}
+
+// parseCSP parses the Content-Security-Policy headers into directives and their sources.
+// Only the first occurrence of a directive is used, as browsers do.
+func parseCSP(policies []string) map[string][]string {
+	directives := make(map[string][]string)
+
+	for _, policy := range policies {
+		for _, directive := range strings.Split(policy, ";") {
+			fields := strings.Fields(directive)
+			if len(fields) == 0 {
+				continue
+			}
+
+			name := strings.ToLower(fields[0])
+			if _, ok := directives[name]; ok {
+				continue
+			}
+			directives[name] = fields[1:]
+		}
+	}
+
+	return directives
+}
+
+// cspSources returns the sources of the directive, falling back to default-src.
+func cspSources(csp map[string][]string, directive string) ([]string, bool) {
+	if sources, ok := csp[directive]; ok {
+		return sources, true
+	}
+
+	sources, ok := csp["default-src"]
+
+	return sources, ok
+}
+
+func checkCSP(header http.Header, csp map[string][]string) *SecurityHeaderCheck {
+	check := &SecurityHeaderCheck{
+		Name:     SecurityCheckCSP,
+		Value:    strings.Join(header.Values("Content-Security-Policy"), ", "),
+		MaxScore: 25,
+	}
+
+	if len(csp) == 0 {
+		if header.Get("Content-Security-Policy-Report-Only") != "" {
+			check.addFinding("the policy is only set in report-only mode, so it is not enforced",
+				"Send the tested policy in the Content-Security-Policy header.")
+		} else {
+			check.addFinding("header is missing",
+				"Add a Content-Security-Policy that restricts where scripts, styles and other resources load from, e.g. \"default-src 'self'\".")
+		}
+		return check
+	}
+
+	check.Score = check.MaxScore
+
+	scriptSources, ok := cspSources(csp, "script-src")
+	if !ok {
+		check.Score -= 10
+		check.addFinding("neither script-src nor default-src is set, so scripts can load from anywhere",
+			"Add a default-src or script-src directive.")
+	}
+
+	lowerScriptSources := make([]string, len(scriptSources))
+	for i, source := range scriptSources {
+		lowerScriptSources[i] = strings.ToLower(source)
+	}
+
+	// Nonces and hashes make browsers ignore 'unsafe-inline'
+	hasNonceOrHash := slices.ContainsFunc(lowerScriptSources, func(source string) bool {
+		return strings.HasPrefix(source, "'nonce-") || strings.HasPrefix(source, "'sha256-") ||
+			strings.HasPrefix(source, "'sha384-") || strings.HasPrefix(source, "'sha512-")
+	})
+	if slices.Contains(lowerScriptSources, "'unsafe-inline'") && !hasNonceOrHash {
+		check.Score -= 10
+		check.addFinding("script sources allow 'unsafe-inline', which defeats protection against XSS",
+			"Remove 'unsafe-inline' and allow inline scripts with nonces or hashes instead.")
+	}
+
+	if slices.Contains(lowerScriptSources, "'unsafe-eval'") {
+		check.Score -= 5
+		check.addFinding("script sources allow 'unsafe-eval'",
+			"Remove 'unsafe-eval' and avoid eval() and similar functions.")
+	}
+
+	for _, directive := range []string{"default-src", "script-src", "object-src", "style-src"} {
+		for _, source := range csp[directive] {
+			if isWildcardSource(directive, source) {
+				check.Score -= 5
+				check.addFinding(fmt.Sprintf("%s allows the wildcard source %q", directive, source),
+					fmt.Sprintf("Replace %q in %s with the specific hosts the page needs.", source, directive))
+				break
+			}
+		}
+	}
+
+	if sources, ok := cspSources(csp, "object-src"); !ok || !slices.Contains(sources, "'none'") {
+		check.addFinding("object-src is not 'none', so plugins such as Flash can be embedded",
+			"Add \"object-src 'none'\".")
+	}
+
+	if check.Score < 0 {
+		check.Score = 0
+	}
+
+	return check
+}
+
+// isWildcardSource reports whether the CSP source allows a whole scheme or any host.
+func isWildcardSource(directive, source string) bool {
+	source = strings.ToLower(source)
+	if source == "*" || source == "http:" || source == "https:" {
+		return true
+	}
+
+	// data: URLs can carry scripts, but not styles
+	return source == "data:" && directive != "style-src"
+}
+
+func checkHSTS(header http.Header, isHTTPS bool) *SecurityHeaderCheck {
+	value := header.Get("Strict-Transport-Security")
+	check := &SecurityHeaderCheck{
+		Name:     SecurityCheckHSTS,
+		Value:    value,
+		MaxScore: 20,
+	}
+
+	if !isHTTPS {
+		check.addFinding("the page is served over HTTP, where browsers ignore HSTS",
+			"Serve the page over HTTPS, redirect HTTP to HTTPS and send Strict-Transport-Security.")
+		return check
+	}
+
+	if value == "" {
+		check.addFinding("header is missing",
+			"Add \"Strict-Transport-Security: max-age=31536000; includeSubDomains\".")
+		return check
+	}
+
+	var (
+		maxAge            = -1
+		includeSubDomains bool
+		preload           bool
+	)
+	for _, directive := range strings.Split(value, ";") {
+		name, directiveValue, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "max-age":
+			if age, err := strconv.Atoi(strings.Trim(strings.TrimSpace(directiveValue), `"`)); err == nil {
+				maxAge = age
+			}
+		case "includesubdomains":
+			includeSubDomains = true
+		case "preload":
+			preload = true
+		}
+	}
+
+	switch {
+	case maxAge < 0:
+		check.addFinding("max-age is missing or invalid, so browsers ignore the header",
+			"Set max-age to at least 31536000 (one year).")
+		return check
+	case maxAge == 0:
+		check.addFinding("max-age is 0, which removes the HSTS policy",
+			"Set max-age to at least 31536000 (one year).")
+		return check
+	case maxAge < hstsMinMaxAge:
+		check.Score += 5
+		check.addFinding(fmt.Sprintf("max-age is only %d seconds", maxAge),
+			"Set max-age to at least 31536000 (one year).")
+	case maxAge < hstsPreloadMaxAge:
+		check.Score += 8
+	default:
+		check.Score += 10
+	}
+
+	if includeSubDomains {
+		check.Score += 5
+	} else {
+		check.addFinding("includeSubDomains is not set, so subdomains can still be reached over HTTP",
+			"Add includeSubDomains once every subdomain supports HTTPS.")
+	}
+
+	switch {
+	case !preload:
+		check.addFinding("preload is not set, so the first visit can still be over HTTP",
+			"Add preload and submit the domain to hstspreload.org.")
+	case !includeSubDomains || maxAge < hstsPreloadMaxAge:
+		check.addFinding("preload is set but the preload list also requires includeSubDomains and a max-age of one year",
+			"Add includeSubDomains and set max-age to at least 31536000.")
+	default:
+		check.Score += 5
+	}
+
+	return check
+}
+
+func checkContentTypeOptions(header http.Header) *SecurityHeaderCheck {
+	value := header.Get("X-Content-Type-Options")
+	check := &SecurityHeaderCheck{
+		Name:     SecurityCheckContentTypeOptions,
+		Value:    value,
+		MaxScore: 10,
+	}
+
+	switch {
+	case value == "":
+		check.addFinding("header is missing", "Add \"X-Content-Type-Options: nosniff\".")
+	case !strings.EqualFold(strings.TrimSpace(value), "nosniff"):
+		check.addFinding(fmt.Sprintf("invalid value %q", value), "Set the header to nosniff.")
+	default:
+		check.Score = check.MaxScore
+	}
+
+	return check
+}
+
+func checkFraming(header http.Header, csp map[string][]string) *SecurityHeaderCheck {
+	frameOptions := header.Get("X-Frame-Options")
+	check := &SecurityHeaderCheck{
+		Name:     SecurityCheckFraming,
+		Value:    frameOptions,
+		MaxScore: 15,
+	}
+
+	// frame-ancestors overrides X-Frame-Options in browsers that support it
+	if ancestors, ok := csp["frame-ancestors"]; ok {
+		check.Value = "frame-ancestors " + strings.Join(ancestors, " ")
+		if slices.Contains(ancestors, "*") {
+			check.addFinding("frame-ancestors allows any site to frame the page",
+				"Set frame-ancestors to 'none' or 'self'.")
+			return check
+		}
+		check.Score = check.MaxScore
+		return check
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(frameOptions)) {
+	case "DENY", "SAMEORIGIN":
+		check.Score = check.MaxScore
+	case "":
+		check.addFinding("neither frame-ancestors nor X-Frame-Options is set, so the page can be framed for clickjacking",
+			"Add \"frame-ancestors 'self'\" to the Content-Security-Policy, or \"X-Frame-Options: SAMEORIGIN\".")
+	default:
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(frameOptions)), "ALLOW-FROM") {
+			check.addFinding("ALLOW-FROM is not supported by modern browsers, which then allow framing",
+				"Use the frame-ancestors directive of the Content-Security-Policy instead.")
+		} else {
+			check.addFinding(fmt.Sprintf("invalid X-Frame-Options value %q", frameOptions),
+				"Set X-Frame-Options to DENY or SAMEORIGIN.")
+		}
+	}
+
+	return check
+}
+
+func checkReferrerPolicy(header http.Header) *SecurityHeaderCheck {
+	value := strings.Join(header.Values("Referrer-Policy"), ", ")
+	check := &SecurityHeaderCheck{
+		Name:     SecurityCheckReferrerPolicy,
+		Value:    value,
+		MaxScore: 10,
+	}
+
+	if value == "" {
+		check.addFinding("header is missing, browsers default to strict-origin-when-cross-origin",
+			"Add \"Referrer-Policy: strict-origin-when-cross-origin\" to make the policy explicit.")
+		check.Score = check.MaxScore / 2
+		return check
+	}
+
+	// The last value the browser understands applies
+	policy := ""
+	for _, candidate := range strings.Split(value, ",") {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		if slices.Contains(referrerPolicies, candidate) {
+			policy = candidate
+		}
+	}
+
+	switch {
+	case policy == "":
+		check.addFinding(fmt.Sprintf("invalid value %q", value),
+			"Set the header to strict-origin-when-cross-origin or a stricter policy.")
+	case !slices.Contains(safeReferrerPolicies, policy):
+		check.Score = check.MaxScore / 2
+		check.addFinding(fmt.Sprintf("%s sends the full URL to other origins", policy),
+			"Use strict-origin-when-cross-origin or a stricter policy.")
+	default:
+		check.Score = check.MaxScore
+	}
+
+	return check
+}
+
+func checkPermissionsPolicy(header http.Header) *SecurityHeaderCheck {
+	value := header.Get("Permissions-Policy")
+	check := &SecurityHeaderCheck{
+		Name:     SecurityCheckPermissionsPolicy,
+		Value:    value,
+		MaxScore: 10,
+	}
+
+	switch {
+	case value != "":
+		check.Score = check.MaxScore
+	case header.Get("Feature-Policy") != "":
+		check.Value = header.Get("Feature-Policy")
+		check.Score = check.MaxScore / 2
+		check.addFinding("only the deprecated Feature-Policy header is set",
+			"Move the policy to the Permissions-Policy header.")
+	default:
+		check.addFinding("header is missing",
+			"Add a Permissions-Policy that disables unused features, e.g. \"camera=(), microphone=(), geolocation=()\".")
+	}
+
+	return check
+}
+
+func checkCOOP(header http.Header) *SecurityHeaderCheck {
+	value := header.Get("Cross-Origin-Opener-Policy")
+	check := &SecurityHeaderCheck{
+		Name:     SecurityCheckCOOP,
+		Value:    value,
+		MaxScore: 5,
+	}
+
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "same-origin", "same-origin-allow-popups":
+		check.Score = check.MaxScore
+	case "":
+		check.addFinding("header is missing, so cross-origin windows opened by or opening the page share its browsing context group",
+			"Add \"Cross-Origin-Opener-Policy: same-origin\".")
+	default:
+		check.addFinding(fmt.Sprintf("%q does not isolate the page", value),
+			"Set the header to same-origin or same-origin-allow-popups.")
+	}
+
+	return check
+}
+
+func checkCOEP(header http.Header) *SecurityHeaderCheck {
+	value := header.Get("Cross-Origin-Embedder-Policy")
+	check := &SecurityHeaderCheck{
+		Name:     SecurityCheckCOEP,
+		Value:    value,
+		MaxScore: 5,
+	}
+
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "require-corp", "credentialless":
+		check.Score = check.MaxScore
+	case "":
+		check.addFinding("header is missing, so the page is not cross-origin isolated",
+			"Add \"Cross-Origin-Embedder-Policy: require-corp\" once every cross-origin resource allows it.")
+	default:
+		check.addFinding(fmt.Sprintf("%q does not restrict cross-origin resources", value),
+			"Set the header to require-corp or credentialless.")
+	}
+
+	return check
+}
+
+func checkCookies(header http.Header, isHTTPS bool) []*CookieCheck {
+	var checks []*CookieCheck
+
+	for _, cookie := range (&http.Response{Header: header}).Cookies() {
+		check := &CookieCheck{
+			Name:     cookie.Name,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+		}
+
+		switch cookie.SameSite {
+		case http.SameSiteLaxMode:
+			check.SameSite = "Lax"
+		case http.SameSiteStrictMode:
+			check.SameSite = "Strict"
+		case http.SameSiteNoneMode:
+			check.SameSite = "None"
+		default:
+			check.addFinding("SameSite is not set, browsers treat the cookie as Lax or send it with cross-site requests",
+				"Set SameSite=Lax, or Strict for cookies that are never needed on cross-site navigation.")
+		}
+
+		if !cookie.Secure {
+			switch {
+			case cookie.SameSite == http.SameSiteNoneMode:
+				check.addFinding("SameSite=None without Secure is rejected by browsers", "Add the Secure flag.")
+			case isHTTPS:
+				check.addFinding("Secure is not set, so the cookie is also sent over HTTP", "Add the Secure flag.")
+			default:
+				check.addFinding("the cookie is set over HTTP and can be read on the network", "Serve the page over HTTPS and add the Secure flag.")
+			}
+		}
+
+		if !cookie.HttpOnly {
+			check.addFinding("HttpOnly is not set, so scripts can read the cookie",
+				"Add the HttpOnly flag unless scripts need to read the cookie.")
+		}
+
+		// Cookie prefixes are only accepted by browsers with the matching attributes
+		if strings.HasPrefix(cookie.Name, "__Host-") && (!cookie.Secure || cookie.Domain != "" || cookie.Path != "/") {
+			check.addFinding("__Host- cookies require Secure, Path=/ and no Domain, so browsers reject it",
+				"Add Secure and Path=/, and remove the Domain attribute.")
+		} else if strings.HasPrefix(cookie.Name, "__Secure-") && !cookie.Secure {
+			check.addFinding("__Secure- cookies require Secure, so browsers reject it", "Add the Secure flag.")
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
@@ -0,0 +1,76 @@
+package pageanalyzer
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityHeaderReport(t *testing.T) {
+	t.Run("Strict headers", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Content-Security-Policy", "default-src 'self'; script-src 'self' 'nonce-abc' 'unsafe-inline'; object-src 'none'; frame-ancestors 'none'")
+		header.Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains; preload")
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("Referrer-Policy", "unknown-policy, no-referrer")
+		header.Set("Permissions-Policy", "camera=()")
+		header.Set("Cross-Origin-Opener-Policy", "same-origin")
+		header.Set("Cross-Origin-Embedder-Policy", "require-corp")
+		header.Add("Set-Cookie", "__Host-session=abc; Path=/; Secure; HttpOnly; SameSite=Strict")
+
+		report := securityHeaderReport(header, "https://example.com")
+
+		assert.Equal(t, "A", report.Grade)
+		assert.Equal(t, 100, report.Score)
+		assert.Equal(t, 100, report.MaxScore)
+		for _, check := range report.Checks {
+			assert.Empty(t, check.Findings, check.Name)
+		}
+		require.Len(t, report.Cookies, 1)
+		assert.Empty(t, report.Cookies[0].Findings)
+		assert.Equal(t, "Strict", report.Cookies[0].SameSite)
+	})
+
+	t.Run("Weak headers", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Content-Security-Policy", "script-src * 'unsafe-inline' 'unsafe-eval'")
+		header.Set("Strict-Transport-Security", "max-age=3600; preload")
+		header.Set("X-Frame-Options", "ALLOW-FROM https://example.org")
+		header.Set("Referrer-Policy", "unsafe-url")
+		header.Add("Set-Cookie", "id=1; SameSite=None")
+
+		report := securityHeaderReport(header, "https://example.com")
+
+		assert.Equal(t, "F", report.Grade)
+
+		checks := make(map[string]*SecurityHeaderCheck)
+		for _, check := range report.Checks {
+			checks[check.Name] = check
+		}
+
+		assert.Equal(t, 5, checks[SecurityCheckCSP].Score)
+		assert.Len(t, checks[SecurityCheckCSP].Findings, 4)
+		assert.Equal(t, 5, checks[SecurityCheckHSTS].Score)
+		assert.Len(t, checks[SecurityCheckHSTS].Findings, 3)
+		assert.Equal(t, 0, checks[SecurityCheckFraming].Score)
+		assert.Contains(t, checks[SecurityCheckFraming].Findings[0].Message, "ALLOW-FROM")
+		assert.Equal(t, 5, checks[SecurityCheckReferrerPolicy].Score)
+		assert.Equal(t, 0, checks[SecurityCheckContentTypeOptions].Score)
+
+		require.Len(t, report.Cookies, 1)
+		assert.Len(t, report.Cookies[0].Findings, 2)
+		assert.Contains(t, report.Cookies[0].Findings[0].Message, "SameSite=None without Secure")
+	})
+
+	t.Run("HTTP page", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Strict-Transport-Security", "max-age=63072000")
+
+		report := securityHeaderReport(header, "http://example.com")
+
+		assert.Equal(t, 0, report.Checks[1].Score)
+		assert.Contains(t, report.Checks[1].Findings[0].Message, "served over HTTP")
+	})
+}
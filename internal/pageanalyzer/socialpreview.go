@@ -0,0 +1,148 @@
+package pageanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+// SocialPreview is the share card that social platforms build from the page's OpenGraph and Twitter Card metadata.
+type SocialPreview struct {
+	Title       string
+	Description string
+	Image       string
+	URL         string
+	SiteName    string
+	Domain      string
+	TwitterCard string
+
+	// ImageWidth and ImageHeight are the real dimensions of the fetched image, zero if it could not be decoded.
+	ImageWidth  int
+	ImageHeight int
+
+	OpenGraph map[string]string
+	Twitter   map[string]string
+
+	Issues []string
+}
+
+// HasLargeImage reports whether the card should be rendered with a large image instead of a thumbnail.
+func (s *SocialPreview) HasLargeImage() bool {
+	return s.Image != "" && s.TwitterCard != "summary"
+}
+
+func (w *WebpageAnalyzer) socialPreview(ctx context.Context, meta htmlextract.SocialMeta, pageURL, pageTitle string) *SocialPreview {
+	preview := &SocialPreview{
+		OpenGraph: meta.OpenGraph,
+		Twitter:   meta.Twitter,
+	}
+
+	// Check the properties each platform requires
+	for _, property := range []string{"og:title", "og:type", "og:image", "og:url"} {
+		if meta.OpenGraph[property] == "" {
+			preview.Issues = append(preview.Issues, fmt.Sprintf("missing required OpenGraph property %q", property))
+		}
+	}
+
+	preview.TwitterCard = meta.Twitter["twitter:card"]
+	switch preview.TwitterCard {
+	case "":
+		preview.Issues = append(preview.Issues, `missing required Twitter Card property "twitter:card"`)
+	case "summary", "summary_large_image", "app", "player":
+	default:
+		preview.Issues = append(preview.Issues, fmt.Sprintf("unknown twitter:card type %q", preview.TwitterCard))
+	}
+
+	// Platforms fall back from their own properties to OpenGraph and then to the document itself
+	preview.Title = firstNonEmpty(meta.Twitter["twitter:title"], meta.OpenGraph["og:title"], pageTitle)
+	preview.Description = firstNonEmpty(meta.Twitter["twitter:description"], meta.OpenGraph["og:description"])
+	preview.URL = firstNonEmpty(meta.OpenGraph["og:url"], pageURL)
+	preview.SiteName = meta.OpenGraph["og:site_name"]
+
+	if parsedURL, err := url.Parse(preview.URL); err == nil {
+		preview.Domain = parsedURL.Hostname()
+	}
+
+	image := firstNonEmpty(meta.OpenGraph["og:image"], meta.OpenGraph["og:image:url"], meta.Twitter["twitter:image"])
+	if image == "" {
+		return preview
+	}
+
+	resolvedImage, err := resolveLink(image, pageURL)
+	if err != nil {
+		preview.Issues = append(preview.Issues, fmt.Sprintf("invalid preview image URL %q", image))
+		return preview
+	}
+	preview.Image = resolvedImage
+
+	if resolvedImage != image {
+		preview.Issues = append(preview.Issues, "preview image URL should be absolute")
+	}
+
+	data := w.downloadImage(ctx, resolvedImage)
+	if data.err != nil {
+		preview.Issues = append(preview.Issues, fmt.Sprintf("preview image could not be loaded: %v", data.err))
+		return preview
+	}
+	if data.width == 0 || data.height == 0 {
+		format := data.format
+		if format == "" {
+			format = "unknown"
+		}
+		preview.Issues = append(preview.Issues, fmt.Sprintf("preview image dimensions could not be decoded from the %s image", format))
+		return preview
+	}
+	width, height := data.width, data.height
+	preview.ImageWidth = width
+	preview.ImageHeight = height
+
+	preview.Issues = append(preview.Issues, validateDeclaredDimension(meta.OpenGraph, "og:image:width", width)...)
+	preview.Issues = append(preview.Issues, validateDeclaredDimension(meta.OpenGraph, "og:image:height", height)...)
+
+	// Facebook and LinkedIn recommend at least 1200x630, Twitter requires less depending on the card type
+	minWidth, minHeight := 1200, 630
+	switch preview.TwitterCard {
+	case "summary":
+		minWidth, minHeight = 144, 144
+	case "summary_large_image":
+		minWidth, minHeight = 300, 157
+	}
+	if width < minWidth || height < minHeight {
+		preview.Issues = append(preview.Issues, fmt.Sprintf(
+			"preview image is %dx%d, recommended at least %dx%d", width, height, minWidth, minHeight,
+		))
+	}
+
+	return preview
+}
+
+func validateDeclaredDimension(openGraph map[string]string, property string, actual int) []string {
+	declared, ok := openGraph[property]
+	if !ok {
+		return nil
+	}
+
+	declaredNum, err := strconv.Atoi(declared)
+	if err != nil {
+		return []string{fmt.Sprintf("%s %q is not a number", property, declared)}
+	}
+
+	if declaredNum != actual {
+		return []string{fmt.Sprintf("%s is %d but the image is %d", property, declaredNum, actual)}
+	}
+
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,107 @@
+package pageanalyzer
+
+import (
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestSocialPreview(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/image.png":
+			_ = png.Encode(w, image.NewRGBA(image.Rect(0, 0, 600, 315)))
+		case "/image.webp":
+			_, _ = w.Write(webpImage(1200, 630))
+		case "/image.svg":
+			_, _ = w.Write([]byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer testServer.Close()
+
+	analyzer := New(&Config{}, testServer.Client())
+
+	t.Run("Complete metadata", func(t *testing.T) {
+		meta := htmlextract.SocialMeta{
+			OpenGraph: map[string]string{
+				"og:title":        "OG Title",
+				"og:type":         "website",
+				"og:image":        testServer.URL + "/image.png",
+				"og:image:width":  "1200",
+				"og:url":          "https://example.com/page",
+				"og:description":  "OG Description",
+				"og:image:height": "315",
+			},
+			Twitter: map[string]string{
+				"twitter:card":  "summary_large_image",
+				"twitter:title": "Twitter Title",
+			},
+		}
+
+		preview := analyzer.socialPreview(context.Background(), meta, "https://example.com/page", "Page Title")
+
+		assert.Equal(t, "Twitter Title", preview.Title)
+		assert.Equal(t, "OG Description", preview.Description)
+		assert.Equal(t, "example.com", preview.Domain)
+		assert.Equal(t, 600, preview.ImageWidth)
+		assert.Equal(t, 315, preview.ImageHeight)
+		assert.True(t, preview.HasLargeImage())
+		assert.Equal(t, []string{"og:image:width is 1200 but the image is 600"}, preview.Issues)
+	})
+
+	t.Run("WebP image", func(t *testing.T) {
+		meta := htmlextract.SocialMeta{
+			OpenGraph: map[string]string{
+				"og:title": "OG Title",
+				"og:type":  "website",
+				"og:image": testServer.URL + "/image.webp",
+				"og:url":   "https://example.com/page",
+			},
+			Twitter: map[string]string{"twitter:card": "summary_large_image"},
+		}
+
+		preview := analyzer.socialPreview(context.Background(), meta, "https://example.com/page", "Page Title")
+
+		assert.Equal(t, 1200, preview.ImageWidth)
+		assert.Equal(t, 630, preview.ImageHeight)
+		assert.Empty(t, preview.Issues)
+	})
+
+	t.Run("SVG image", func(t *testing.T) {
+		meta := htmlextract.SocialMeta{
+			OpenGraph: map[string]string{"og:image": testServer.URL + "/image.svg"},
+			Twitter:   map[string]string{},
+		}
+
+		preview := analyzer.socialPreview(context.Background(), meta, "https://example.com/page", "Page Title")
+
+		assert.Contains(t, preview.Issues, "preview image dimensions could not be decoded from the SVG image")
+	})
+
+	t.Run("Missing metadata", func(t *testing.T) {
+		meta := htmlextract.SocialMeta{
+			OpenGraph: map[string]string{
+				"og:image": "/missing.png",
+			},
+			Twitter: map[string]string{},
+		}
+
+		preview := analyzer.socialPreview(context.Background(), meta, testServer.URL, "Page Title")
+
+		assert.Equal(t, "Page Title", preview.Title)
+		assert.Equal(t, testServer.URL+"/missing.png", preview.Image)
+		assert.Contains(t, preview.Issues, `missing required OpenGraph property "og:title"`)
+		assert.Contains(t, preview.Issues, `missing required Twitter Card property "twitter:card"`)
+		assert.Contains(t, preview.Issues, "preview image URL should be absolute")
+		assert.Contains(t, preview.Issues, "preview image could not be loaded: request failed with status 404")
+	})
+}
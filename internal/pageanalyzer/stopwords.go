@@ -0,0 +1,52 @@
+package pageanalyzer
+
+import "strings"
+
+// stopWords holds the most common function words of each supported language. They are left out of the keywords
+// and their frequency identifies the language of the text.
+var stopWords = map[string]map[string]bool{
+	"en": wordSet(`a about above after again against all am an and any are as at be because been before being below
+		between both but by can could did do does doing down during each few for from further had has have having he her
+		here hers herself him himself his how i if in into is it its itself just me more most my myself no nor not now of
+		off on once only or other our ours ourselves out over own same she should so some such than that the their theirs
+		them themselves then there these they this those through to too under until up very was we were what when where
+		which while who whom why will with would you your yours yourself yourselves also may us new one get use`),
+	"de": wordSet(`aber alle allem allen aller alles als also am an ander andere anderem anderen anderer anderes auch auf
+		aus bei bin bis bist da damit dann das dass dein deine dem den der des dich die dies diese diesem diesen dieser
+		dieses dir doch dort du durch ein eine einem einen einer eines er es etwas euch euer für gegen hat hatte habe haben
+		hier hin hinter ich ihm ihn ihnen ihr ihre im in indem ins ist jede jedem jeden jeder jedes jetzt kann kein keine
+		man manche mein meine mich mir mit muss nach nicht nichts noch nun nur ob oder ohne sehr sein seine sich sie sind
+		so solche soll sondern über um und uns unser unter viel vom von vor war waren warst was weg weil weiter welche
+		wenn werde werden wie wieder will wir wird wo zu zum zur zwar zwischen`),
+	"fr": wordSet(`à au aux avec ce ces cette dans de des du elle elles en est et été être eu il ils je la le les leur
+		leurs lui ma mais me même mes moi mon ne nos notre nous on ont ou où par pas pour qu que qui sa se ses son sont
+		sur ta te tes toi ton tu un une vos votre vous y ceci cela celle celui comme donc dont encore entre ici lors
+		peu plus sans sous tous tout toute toutes très aussi avoir fait faire peut si`),
+	"es": wordSet(`a al algo algunas algunos ante antes como con contra cual cuando de del desde donde durante e el él
+		ella ellas ellos en entre era es esa esas ese eso esos esta estaba están este esto estos fue fueron ha han hasta
+		hay la las le les lo los más me mi mis mucho muy nada ni no nos nosotros o otra otros para pero poco por porque
+		que quien se sea ser si sí sin sobre son su sus también tanto te tiene todo todos tu tus un una uno unos y ya yo`),
+	"it": wordSet(`a ad al alla alle allo agli ai anche ancora avere aveva c che chi ci come con cosa da dal dalla dalle
+		degli dei del della delle dello di dove e è ed era essere gli ha hanno i il in io la le lei lo loro lui ma me
+		mi mio molto ne negli nei nel nella nelle nello noi non nostro o ogni per perché più poi quale quando quanto
+		quella quelle quello questa queste questo se sei si sia siamo sono su sua sue suo suoi sul sulla tra tu tutti
+		tutto un una uno voi`),
+	"nl": wordSet(`aan al alles als altijd andere ben bij daar dan dat de der deze die dit doch doen door dus een eens en
+		er ge geen geweest haar had heb hebben heeft hem het hier hij hoe hun iemand iets ik in is ja je kan kon kunnen
+		maar me meer men met mij mijn moet na naar niet niets nog nu of om omdat onder ons ook op over reeds te tegen
+		toch toen tot u uit uw van veel voor want waren was wat we wel werd wezen wie wij wil worden zal ze zelf zich zij
+		zijn zo zonder zou`),
+	"pt": wordSet(`a ao aos aquela aquele as às até com como da das de dela dele deles depois do dos e é ela elas ele
+		eles em entre era essa esse esta está este eu foi for há isso isto já lhe mais mas me mesmo meu minha muito na
+		não nas nem no nos nós nossa nosso num numa o os ou para pela pelo por qual quando que quem se sem ser seu sua
+		suas são também te tem tu um uma você vocês`),
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+
+	return set
+}
@@ -0,0 +1,86 @@
+package pageanalyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+// recommendedProperties lists, per schema.org type, the properties search engines expect for rich results.
+var recommendedProperties = map[string][]string{
+	"Article":        {"headline", "image", "datePublished", "author"},
+	"NewsArticle":    {"headline", "image", "datePublished", "author"},
+	"BlogPosting":    {"headline", "image", "datePublished", "author"},
+	"Product":        {"name", "image", "description", "offers"},
+	"Offer":          {"price", "priceCurrency", "availability"},
+	"BreadcrumbList": {"itemListElement"},
+	"ListItem":       {"position", "name"},
+	"Organization":   {"name", "url", "logo"},
+}
+
+// StructuredDataReport holds the structured data items of the page and the problems found in them.
+type StructuredDataReport struct {
+	Items []*htmlextract.StructuredDataItem
+	// Errors holds the blocks that could not be parsed
+	Errors []string
+	// Issues holds missing recommended properties
+	Issues []string
+}
+
+func validateStructuredData(structuredData htmlextract.StructuredData) *StructuredDataReport {
+	report := &StructuredDataReport{
+		Items:  structuredData.Items,
+		Errors: structuredData.Errors,
+	}
+
+	for _, item := range structuredData.Items {
+		report.Issues = append(report.Issues, structuredDataItemIssues(item, "")...)
+	}
+
+	return report
+}
+
+// structuredDataItemIssues checks the item and its nested items, path locates the item for the reader.
+func structuredDataItemIssues(item *htmlextract.StructuredDataItem, path string) []string {
+	if path == "" {
+		path = fmt.Sprintf("%s %s", item.Format, strings.Join(item.Types, ", "))
+	}
+
+	var issues []string
+
+	for _, itemType := range item.Types {
+		for _, property := range recommendedProperties[itemType] {
+			if len(item.Properties[property]) == 0 {
+				issues = append(issues, fmt.Sprintf("%s: missing recommended property %q", path, property))
+			}
+		}
+	}
+
+	// A breadcrumb entry needs a URL, except the last one which is the current page
+	if item.HasType("BreadcrumbList") {
+		elements := item.Properties["itemListElement"]
+		for i, element := range elements {
+			if element.Item != nil && i < len(elements)-1 && len(element.Item.Properties["item"]) == 0 {
+				issues = append(issues, fmt.Sprintf("%s > itemListElement[%d]: missing recommended property %q", path, i, "item"))
+			}
+		}
+	}
+
+	// Iterate in a stable order so the report doesn't change between runs
+	names := maps.Keys(item.Properties)
+	slices.Sort(names)
+
+	for _, name := range names {
+		for i, value := range item.Properties[name] {
+			if value.Item != nil {
+				issues = append(issues, structuredDataItemIssues(value.Item, fmt.Sprintf("%s > %s[%d]", path, name, i))...)
+			}
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,49 @@
+package pageanalyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestValidateStructuredData(t *testing.T) {
+	breadcrumbs := &htmlextract.StructuredDataItem{
+		Format: htmlextract.FormatJSONLD,
+		Types:  []string{"BreadcrumbList"},
+		Properties: map[string][]htmlextract.StructuredDataValue{
+			"itemListElement": {
+				{Item: &htmlextract.StructuredDataItem{
+					Types:      []string{"ListItem"},
+					Properties: map[string][]htmlextract.StructuredDataValue{"position": {{Text: "1"}}, "name": {{Text: "Home"}}},
+				}},
+				{Item: &htmlextract.StructuredDataItem{
+					Types:      []string{"ListItem"},
+					Properties: map[string][]htmlextract.StructuredDataValue{"position": {{Text: "2"}}},
+				}},
+			},
+		},
+	}
+	article := &htmlextract.StructuredDataItem{
+		Format: htmlextract.FormatMicrodata,
+		Types:  []string{"Article"},
+		Properties: map[string][]htmlextract.StructuredDataValue{
+			"headline":      {{Text: "Headline"}},
+			"image":         {{Text: "/image.png"}},
+			"datePublished": {{Text: "2024-01-01"}},
+		},
+	}
+
+	report := validateStructuredData(htmlextract.StructuredData{
+		Items:  []*htmlextract.StructuredDataItem{breadcrumbs, article},
+		Errors: []string{"JSON-LD block 3: syntax error at offset 10"},
+	})
+
+	assert.Equal(t, []string{"JSON-LD block 3: syntax error at offset 10"}, report.Errors)
+	assert.Equal(t, []string{
+		`JSON-LD BreadcrumbList > itemListElement[0]: missing recommended property "item"`,
+		`JSON-LD BreadcrumbList > itemListElement[1]: missing recommended property "name"`,
+		`Microdata Article: missing recommended property "author"`,
+	}, report.Issues)
+}
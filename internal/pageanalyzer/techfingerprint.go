@@ -0,0 +1,246 @@
+package pageanalyzer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// maxHTMLEvidenceLen caps the length of the HTML shown as evidence.
+const maxHTMLEvidenceLen = 100
+
+//go:embed techfingerprint_rules.json
+var defaultTechRules []byte
+
+// TechRule fingerprints a technology. Every pattern is a regular expression, its first capture group is the version.
+// An empty header pattern only requires the header to be present.
+type TechRule struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	// Headers maps response header names to patterns of their value.
+	Headers map[string]string `json:"headers"`
+	// Meta maps meta tag names, e.g. "generator", to patterns of their content.
+	Meta      map[string]string `json:"meta"`
+	ScriptSrc []string          `json:"scriptSrc"`
+	HTML      []string          `json:"html"`
+	// Cookies are patterns of the names of the cookies set by the response.
+	Cookies []string `json:"cookies"`
+	// Implies names technologies the technology is built on, e.g. PHP for WordPress.
+	Implies []string `json:"implies"`
+
+	headers   map[string]*regexp.Regexp
+	meta      map[string]*regexp.Regexp
+	scriptSrc []*regexp.Regexp
+	html      []*regexp.Regexp
+	cookies   []*regexp.Regexp
+}
+
+func (r *TechRule) compile() error {
+	compileMap := func(patterns map[string]string) (map[string]*regexp.Regexp, error) {
+		compiled := make(map[string]*regexp.Regexp, len(patterns))
+		for key, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("compile %q failed: %v", pattern, err)
+			}
+			compiled[key] = re
+		}
+		return compiled, nil
+	}
+
+	compileList := func(patterns []string) ([]*regexp.Regexp, error) {
+		var compiled []*regexp.Regexp
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("compile %q failed: %v", pattern, err)
+			}
+			compiled = append(compiled, re)
+		}
+		return compiled, nil
+	}
+
+	var err error
+	if r.headers, err = compileMap(r.Headers); err != nil {
+		return err
+	}
+	if r.meta, err = compileMap(r.Meta); err != nil {
+		return err
+	}
+	if r.scriptSrc, err = compileList(r.ScriptSrc); err != nil {
+		return err
+	}
+	if r.html, err = compileList(r.HTML); err != nil {
+		return err
+	}
+	if r.cookies, err = compileList(r.Cookies); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LoadTechRules reads technology fingerprint rules, in the format of the bundled techfingerprint_rules.json.
+// The bundled rules are returned if the path is empty.
+func LoadTechRules(path string) ([]*TechRule, error) {
+	data := defaultTechRules
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read technology rules failed: %v", err)
+		}
+	}
+
+	var ruleFile struct {
+		Technologies []*TechRule `json:"technologies"`
+	}
+	if err := json.Unmarshal(data, &ruleFile); err != nil {
+		return nil, fmt.Errorf("parse technology rules failed: %v", err)
+	}
+
+	for _, rule := range ruleFile.Technologies {
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("technology %q: %v", rule.Name, err)
+		}
+	}
+
+	return ruleFile.Technologies, nil
+}
+
+// Technology is a technology detected on the page.
+type Technology struct {
+	Name     string
+	Category string
+	// Version is empty if no evidence tells it.
+	Version  string
+	Evidence []string
+}
+
+// techInput is what the fingerprint rules are matched against.
+type techInput struct {
+	header     http.Header
+	metaTags   map[string][]string
+	scriptSrcs []string
+	html       []byte
+}
+
+// detectTechnologies matches the rules against the page and returns the detected technologies by category and name.
+func detectTechnologies(rules []*TechRule, input techInput) []*Technology {
+	var technologies []*Technology
+
+	cookieNames := make([]string, 0)
+	for _, cookie := range (&http.Response{Header: input.header}).Cookies() {
+		cookieNames = append(cookieNames, cookie.Name)
+	}
+
+	for _, rule := range rules {
+		technology := &Technology{Name: rule.Name, Category: rule.Category}
+
+		match := func(re *regexp.Regexp, value, evidence string) {
+			submatches := re.FindStringSubmatch(value)
+			if submatches == nil {
+				return
+			}
+			if technology.Version == "" && len(submatches) > 1 {
+				technology.Version = submatches[1]
+			}
+			technology.Evidence = append(technology.Evidence, evidence)
+		}
+
+		headerNames := maps.Keys(rule.headers)
+		slices.Sort(headerNames)
+		for _, name := range headerNames {
+			for _, value := range input.header.Values(name) {
+				match(rule.headers[name], value, fmt.Sprintf("header %s: %s", http.CanonicalHeaderKey(name), value))
+			}
+		}
+
+		metaNames := maps.Keys(rule.meta)
+		slices.Sort(metaNames)
+		for _, name := range metaNames {
+			for _, content := range input.metaTags[strings.ToLower(name)] {
+				match(rule.meta[name], content, fmt.Sprintf("meta %s: %s", name, content))
+			}
+		}
+
+		for _, re := range rule.scriptSrc {
+			for _, src := range input.scriptSrcs {
+				match(re, src, "script src: "+src)
+			}
+		}
+
+		for _, re := range rule.html {
+			if submatch := re.Find(input.html); submatch != nil {
+				match(re, string(submatch), "html: "+truncate(string(submatch), maxHTMLEvidenceLen))
+			}
+		}
+
+		for _, re := range rule.cookies {
+			for _, name := range cookieNames {
+				match(re, name, "cookie: "+name)
+			}
+		}
+
+		if len(technology.Evidence) > 0 {
+			technologies = append(technologies, technology)
+		}
+	}
+
+	technologies = addImpliedTechnologies(rules, technologies)
+
+	slices.SortFunc(technologies, func(a, b *Technology) int {
+		if a.Category != b.Category {
+			return strings.Compare(a.Category, b.Category)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return technologies
+}
+
+// addImpliedTechnologies adds the technologies implied by the detected ones, following chains of implications.
+func addImpliedTechnologies(rules []*TechRule, technologies []*Technology) []*Technology {
+	for i := 0; i < len(technologies); i++ {
+		ruleIndex := slices.IndexFunc(rules, func(rule *TechRule) bool { return rule.Name == technologies[i].Name })
+		if ruleIndex == -1 {
+			continue
+		}
+
+		for _, implied := range rules[ruleIndex].Implies {
+			evidence := "implied by " + technologies[i].Name
+
+			detectedIndex := slices.IndexFunc(technologies, func(technology *Technology) bool { return technology.Name == implied })
+			if detectedIndex != -1 {
+				if !slices.Contains(technologies[detectedIndex].Evidence, evidence) {
+					technologies[detectedIndex].Evidence = append(technologies[detectedIndex].Evidence, evidence)
+				}
+				continue
+			}
+
+			technology := &Technology{Name: implied, Evidence: []string{evidence}}
+			if impliedIndex := slices.IndexFunc(rules, func(rule *TechRule) bool { return rule.Name == implied }); impliedIndex != -1 {
+				technology.Category = rules[impliedIndex].Category
+			}
+			technologies = append(technologies, technology)
+		}
+	}
+
+	return technologies
+}
+
+func truncate(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+
+	return string(runes[:maxLen]) + "..."
+}
@@ -0,0 +1,79 @@
+package pageanalyzer
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTechRules(t *testing.T) {
+	t.Run("Bundled rules", func(t *testing.T) {
+		rules, err := LoadTechRules("")
+		require.NoError(t, err)
+		assert.NotEmpty(t, rules)
+
+		names := make(map[string]bool)
+		for _, rule := range rules {
+			names[rule.Name] = true
+		}
+		for _, rule := range rules {
+			for _, implied := range rule.Implies {
+				assert.True(t, names[implied], "%s implies unknown technology %s", rule.Name, implied)
+			}
+		}
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rules.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"technologies": [{"name": "Broken", "html": ["("]}]}`), 0o600))
+
+		_, err := LoadTechRules(path)
+		assert.ErrorContains(t, err, `technology "Broken"`)
+	})
+}
+
+func TestDetectTechnologies(t *testing.T) {
+	rules, err := LoadTechRules("")
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("Server", "nginx/1.25.3")
+	header.Set("CF-Ray", "8a1b2c3d4e5f-FRA")
+	header.Add("Set-Cookie", "wordpress_test_cookie=WP%20Cookie%20check; path=/")
+
+	technologies := detectTechnologies(rules, techInput{
+		header:   header,
+		metaTags: map[string][]string{"generator": {"WordPress 6.4.2"}},
+		scriptSrcs: []string{
+			"https://example.com/wp-includes/js/jquery/jquery.min.js",
+			"https://code.jquery.com/jquery-3.7.1.min.js",
+		},
+		html: []byte(`<html><head><link rel="stylesheet" href="/wp-content/themes/site/style.css"></head></html>`),
+	})
+
+	assert.Equal(t, []*Technology{
+		{Name: "Cloudflare", Category: "cdn", Evidence: []string{"header Cf-Ray: 8a1b2c3d4e5f-FRA"}},
+		{
+			Name: "WordPress", Category: "cms", Version: "6.4.2",
+			Evidence: []string{
+				"meta generator: WordPress 6.4.2",
+				"script src: https://example.com/wp-includes/js/jquery/jquery.min.js",
+				`html: <link rel="stylesheet" href="/wp-content/`,
+				"cookie: wordpress_test_cookie",
+			},
+		},
+		{
+			Name: "jQuery", Category: "js-library", Version: "3.7.1",
+			Evidence: []string{
+				"script src: https://code.jquery.com/jquery-3.7.1.min.js",
+				"script src: https://example.com/wp-includes/js/jquery/jquery.min.js",
+			},
+		},
+		{Name: "PHP", Category: "language", Evidence: []string{"implied by WordPress"}},
+		{Name: "Nginx", Category: "web-server", Version: "1.25.3", Evidence: []string{"header Server: nginx/1.25.3"}},
+	}, technologies)
+}
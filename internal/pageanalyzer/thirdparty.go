@@ -0,0 +1,165 @@
+package pageanalyzer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+// Vendor categories
+const (
+	VendorCategoryAnalytics   = "analytics"
+	VendorCategoryAdvertising = "advertising"
+	VendorCategoryTagManager  = "tag-manager"
+	VendorCategoryCDN         = "cdn"
+	VendorCategorySocial      = "social"
+)
+
+// thirdPartyResourceTypes are the resources a third party can use to run code, track or style the page.
+var thirdPartyResourceTypes = []string{
+	htmlextract.ResourceTypeScript, htmlextract.ResourceTypeIFrame, htmlextract.ResourceTypeStylesheet,
+	htmlextract.ResourceTypeImage, htmlextract.ResourceTypePreload, htmlextract.ResourceTypeEmbed,
+}
+
+//go:embed thirdparty_vendors.json
+var defaultVendorSignatures []byte
+
+// VendorSignature identifies a third-party vendor by the URLs it serves.
+type VendorSignature struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	// Patterns are domains, optionally with a path prefix, e.g. "facebook.com/tr". A domain also matches its subdomains.
+	Patterns []string `json:"patterns"`
+}
+
+// LoadVendorSignatures reads a vendor signature database, in the format of the bundled thirdparty_vendors.json.
+// The bundled database is returned if the path is empty.
+func LoadVendorSignatures(path string) ([]VendorSignature, error) {
+	data := defaultVendorSignatures
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read vendor signatures failed: %v", err)
+		}
+	}
+
+	var database struct {
+		Vendors []VendorSignature `json:"vendors"`
+	}
+	if err := json.Unmarshal(data, &database); err != nil {
+		return nil, fmt.Errorf("parse vendor signatures failed: %v", err)
+	}
+
+	return database.Vendors, nil
+}
+
+// ThirdPartyElement is an element of the page that loads a third-party resource.
+type ThirdPartyElement struct {
+	// Kind is the resource type, or "pixel" for tracking pixels.
+	Kind string
+	URL  string
+}
+
+// ThirdPartyVendor is a vendor the page loads resources from. Name is empty if no signature matches.
+type ThirdPartyVendor struct {
+	Name     string
+	Category string
+	Elements []ThirdPartyElement
+}
+
+// ThirdPartyDomain groups the third-party resources of a registrable domain.
+type ThirdPartyDomain struct {
+	Domain  string
+	Vendors []*ThirdPartyVendor
+}
+
+// ThirdPartyReport is the inventory of the third parties the page loads resources from.
+type ThirdPartyReport struct {
+	Domains []*ThirdPartyDomain
+	// CategoryCounts holds the number of vendors per category.
+	CategoryCounts map[string]int
+}
+
+// thirdPartyReport groups the resources outside the registrable domain of the page by domain and vendor.
+func thirdPartyReport(resources []*PageResource, pageURL string, signatures []VendorSignature) *ThirdPartyReport {
+	report := &ThirdPartyReport{CategoryCounts: make(map[string]int)}
+	firstParty := Scope{Mode: ScopeRegistrableDomain}
+
+	domainToReport := make(map[string]*ThirdPartyDomain)
+	for _, resource := range resources {
+		if !slices.Contains(thirdPartyResourceTypes, resource.Type) || firstParty.IsInternal(resource.URL, pageURL) {
+			continue
+		}
+
+		resourceURL, err := url.Parse(resource.URL)
+		if err != nil || resourceURL.Hostname() == "" {
+			continue
+		}
+
+		hostname := strings.ToLower(resourceURL.Hostname())
+		domain, err := publicsuffix.EffectiveTLDPlusOne(hostname)
+		if err != nil {
+			domain = hostname
+		}
+
+		domainReport, ok := domainToReport[domain]
+		if !ok {
+			domainReport = &ThirdPartyDomain{Domain: domain}
+			domainToReport[domain] = domainReport
+			report.Domains = append(report.Domains, domainReport)
+		}
+
+		var name, category string
+		if signature := matchVendor(resourceURL, signatures); signature != nil {
+			name, category = signature.Name, signature.Category
+		}
+
+		vendorIndex := slices.IndexFunc(domainReport.Vendors, func(vendor *ThirdPartyVendor) bool { return vendor.Name == name })
+		if vendorIndex == -1 {
+			domainReport.Vendors = append(domainReport.Vendors, &ThirdPartyVendor{Name: name, Category: category})
+			vendorIndex = len(domainReport.Vendors) - 1
+			if category != "" {
+				report.CategoryCounts[category]++
+			}
+		}
+
+		kind := resource.Type
+		if resource.Pixel {
+			kind = "pixel"
+		}
+
+		vendor := domainReport.Vendors[vendorIndex]
+		vendor.Elements = append(vendor.Elements, ThirdPartyElement{Kind: kind, URL: resource.URL})
+	}
+
+	slices.SortFunc(report.Domains, func(a, b *ThirdPartyDomain) int { return strings.Compare(a.Domain, b.Domain) })
+
+	return report
+}
+
+// matchVendor returns the vendor with the most specific pattern matching the URL.
+func matchVendor(resourceURL *url.URL, signatures []VendorSignature) *VendorSignature {
+	var (
+		match          *VendorSignature
+		matchedPattern string
+	)
+
+	for i, signature := range signatures {
+		for _, pattern := range signature.Patterns {
+			if len(pattern) > len(matchedPattern) && matchesScopeEntry(resourceURL, pattern) {
+				match, matchedPattern = &signatures[i], pattern
+			}
+		}
+	}
+
+	return match
+}
@@ -0,0 +1,102 @@
+package pageanalyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Rezab98/web-analyzer/internal/pageanalyzer/htmlextract"
+)
+
+func TestLoadVendorSignatures(t *testing.T) {
+	t.Run("Bundled database", func(t *testing.T) {
+		signatures, err := LoadVendorSignatures("")
+		require.NoError(t, err)
+		assert.NotEmpty(t, signatures)
+
+		categories := []string{VendorCategoryAnalytics, VendorCategoryAdvertising, VendorCategoryTagManager, VendorCategoryCDN, VendorCategorySocial}
+		for _, signature := range signatures {
+			assert.NotEmpty(t, signature.Patterns, signature.Name)
+			assert.Contains(t, categories, signature.Category, signature.Name)
+		}
+	})
+
+	t.Run("Database file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "vendors.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"vendors": [{"name": "Tracker", "category": "analytics", "patterns": ["tracker.example"]}]}`), 0o600))
+
+		signatures, err := LoadVendorSignatures(path)
+		require.NoError(t, err)
+		assert.Equal(t, []VendorSignature{{Name: "Tracker", Category: "analytics", Patterns: []string{"tracker.example"}}}, signatures)
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := LoadVendorSignatures(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestThirdPartyReport(t *testing.T) {
+	signatures, err := LoadVendorSignatures("")
+	require.NoError(t, err)
+
+	resources := []*PageResource{
+		{Type: htmlextract.ResourceTypeImage, URL: "https://www.example.com/logo.png"},
+		{Type: htmlextract.ResourceTypeImage, URL: "https://www.facebook.com/tr?id=1", Pixel: true},
+		{Type: htmlextract.ResourceTypeScript, URL: "https://www.googletagmanager.com/gtag/js?id=G-1"},
+		{Type: htmlextract.ResourceTypeScript, URL: "https://www.googletagmanager.com/gtm.js?id=GTM-1"},
+		{Type: htmlextract.ResourceTypeScript, URL: "https://connect.facebook.net/en_US/sdk.js"},
+		{Type: htmlextract.ResourceTypeStylesheet, URL: "https://static.example.org/style.css"},
+		{Type: htmlextract.ResourceTypeFormAction, URL: "https://forms.example.net/submit"},
+		// Vendor path prefixes match whole segments
+		{Type: htmlextract.ResourceTypeIFrame, URL: "https://www.facebook.com/travel"},
+		{Type: htmlextract.ResourceTypeStylesheet, URL: "https://s.pinimg.com/css/main.css"},
+	}
+
+	report := thirdPartyReport(resources, "https://blog.example.com/", signatures)
+
+	assert.Equal(t, []*ThirdPartyDomain{
+		{
+			Domain: "example.org",
+			Vendors: []*ThirdPartyVendor{
+				{Elements: []ThirdPartyElement{{Kind: "stylesheet", URL: "https://static.example.org/style.css"}}},
+			},
+		},
+		{
+			Domain: "facebook.com",
+			Vendors: []*ThirdPartyVendor{
+				{Name: "Meta Pixel", Category: VendorCategoryAdvertising, Elements: []ThirdPartyElement{{Kind: "pixel", URL: "https://www.facebook.com/tr?id=1"}}},
+				{Elements: []ThirdPartyElement{{Kind: "iframe", URL: "https://www.facebook.com/travel"}}},
+			},
+		},
+		{
+			Domain: "facebook.net",
+			Vendors: []*ThirdPartyVendor{
+				{Name: "Facebook Social Plugins", Category: VendorCategorySocial, Elements: []ThirdPartyElement{{Kind: "script", URL: "https://connect.facebook.net/en_US/sdk.js"}}},
+			},
+		},
+		{
+			Domain: "googletagmanager.com",
+			Vendors: []*ThirdPartyVendor{
+				{Name: "Google Analytics", Category: VendorCategoryAnalytics, Elements: []ThirdPartyElement{{Kind: "script", URL: "https://www.googletagmanager.com/gtag/js?id=G-1"}}},
+				{Name: "Google Tag Manager", Category: VendorCategoryTagManager, Elements: []ThirdPartyElement{{Kind: "script", URL: "https://www.googletagmanager.com/gtm.js?id=GTM-1"}}},
+			},
+		},
+		{
+			Domain: "pinimg.com",
+			Vendors: []*ThirdPartyVendor{
+				{Elements: []ThirdPartyElement{{Kind: "stylesheet", URL: "https://s.pinimg.com/css/main.css"}}},
+			},
+		},
+	}, report.Domains)
+
+	assert.Equal(t, map[string]int{
+		VendorCategoryAdvertising: 1,
+		VendorCategoryAnalytics:   1,
+		VendorCategorySocial:      1,
+		VendorCategoryTagManager:  1,
+	}, report.CategoryCounts)
+}
@@ -0,0 +1,347 @@
+package pageanalyzer
+
+import (
+	"context"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// tlsDialTimeout caps the TLS handshake with an external host.
+	tlsDialTimeout = 5 * time.Second
+	// tlsCacheTTL is how long the inspection of an external host is reused.
+	tlsCacheTTL = time.Hour
+	// tlsCacheMaxEntries bounds the number of cached hosts, the entry expiring first is evicted to make room.
+	tlsCacheMaxEntries = 1000
+	// minRSAKeyBits and minECDSAKeyBits are the smallest keys considered strong.
+	minRSAKeyBits   = 2048
+	minECDSAKeyBits = 256
+)
+
+var tlsVersionNames = map[uint16]string{
+	tls.VersionSSL30: "SSL 3.0",
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+// weakSignatureAlgorithms are broken signature algorithms browsers no longer accept.
+var weakSignatureAlgorithms = []x509.SignatureAlgorithm{
+	x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1,
+}
+
+// CertificateInfo describes a certificate of the chain sent by the server.
+type CertificateInfo struct {
+	Subject            string
+	SANs               []string
+	Issuer             string
+	NotBefore          time.Time
+	NotAfter           time.Time
+	KeyType            string
+	KeySize            int
+	SignatureAlgorithm string
+}
+
+// TLSReport describes the TLS connection to a host and its certificate chain.
+type TLSReport struct {
+	Host        string
+	Version     string
+	CipherSuite string
+	// Chain starts with the leaf certificate.
+	Chain    []CertificateInfo
+	Warnings []string
+	// Error is set if the connection could not be established, e.g. for external hosts.
+	Error string
+}
+
+// tlsCacheEntry is a cached inspection of an external host.
+type tlsCacheEntry struct {
+	report    *TLSReport
+	expiresAt time.Time
+}
+
+// tlsCache caches the inspections of external hosts across analyses.
+type tlsCache struct {
+	lock    sync.Mutex
+	entries map[string]tlsCacheEntry
+}
+
+func (c *tlsCache) get(host string, now time.Time) (*TLSReport, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[host]
+	if !ok {
+		return nil, false
+	}
+	if now.After(entry.expiresAt) {
+		delete(c.entries, host)
+		return nil, false
+	}
+
+	return entry.report, true
+}
+
+func (c *tlsCache) set(host string, report *TLSReport, now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]tlsCacheEntry)
+	}
+
+	if _, ok := c.entries[host]; !ok && len(c.entries) >= tlsCacheMaxEntries {
+		c.evict(now)
+	}
+	c.entries[host] = tlsCacheEntry{report: report, expiresAt: now.Add(tlsCacheTTL)}
+}
+
+// evict deletes the expired entries, or the entry expiring first if none has expired.
+func (c *tlsCache) evict(now time.Time) {
+	var (
+		firstHost      string
+		firstExpiresAt time.Time
+	)
+	for host, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, host)
+			continue
+		}
+		if firstHost == "" || entry.expiresAt.Before(firstExpiresAt) {
+			firstHost, firstExpiresAt = host, entry.expiresAt
+		}
+	}
+
+	if len(c.entries) >= tlsCacheMaxEntries {
+		delete(c.entries, firstHost)
+	}
+}
+
+// inspectTLS reports the connection and the certificate chain, with warnings for certificates that expire within
+// expiryWarningDays, hostname mismatches, weak keys and deprecated protocols.
+func inspectTLS(state *tls.ConnectionState, host string, roots *x509.CertPool, now time.Time, expiryWarningDays int) *TLSReport {
+	report := &TLSReport{
+		Host:        host,
+		Version:     tlsVersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+	}
+
+	if state.Version < tls.VersionTLS12 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%s is deprecated, use TLS 1.2 or TLS 1.3", report.Version))
+	}
+
+	if slices.ContainsFunc(tls.InsecureCipherSuites(), func(suite *tls.CipherSuite) bool { return suite.ID == state.CipherSuite }) {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("cipher suite %s is insecure", report.CipherSuite))
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		report.Warnings = append(report.Warnings, "the server sent no certificate")
+		return report
+	}
+
+	for i, cert := range state.PeerCertificates {
+		info := certificateInfo(cert)
+		report.Chain = append(report.Chain, info)
+
+		switch {
+		case info.KeyType == "RSA" && info.KeySize < minRSAKeyBits,
+			info.KeyType == "ECDSA" && info.KeySize < minECDSAKeyBits,
+			info.KeyType == "DSA":
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%q has a weak %d-bit %s key", info.Subject, info.KeySize, info.KeyType))
+		}
+
+		// Self-signed roots are trusted by their presence in the trust store, not by their signature
+		isRoot := i > 0 && cert.Subject.String() == cert.Issuer.String()
+		if !isRoot && slices.Contains(weakSignatureAlgorithms, cert.SignatureAlgorithm) {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%q is signed with the weak %s algorithm", info.Subject, info.SignatureAlgorithm))
+		}
+
+		daysLeft := int(cert.NotAfter.Sub(now).Hours() / 24)
+		switch {
+		case now.After(cert.NotAfter):
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%q expired on %s", info.Subject, cert.NotAfter.Format("2006-01-02")))
+		case now.Before(cert.NotBefore):
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%q is not valid before %s", info.Subject, cert.NotBefore.Format("2006-01-02")))
+		case daysLeft < expiryWarningDays:
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%q expires in %d days, on %s", info.Subject, daysLeft, cert.NotAfter.Format("2006-01-02")))
+		}
+	}
+
+	leaf := state.PeerCertificates[0]
+	if err := leaf.VerifyHostname(host); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("the certificate is not valid for %s", host))
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	// Expiry and hostname are reported above, so only the chain of trust is verified here, at a time the leaf is valid
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   leaf.NotBefore.Add(leaf.NotAfter.Sub(leaf.NotBefore) / 2),
+	})
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	if errors.As(err, &unknownAuthorityErr) {
+		report.Warnings = append(report.Warnings, "the certificate chain is not signed by a trusted authority")
+	} else if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("the certificate chain is invalid: %v", err))
+	}
+
+	return report
+}
+
+// tlsServerName returns the host name the client connected to, which differs from the page host after a redirect.
+func tlsServerName(state *tls.ConnectionState, pageURL string) string {
+	if state.ServerName != "" {
+		return state.ServerName
+	}
+
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+
+	return page.Hostname()
+}
+
+func certificateInfo(cert *x509.Certificate) CertificateInfo {
+	info := CertificateInfo{
+		Subject:            cert.Subject.String(),
+		SANs:               cert.DNSNames,
+		Issuer:             cert.Issuer.String(),
+		NotBefore:          cert.NotBefore,
+		NotAfter:           cert.NotAfter,
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+	}
+
+	for _, ip := range cert.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		info.KeyType, info.KeySize = "RSA", key.N.BitLen()
+	case *ecdsa.PublicKey:
+		info.KeyType, info.KeySize = "ECDSA", key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		info.KeyType, info.KeySize = "Ed25519", 256
+	case *dsa.PublicKey:
+		info.KeyType, info.KeySize = "DSA", key.P.BitLen()
+	default:
+		info.KeyType = "unknown"
+	}
+
+	return info
+}
+
+func tlsVersionName(version uint16) string {
+	if name, ok := tlsVersionNames[version]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("0x%04x", version)
+}
+
+// rootCAs returns the trusted roots of the HTTP client, nil means the system roots.
+func (w *WebpageAnalyzer) rootCAs() *x509.CertPool {
+	if transport, ok := w.httpClient.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+		return transport.TLSClientConfig.RootCAs
+	}
+
+	return nil
+}
+
+// inspectExternalTLS inspects the TLS connection of each distinct HTTPS host of the links, reusing cached results.
+func (w *WebpageAnalyzer) inspectExternalTLS(ctx context.Context, links []string) []*TLSReport {
+	var hosts []string
+	for _, link := range links {
+		parsedLink, err := url.Parse(link)
+		if err != nil || !strings.EqualFold(parsedLink.Scheme, "https") {
+			continue
+		}
+
+		host := strings.ToLower(parsedLink.Host)
+		if !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	slices.Sort(hosts)
+
+	var (
+		reports   = make([]*TLSReport, len(hosts))
+		wg        sync.WaitGroup
+		semaphore = make(chan struct{}, linkCheckConcurrency)
+	)
+
+	for i, host := range hosts {
+		if report, ok := w.tlsCache.get(host, time.Now()); ok {
+			reports[i] = report
+			continue
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(i int, host string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			reports[i] = w.inspectHostTLS(ctx, host)
+		}(i, host)
+	}
+
+	wg.Wait()
+
+	return reports
+}
+
+// inspectHostTLS connects to the host without verifying the certificate, so invalid certificates can be reported.
+func (w *WebpageAnalyzer) inspectHostTLS(ctx context.Context, host string) *TLSReport {
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname, port = host, "443"
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: tlsDialTimeout},
+		Config: &tls.Config{
+			ServerName:         hostname,
+			InsecureSkipVerify: true,
+			MinVersion:         tls.VersionTLS10,
+		},
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(hostname, port))
+	if err != nil {
+		logrus.WithError(err).WithField("host", host).Debug("tls dial failed")
+		// Failures are not cached, they may be caused by the request being canceled
+		return &TLSReport{Host: host, Error: fmt.Sprintf("connection failed: %v", err)}
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	report := inspectTLS(&state, hostname, w.rootCAs(), time.Now(), w.cfg.CertExpiryWarningDays)
+	report.Host = host
+	w.tlsCache.set(host, report, time.Now())
+
+	return report
+}
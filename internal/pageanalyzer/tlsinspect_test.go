@@ -0,0 +1,118 @@
+package pageanalyzer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectTLS(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer testServer.Close()
+
+	resp, err := testServer.Client().Get(testServer.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	cert := testServer.Certificate()
+	roots := testServer.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	t.Run("Valid certificate", func(t *testing.T) {
+		report := inspectTLS(resp.TLS, "example.com", roots, time.Now(), 30)
+
+		assert.Equal(t, "TLS 1.3", report.Version)
+		assert.NotEmpty(t, report.CipherSuite)
+		require.Len(t, report.Chain, 1)
+		assert.Equal(t, cert.NotAfter, report.Chain[0].NotAfter)
+		assert.Contains(t, report.Chain[0].SANs, "example.com")
+		assert.Contains(t, report.Chain[0].SANs, "127.0.0.1")
+		assert.Equal(t, "RSA", report.Chain[0].KeyType)
+		assert.Empty(t, report.Warnings)
+	})
+
+	t.Run("Expiring certificate and hostname mismatch", func(t *testing.T) {
+		report := inspectTLS(resp.TLS, "example.org", nil, cert.NotAfter.Add(-10*24*time.Hour), 30)
+
+		require.Len(t, report.Warnings, 3)
+		assert.Contains(t, report.Warnings[0], "expires in 10 days")
+		assert.Equal(t, "the certificate is not valid for example.org", report.Warnings[1])
+		assert.Equal(t, "the certificate chain is not signed by a trusted authority", report.Warnings[2])
+	})
+
+	t.Run("Deprecated protocol", func(t *testing.T) {
+		state := *resp.TLS
+		state.Version = tls.VersionTLS10
+
+		report := inspectTLS(&state, "example.com", roots, time.Now(), 30)
+
+		assert.Equal(t, []string{"TLS 1.0 is deprecated, use TLS 1.2 or TLS 1.3"}, report.Warnings)
+	})
+}
+
+func TestInspectExternalTLS(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer testServer.Close()
+
+	analyzer := New(&Config{CertExpiryWarningDays: 30}, testServer.Client())
+	host := strings.TrimPrefix(testServer.URL, "https://")
+
+	reports := analyzer.inspectExternalTLS(context.Background(), []string{
+		testServer.URL + "/a",
+		testServer.URL + "/b",
+		"http://example.com/",
+	})
+
+	require.Len(t, reports, 1)
+	assert.Equal(t, host, reports[0].Host)
+	assert.Empty(t, reports[0].Error)
+	assert.Empty(t, reports[0].Warnings)
+
+	// The second inspection is served from the cache, even after the server is gone
+	testServer.Close()
+	cached := analyzer.inspectExternalTLS(context.Background(), []string{testServer.URL})
+	assert.Same(t, reports[0], cached[0])
+}
+
+func TestTLSCache(t *testing.T) {
+	now := time.Now()
+	report := &TLSReport{Host: "example.com"}
+
+	t.Run("Expired entries are deleted", func(t *testing.T) {
+		cache := &tlsCache{}
+		cache.set("example.com", report, now)
+
+		cached, ok := cache.get("example.com", now.Add(tlsCacheTTL/2))
+		assert.True(t, ok)
+		assert.Same(t, report, cached)
+
+		_, ok = cache.get("example.com", now.Add(2*tlsCacheTTL))
+		assert.False(t, ok)
+		assert.Empty(t, cache.entries)
+	})
+
+	t.Run("Size is bounded", func(t *testing.T) {
+		cache := &tlsCache{}
+		for i := 0; i < tlsCacheMaxEntries; i++ {
+			cache.set(fmt.Sprintf("host%d.example.com", i), report, now.Add(time.Duration(i)*time.Second))
+		}
+
+		// The cache is full, the entry expiring first makes room
+		cache.set("example.com", report, now.Add(tlsCacheMaxEntries*time.Second))
+		assert.Len(t, cache.entries, tlsCacheMaxEntries)
+		assert.NotContains(t, cache.entries, "host0.example.com")
+		assert.Contains(t, cache.entries, "example.com")
+
+		// Expired entries are all swept at once, the second half of the hosts is kept
+		cache.set("example.org", report, now.Add(tlsCacheTTL+tlsCacheMaxEntries/2*time.Second))
+		assert.Len(t, cache.entries, tlsCacheMaxEntries/2+2)
+		assert.NotContains(t, cache.entries, fmt.Sprintf("host%d.example.com", tlsCacheMaxEntries/2-1))
+	})
+}
@@ -1,11 +1,15 @@
 package pagedownloader
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 )
 
 // ErrNotfound is returned when a webpage is not found
@@ -13,6 +17,19 @@ var (
 	ErrNotfound = errors.New("page not found")
 )
 
+// Page is a downloaded webpage.
+type Page struct {
+	// URL is the URL of the page after following redirects
+	URL string
+	// Content is the decoded body
+	Content []byte
+	// TransferredBytes is the size of the body before decoding its Content-Encoding
+	TransferredBytes int64
+	Header           http.Header
+	// TLS is nil if the page is not served over HTTPS
+	TLS *tls.ConnectionState
+}
+
 type SimpleWebPageDownloader struct {
 	client *http.Client
 }
@@ -23,12 +40,16 @@ func New(client *http.Client) *SimpleWebPageDownloader {
 	}
 }
 
-func (d *SimpleWebPageDownloader) Download(ctx context.Context, url string) ([]byte, error) {
+func (d *SimpleWebPageDownloader) Download(ctx context.Context, url string) (*Page, error) {
 	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request failed: %v", err)
 
 	}
+
+	// Setting the header disables the transparent decompression of the client, so the transferred size is known
+	request.Header.Set("Accept-Encoding", "gzip")
+
 	resp, err := d.client.Do(request)
 	if err != nil {
 		return nil, fmt.Errorf("client GET failed: %v", err)
@@ -50,5 +71,38 @@ func (d *SimpleWebPageDownloader) Download(ctx context.Context, url string) ([]b
 		return nil, fmt.Errorf("read all data failed: %v", err)
 	}
 
-	return body, nil
+	content, err := decodeBody(body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page{
+		URL:              resp.Request.URL.String(),
+		Content:          content,
+		TransferredBytes: int64(len(body)),
+		Header:           resp.Header,
+		TLS:              resp.TLS,
+	}, nil
+}
+
+// decodeBody decodes a body served with the gzip encoding the request accepts.
+func decodeBody(body []byte, contentEncoding string) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("new gzip reader failed: %v", err)
+		}
+
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("decode gzip content failed: %v", err)
+		}
+
+		return content, nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", contentEncoding)
+	}
 }
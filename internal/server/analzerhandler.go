@@ -4,10 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html/template"
 	"net/http"
 	"regexp"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -52,12 +52,41 @@ type TemplateData struct {
 	Title                string
 	HeadingTagToTexts    map[string][]string
 	HeadingTagToTextsNum map[string]int
+	Outline              *pageanalyzer.Outline
 	InternalLinksNum     int
 	ExternalLinksNum     int
 	InternalLinks        []string
 	ExternalLinks        []string
 	InaccessibleLinksNum int
+	LinkKindCounts       []pageanalyzer.LinkKindCount
+	LinkAttributes       *pageanalyzer.LinkAttributeReport
+	BrokenFragmentLinks  []string
+	Resources            *pageanalyzer.ResourceReport
+	MixedContent         *pageanalyzer.MixedContentReport
+	ThirdParties         *pageanalyzer.ThirdPartyReport
+	Technologies         []*pageanalyzer.Technology
+	Images               *pageanalyzer.ImageReport
+	PageWeight           *pageanalyzer.PageWeightReport
+	Compression          *pageanalyzer.CompressionReport
+	Caching              *pageanalyzer.CachingReport
+	CrawledPagesNum      int
+	Duplicates           *pageanalyzer.DuplicateReport
+	RedirectCanonical    *pageanalyzer.RedirectCanonicalReport
+	Hreflang             *pageanalyzer.HreflangReport
 	HasLoginForm         bool
+	Forms                []*pageanalyzer.FormReport
+	SocialPreview        *pageanalyzer.SocialPreview
+	StructuredData       *pageanalyzer.StructuredDataReport
+	Feeds                []*pageanalyzer.FeedReport
+	Content              *pageanalyzer.ContentMetrics
+	Conformance          *pageanalyzer.ConformanceReport
+	Accessibility        *pageanalyzer.AccessibilityReport
+	SecurityHeaders      *pageanalyzer.SecurityHeaderReport
+	TLS                  *pageanalyzer.TLSReport
+	ExternalTLS          []*pageanalyzer.TLSReport
+	MainContent          *pageanalyzer.MainContent
+	MainContentOnly      bool
+	SkippedChecks        []string
 }
 
 func (h *AnalyzerHandler) analyzeURL(w http.ResponseWriter, r *http.Request) {
@@ -74,10 +103,32 @@ func (h *AnalyzerHandler) analyzeURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts := pageanalyzer.Options{
+		Crawl:              r.FormValue("crawl") != "",
+		InspectExternalTLS: r.FormValue("externalTLS") != "",
+		MainContentOnly:    r.FormValue("mainContent") != "",
+		Scope: pageanalyzer.Scope{
+			Mode:    r.FormValue("scope"),
+			Entries: strings.Fields(strings.ReplaceAll(r.FormValue("scopeEntries"), ",", " ")),
+		},
+	}
+
+	// An empty mode falls back to the configured default
+	if opts.Scope.Mode != "" {
+		if err := opts.Scope.Validate(); err != nil {
+			handleHTTPError(w, r,
+				fmt.Sprintf("Invalid link scope: %v", err),
+				http.StatusBadRequest,
+				nil,
+			)
+			return
+		}
+	}
+
 	downloadCtx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	pageContent, err := h.pageDownloader.Download(downloadCtx, urlStr)
+	page, err := h.pageDownloader.Download(downloadCtx, urlStr)
 	if err != nil {
 		if errors.Is(err, pagedownloader.ErrNotfound) {
 			handleHTTPError(w, r,
@@ -96,10 +147,21 @@ func (h *AnalyzerHandler) analyzeURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	analyzerCtx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	// Crawling downloads many pages, so give it more time
+	analyzerTimeout := 10 * time.Second
+	if opts.Crawl {
+		analyzerTimeout = time.Minute
+	}
+
+	analyzerCtx, cancel := context.WithTimeout(r.Context(), analyzerTimeout)
 	defer cancel()
 
-	pageAnalyzedResult, err := h.pageAnalyzer.Analyze(analyzerCtx, url, pageContent)
+	pageAnalyzedResult, err := h.pageAnalyzer.Analyze(analyzerCtx, url, page.Content, &pageanalyzer.Response{
+		FinalURL:         page.URL,
+		TransferredBytes: page.TransferredBytes,
+		Header:           page.Header,
+		TLS:              page.TLS,
+	}, opts)
 	if err != nil {
 		handleHTTPError(w, r,
 			"An error occurred while analyzing the page. Please try again later.",
@@ -120,9 +182,11 @@ func (h *AnalyzerHandler) analyzeURL(w http.ResponseWriter, r *http.Request) {
 		HTMLVersion:  pageAnalyzedResult.HTMLVersion,
 		Title:        pageAnalyzedResult.Title,
 		HasLoginForm: pageAnalyzedResult.HasLoginForm,
+		Forms:        pageAnalyzedResult.Forms,
 
 		HeadingTagToTexts:    pageAnalyzedResult.HeadingTagToTexts,
 		HeadingTagToTextsNum: headingTagToTextNum,
+		Outline:              pageAnalyzedResult.Outline,
 		InternalLinks:        pageAnalyzedResult.InternalLinks,
 		InternalLinksNum:     len(pageAnalyzedResult.InternalLinks),
 
@@ -130,6 +194,34 @@ func (h *AnalyzerHandler) analyzeURL(w http.ResponseWriter, r *http.Request) {
 		ExternalLinksNum: len(pageAnalyzedResult.ExternalLinks),
 
 		InaccessibleLinksNum: pageAnalyzedResult.InaccessibleLinksNum,
+		LinkKindCounts:       pageAnalyzedResult.LinkKindCounts,
+		LinkAttributes:       pageAnalyzedResult.LinkAttributes,
+		BrokenFragmentLinks:  pageAnalyzedResult.BrokenFragmentLinks,
+		Resources:            pageAnalyzedResult.Resources,
+		MixedContent:         pageAnalyzedResult.MixedContent,
+		ThirdParties:         pageAnalyzedResult.ThirdParties,
+		Technologies:         pageAnalyzedResult.Technologies,
+		Images:               pageAnalyzedResult.Images,
+		PageWeight:           pageAnalyzedResult.PageWeight,
+		Compression:          pageAnalyzedResult.Compression,
+		Caching:              pageAnalyzedResult.Caching,
+		CrawledPagesNum:      pageAnalyzedResult.CrawledPagesNum,
+		Duplicates:           pageAnalyzedResult.Duplicates,
+		RedirectCanonical:    pageAnalyzedResult.RedirectCanonical,
+		Hreflang:             pageAnalyzedResult.Hreflang,
+
+		SocialPreview:   pageAnalyzedResult.SocialPreview,
+		StructuredData:  pageAnalyzedResult.StructuredData,
+		Feeds:           pageAnalyzedResult.Feeds,
+		Content:         pageAnalyzedResult.Content,
+		Conformance:     pageAnalyzedResult.Conformance,
+		Accessibility:   pageAnalyzedResult.Accessibility,
+		SecurityHeaders: pageAnalyzedResult.SecurityHeaders,
+		TLS:             pageAnalyzedResult.TLS,
+		ExternalTLS:     pageAnalyzedResult.ExternalTLS,
+		MainContent:     pageAnalyzedResult.MainContent,
+		MainContentOnly: opts.MainContentOnly,
+		SkippedChecks:   pageAnalyzedResult.SkippedChecks,
 	}
 
 	// Execute template